@@ -0,0 +1,262 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"blog/services"
+)
+
+// usage 子命令的用法说明
+const usage = `用法:
+  blog                                  启动博客服务器
+  blog import markdown [选项] <目录>    从 Markdown 文件导入文章，-h 查看选项
+  blog import archive [选项] <归档>     从 blog export 导出的归档恢复全部数据，-h 查看选项
+  blog export [选项]                    导出全部数据为 zip 或 tar.gz 归档，-h 查看选项
+  blog repair reactions                 按回应记录修复文章上的表情回应数量`
+
+// runCommand 执行子命令，返回进程退出码；存储等配置与服务器相同，均读取环境变量
+func runCommand(args []string) int {
+	switch {
+	case len(args) >= 2 && args[0] == "import" && args[1] == "markdown":
+		return runImportMarkdown(args[2:])
+	case len(args) >= 2 && args[0] == "import" && args[1] == "archive":
+		return runImportArchive(args[2:])
+	case args[0] == "export":
+		return runExport(args[1:])
+	case len(args) == 2 && args[0] == "repair" && args[1] == "reactions":
+		return runRepairReactions()
+	case args[0] == "help" || args[0] == "-h" || args[0] == "--help":
+		fmt.Println(usage)
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
+
+// importActionLabels 导入报告中各操作的显示名称
+var importActionLabels = map[services.ImportAction]string{
+	services.ImportCreated: "创建",
+	services.ImportUpdated: "更新",
+	services.ImportSkipped: "跳过",
+	services.ImportFailed:  "失败",
+}
+
+// runImportMarkdown 导入目录下的 Markdown 文件并输出每个文件的结果与汇总，有文件失败时返回 1
+func runImportMarkdown(args []string) int {
+	flags := flag.NewFlagSet("import markdown", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "只显示将要执行的操作，不写入数据")
+	author := flags.String("author", "admin", "front matter 未指定 author 时使用的作者")
+	timezone := flags.String("timezone", "", "front matter 中不带时区的时间所在的时区（IANA 名称，如 Asia/Shanghai），默认为系统时区")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "用法: blog import markdown [选项] <目录>")
+		flags.PrintDefaults()
+	}
+
+	// 允许选项写在目录之后
+	var dirs []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		dirs = append(dirs, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(dirs) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	location := time.Local
+	if *timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(*timezone); err != nil {
+			fmt.Fprintf(os.Stderr, "无效的时区 %q: %v\n", *timezone, err)
+			return 2
+		}
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+	blogService := newBlogService(st)
+	importService := services.NewImportService(blogService, services.NewCategoryService(st.categories, blogService))
+
+	results, err := importService.ImportMarkdown(dirs[0], services.ImportOptions{
+		DryRun:   *dryRun,
+		Author:   *author,
+		Location: location,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "导入失败:", err)
+		return 1
+	}
+
+	failed := printImportResults(results)
+	if *dryRun {
+		fmt.Println("试运行，未写入任何数据")
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printImportResults 输出每个文件的导入结果与汇总，返回失败的文件数
+func printImportResults(results []*services.ImportResult) int {
+	counts := make(map[services.ImportAction]int)
+	for _, result := range results {
+		counts[result.Action]++
+		line := fmt.Sprintf("%s  %s", importActionLabels[result.Action], result.Path)
+		if result.Slug != "" {
+			line += " -> " + result.Slug
+		}
+		if result.Reason != "" {
+			line += "（" + result.Reason + "）"
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("共 %d 个文件：创建 %d，更新 %d，跳过 %d，失败 %d\n", len(results),
+		counts[services.ImportCreated], counts[services.ImportUpdated], counts[services.ImportSkipped], counts[services.ImportFailed])
+	return counts[services.ImportFailed]
+}
+
+// runImportArchive 从导出的归档恢复全部数据并输出文章与各集合的结果，有记录失败时返回 1
+func runImportArchive(args []string) int {
+	flags := flag.NewFlagSet("import archive", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "只显示将要执行的操作，不写入数据")
+	editor := flags.String("author", "admin", "更新已有文章时记录的编辑者")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "用法: blog import archive [选项] <归档文件>")
+		flags.PrintDefaults()
+	}
+
+	// 允许选项写在归档文件之后
+	var files []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(files) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+	blogService := newBlogService(st)
+	importService := services.NewImportService(blogService, services.NewCategoryService(st.categories, blogService))
+	restoreService := services.NewRestoreService(importService, st.series, st.users, st.mediaMeta, openMediaStore())
+
+	result, err := restoreService.RestoreArchive(files[0], services.ImportOptions{DryRun: *dryRun, Author: *editor})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "恢复失败:", err)
+		return 1
+	}
+
+	failed := printImportResults(result.Posts)
+	for _, c := range result.Collections {
+		for _, reason := range c.Failed {
+			fmt.Printf("失败  %s: %s\n", c.Name, reason)
+		}
+		fmt.Printf("%s：恢复 %d，跳过 %d，失败 %d\n", c.Name, c.Restored, c.Skipped, len(c.Failed))
+		failed += len(c.Failed)
+		if c.Name == "users.json" && c.Restored > 0 {
+			fmt.Printf("恢复的 %d 个用户没有密码（导出时不含密码哈希），在数据库中设置密码哈希之前无法登录\n", c.Restored)
+		}
+	}
+	if *dryRun {
+		fmt.Println("试运行，未写入任何数据")
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runExport 导出全部数据，归档可由 import archive 恢复
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatName := flags.String("format", string(services.ExportZip), "归档格式：zip 或 tar.gz")
+	output := flags.String("o", "", "输出文件路径，- 表示标准输出，默认为当前目录下的 blog-export-<时间>.<格式>")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "用法: blog export [选项]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+	format, err := services.ParseExportFormat(*formatName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	st, closeStores := openStores()
+	defer closeStores()
+	exportService := services.NewExportService(newBlogService(st), st.series, st.users, st.mediaMeta, openMediaStore())
+
+	export, err := exportService.Export()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "导出失败:", err)
+		return 1
+	}
+	if *output == "-" {
+		if err := export.WriteArchive(os.Stdout, format); err != nil {
+			fmt.Fprintln(os.Stderr, "导出失败:", err)
+			return 1
+		}
+		return 0
+	}
+
+	path := *output
+	if path == "" {
+		path = format.FileName(export.CreatedAt)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "导出失败:", err)
+		return 1
+	}
+	err = export.WriteArchive(f, format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		fmt.Fprintln(os.Stderr, "导出失败:", err)
+		return 1
+	}
+	// 提示信息输出到标准错误，避免与重定向的内容混在一起
+	fmt.Fprintf(os.Stderr, "已导出 %d 篇文章、%d 个媒体文件到 %s\n", export.Posts, export.Media, path)
+	return 0
+}
+
+// runRepairReactions 按回应记录重新统计并修复文章上的表情回应数量
+func runRepairReactions() int {
+	st, closeStores := openStores()
+	defer closeStores()
+	reactionService := services.NewReactionService(st.reactions, newBlogService(st), nil)
+
+	repaired, err := reactionService.RepairCounts()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "修复失败:", err)
+		return 1
+	}
+	fmt.Printf("已修复 %d 篇文章的表情回应数量\n", repaired)
+	return 0
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ArchiveHandler 处理文章归档的HTTP请求
+type ArchiveHandler struct {
+	archiveService *services.ArchiveService
+}
+
+// NewArchiveHandler 创建新的ArchiveHandler实例
+func NewArchiveHandler(archiveService *services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// GetArchive 获取前端可见文章按年月分组的归档
+func (h *ArchiveHandler) GetArchive(w http.ResponseWriter, r *http.Request) {
+	years, err := h.archiveService.VisibleArchive()
+	if err != nil {
+		http.Error(w, "获取归档失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ArchiveResponse{Data: years})
+}
+
+// GetArchiveYear 获取某一年的归档
+func (h *ArchiveHandler) GetArchiveYear(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	year, _ := strconv.Atoi(vars["year"])
+
+	archive, err := h.archiveService.VisibleArchiveYear(year)
+	if err != nil {
+		writeBlogError(w, err, "获取归档失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ArchiveYearResponse{Data: archive})
+}
+
+// GetArchiveMonth 获取某年某月的归档
+func (h *ArchiveHandler) GetArchiveMonth(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	year, _ := strconv.Atoi(vars["year"])
+	month, _ := strconv.Atoi(vars["month"])
+
+	archive, err := h.archiveService.VisibleArchiveMonth(year, month)
+	if err != nil {
+		writeBlogError(w, err, "获取归档失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ArchiveMonthResponse{Data: archive})
+}
@@ -2,10 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"blog/middleware"
+	"blog/models"
 	"blog/services"
 
 	"github.com/gorilla/mux"
@@ -13,82 +20,167 @@ import (
 
 // BlogHandler 处理博客文章的HTTP请求
 type BlogHandler struct {
-	blogService *services.BlogService
+	blogService   *services.BlogService
+	viewCounter   *services.ViewCounter
+	seriesService *services.SeriesService
 }
 
 // NewBlogHandler 创建新的BlogHandler实例
-func NewBlogHandler(blogService *services.BlogService) *BlogHandler {
+func NewBlogHandler(blogService *services.BlogService, viewCounter *services.ViewCounter, seriesService *services.SeriesService) *BlogHandler {
 	return &BlogHandler{
-		blogService: blogService,
+		blogService:   blogService,
+		viewCounter:   viewCounter,
+		seriesService: seriesService,
 	}
 }
 
-// GetBlogs 获取所有博客文章
+// GetBlogs 后台分页获取博客文章，包含未展示的草稿
+// 支持 show、author、tag、tag_mode、created_from、created_to、updated_from、updated_to 过滤
 func (h *BlogHandler) GetBlogs(w http.ResponseWriter, r *http.Request) {
-	blogs, err := h.blogService.GetAllBlogs()
+	q := r.URL.Query()
+
+	query := services.BlogQuery{
+		Author: q.Get("author"),
+	}
+	withTotal, err := parseListPagination(r, &query)
 	if err != nil {
-		http.Error(w, "获取文章失败", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := parseTagFilter(r, &query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	// 返回强类型响应，主数据放在 data 下
-	resp := BlogListResponse{
-		Data:       blogs,
-		Pagination: Pagination{Page: 1, Limit: int64(len(blogs)), Total: int64(len(blogs))},
+	if showStr := q.Get("show"); showStr != "" {
+		show, err := strconv.ParseBool(showStr)
+		if err != nil {
+			http.Error(w, "无效的 show 参数", http.StatusBadRequest)
+			return
+		}
+		query.Show = &show
 	}
-	json.NewEncoder(w).Encode(resp)
-}
 
-// GetBlogsPaginated 分页获取博客文章
-func (h *BlogHandler) GetBlogsPaginated(w http.ResponseWriter, r *http.Request) {
-	// 获取查询参数
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
+	if query.CreatedFrom, err = parseTimeParam(q.Get("created_from"), false); err != nil {
+		http.Error(w, "无效的 created_from 参数", http.StatusBadRequest)
+		return
+	}
+	if query.CreatedTo, err = parseTimeParam(q.Get("created_to"), true); err != nil {
+		http.Error(w, "无效的 created_to 参数", http.StatusBadRequest)
+		return
+	}
+	if query.UpdatedFrom, err = parseTimeParam(q.Get("updated_from"), false); err != nil {
+		http.Error(w, "无效的 updated_from 参数", http.StatusBadRequest)
+		return
+	}
+	if query.UpdatedTo, err = parseTimeParam(q.Get("updated_to"), true); err != nil {
+		http.Error(w, "无效的 updated_to 参数", http.StatusBadRequest)
+		return
+	}
 
-	// 默认值
-	page := int64(1)
-	limit := int64(10)
+	page, err := h.blogService.ListBlogs(query, withTotal)
+	if err != nil {
+		http.Error(w, "获取文章失败", http.StatusInternalServerError)
+		return
+	}
 
-	if pageStr != "" {
-		if parsedPage, err := strconv.ParseInt(pageStr, 10, 64); err == nil && parsedPage > 0 {
-			page = parsedPage
-		}
+	writeBlogPage(w, r, query, page)
+}
+
+// GetBlogsPaginated 分页获取博客文章（仅前端可见的文章）
+// 支持 page/limit 页码分页，以及 cursor/limit 游标分页；可用 tag（可重复）与 tag_mode=any|all 按标签过滤
+func (h *BlogHandler) GetBlogsPaginated(w http.ResponseWriter, r *http.Request) {
+	var query services.BlogQuery
+	withTotal, err := parseListPagination(r, &query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	if limitStr != "" {
-		if parsedLimit, err := strconv.ParseInt(limitStr, 10, 64); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
-			limit = parsedLimit
-		}
+	if err := parseTagFilter(r, &query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	blogs, total, err := h.blogService.GetBlogsWithPagination(page, limit)
+	page, err := h.blogService.ListVisibleBlogs(query, withTotal)
 	if err != nil {
 		http.Error(w, "获取文章失败", http.StatusInternalServerError)
 		return
 	}
 
-	response := BlogListResponse{
-		Data:       blogs,
-		Pagination: Pagination{Page: page, Limit: limit, Total: total},
+	writeBlogPage(w, r, query, page)
+}
+
+// GetBlog 获取单篇博客文章（未展示的文章返回 404），并记录一次浏览
+// ?render=html 时附带渲染后的正文 content_html
+func (h *BlogHandler) GetBlog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	blog, err := h.blogService.GetVisibleBlogByID(id)
+	if err != nil {
+		writeBlogError(w, err, "获取文章失败")
+		return
 	}
 
+	// 浏览次数先进入缓冲区，响应中加上尚未写入的部分
+	h.viewCounter.Record(blog.ID, clientKey(r), r.UserAgent())
+	blog.Views += h.viewCounter.Pending(blog.ID)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.Header().Set("ETag", blogETag(blog))
+	json.NewEncoder(w).Encode(BlogResponse{
+		Data:        blog,
+		ContentHTML: h.contentHTML(r, blog),
+		Series:      h.seriesNavigation(blog, true),
+	})
 }
 
-// GetBlog 获取单篇博客文章
-func (h *BlogHandler) GetBlog(w http.ResponseWriter, r *http.Request) {
+// GetBlogBySlug 根据 slug 获取单篇博客文章（未展示的文章返回 404），并记录一次浏览
+// 通过历史 slug 访问时仍返回文章，moved 为 true，canonical_slug 为当前 slug，
+// 前端据此向读者发出 301 跳转
+func (h *BlogHandler) GetBlogBySlug(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	blog, moved, err := h.blogService.GetVisibleBlogBySlug(slug)
+	if err != nil {
+		writeBlogError(w, err, "获取文章失败")
+		return
+	}
+
+	h.viewCounter.Record(blog.ID, clientKey(r), r.UserAgent())
+	blog.Views += h.viewCounter.Pending(blog.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", blogETag(blog))
+	w.Header().Set("Link", fmt.Sprintf(`</api/blog/by-slug/%s>; rel="canonical"`, url.PathEscape(blog.Slug)))
+	json.NewEncoder(w).Encode(BlogResponse{
+		Data:          blog,
+		ContentHTML:   h.contentHTML(r, blog),
+		CanonicalSlug: blog.Slug,
+		Moved:         moved,
+		Series:        h.seriesNavigation(blog, true),
+	})
+}
+
+// GetAdminBlog 后台获取单篇博客文章，包含未展示的草稿，支持 ?render=html 预览
+func (h *BlogHandler) GetAdminBlog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	blog, err := h.blogService.GetBlogByID(id)
 	if err != nil {
-		http.Error(w, "文章未找到", http.StatusNotFound)
+		writeBlogError(w, err, "获取文章失败")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BlogResponse{Data: blog})
+	w.Header().Set("ETag", blogETag(blog))
+	json.NewEncoder(w).Encode(BlogResponse{
+		Data:        blog,
+		ContentHTML: h.contentHTML(r, blog),
+		Series:      h.seriesNavigation(blog, false),
+	})
 }
 
 // CreateBlog 创建新博客文章
@@ -98,6 +190,12 @@ func (h *BlogHandler) CreateBlog(w http.ResponseWriter, r *http.Request) {
 		Content string   `json:"content"`
 		Tags    []string `json:"tags,omitempty"`
 		Show    *bool    `json:"show,omitempty"`
+		Slug    string   `json:"slug,omitempty"`
+
+		CategoryID       string     `json:"category_id,omitempty"`
+		CommentsDisabled bool       `json:"comments_disabled,omitempty"`
+		PublishAt        *time.Time `json:"publish_at,omitempty"`
+		UnpublishAt      *time.Time `json:"unpublish_at,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "无效的请求数据", http.StatusBadRequest)
@@ -116,18 +214,32 @@ func (h *BlogHandler) CreateBlog(w http.ResponseWriter, r *http.Request) {
 		showVal = *req.Show
 	}
 
-	blog, err := h.blogService.CreateBlog(req.Title, req.Content, author, req.Tags, showVal)
+	blog, err := h.blogService.CreateBlog(services.CreateBlogInput{
+		Title:   req.Title,
+		Content: req.Content,
+		Author:  author,
+		Tags:    req.Tags,
+		Show:    showVal,
+		Slug:    req.Slug,
+
+		CategoryID:       req.CategoryID,
+		CommentsDisabled: req.CommentsDisabled,
+		PublishAt:        req.PublishAt,
+		UnpublishAt:      req.UnpublishAt,
+	})
 	if err != nil {
-		http.Error(w, "创建文章失败", http.StatusInternalServerError)
+		writeBlogError(w, err, "创建文章失败")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", blogETag(blog))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(BlogResponse{Data: blog})
 }
 
-// UpdateBlog 更新博客文章
+// UpdateBlog 更新博客文章，publish_at、unpublish_at 传空字符串表示清除定时，category_id 传空字符串表示设为未分类
+// 请求带 If-Match 时，仅当文章当前版本与之相符才会更新，否则返回 412 与最新版本
 func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -139,11 +251,27 @@ func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
 		Tags    []string `json:"tags,omitempty"`
 		Show    *bool    `json:"show,omitempty"`
 		Views   *int64   `json:"views,omitempty"`
+		Slug    *string  `json:"slug,omitempty"`
+
+		CategoryID       *string `json:"category_id,omitempty"`
+		CommentsDisabled *bool   `json:"comments_disabled,omitempty"`
+		PublishAt        *string `json:"publish_at,omitempty"`
+		UnpublishAt      *string `json:"unpublish_at,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "无效的请求数据", http.StatusBadRequest)
 		return
 	}
+	publishAt, err := parseScheduleTime(req.PublishAt)
+	if err != nil {
+		http.Error(w, "publish_at 须为 RFC3339 格式的时间", http.StatusBadRequest)
+		return
+	}
+	unpublishAt, err := parseScheduleTime(req.UnpublishAt)
+	if err != nil {
+		http.Error(w, "unpublish_at 须为 RFC3339 格式的时间", http.StatusBadRequest)
+		return
+	}
 
 	// 从认证上下文中获取用户信息（用于权限校验）
 	username := middleware.GetUsername(r)
@@ -155,17 +283,33 @@ func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
 	// 检查是否是文章作者（这里简化了，实际应该从数据库检查）
 	// TODO: 添加权限检查
 
-	blog, err := h.blogService.UpdateBlog(id, req.Title, req.Content, req.Author, req.Tags, req.Show, req.Views)
+	blog, err := h.blogService.UpdateBlog(id, services.UpdateBlogInput{
+		Title:   req.Title,
+		Content: req.Content,
+		Author:  req.Author,
+		Tags:    req.Tags,
+		Show:    req.Show,
+		Views:   req.Views,
+		Slug:    req.Slug,
+		Editor:  username,
+		IfMatch: parseIfMatch(r),
+
+		CategoryID:       req.CategoryID,
+		CommentsDisabled: req.CommentsDisabled,
+		PublishAt:        publishAt,
+		UnpublishAt:      unpublishAt,
+	})
 	if err != nil {
-		http.Error(w, "文章未找到", http.StatusNotFound)
+		writeBlogError(w, err, "更新文章失败")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", blogETag(blog))
 	json.NewEncoder(w).Encode(BlogResponse{Data: blog})
 }
 
-// DeleteBlog 删除博客文章
+// DeleteBlog 删除博客文章（移入回收站），支持 If-Match 条件删除
 func (h *BlogHandler) DeleteBlog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -180,10 +324,264 @@ func (h *BlogHandler) DeleteBlog(w http.ResponseWriter, r *http.Request) {
 	// 检查是否是文章作者（这里简化了，实际应该从数据库检查）
 	// TODO: 添加权限检查
 
-	if err := h.blogService.DeleteBlog(id); err != nil {
-		http.Error(w, "文章未找到", http.StatusNotFound)
+	if err := h.blogService.DeleteBlog(id, parseIfMatch(r)); err != nil {
+		writeBlogError(w, err, "删除文章失败")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// contentHTML 在请求 ?render=html 时返回渲染后的正文，否则返回空字符串
+func (h *BlogHandler) contentHTML(r *http.Request, blog *models.Blog) string {
+	if r.URL.Query().Get("render") != "html" {
+		return ""
+	}
+	return h.blogService.RenderContentHTML(blog)
+}
+
+// seriesNavigation 返回文章所属系列的导航信息，查询失败时省略而不影响文章本身
+func (h *BlogHandler) seriesNavigation(blog *models.Blog, visibleOnly bool) []SeriesNav {
+	navs, err := h.seriesService.Navigation(blog, visibleOnly)
+	if err != nil {
+		log.Printf("获取文章 %s 的系列导航失败: %v", blog.ID.Hex(), err)
+		return nil
+	}
+	return seriesNavs(navs)
+}
+
+// writeBlogError 将业务错误映射为 HTTP 状态码，未知错误使用 fallback 作为提示
+func writeBlogError(w http.ResponseWriter, err error, fallback string) {
+	var conflict *services.VersionConflictError
+	switch {
+	case errors.As(err, &conflict):
+		// 返回最新版本，客户端可据此合并修改后带上新的 If-Match 重试
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", blogETag(conflict.Current))
+		w.WriteHeader(http.StatusPreconditionFailed)
+		json.NewEncoder(w).Encode(VersionConflictResponse{
+			Error:          err.Error(),
+			CurrentVersion: conflict.Current.Version,
+			Data:           conflict.Current,
+		})
+	case errors.Is(err, services.ErrBlogNotFound):
+		http.Error(w, "文章未找到", http.StatusNotFound)
+	case errors.Is(err, services.ErrSlugTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidSlug), errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidArchiveDate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrRevisionNotFound):
+		http.Error(w, "版本未找到", http.StatusNotFound)
+	case errors.Is(err, services.ErrCategoryNotFound):
+		http.Error(w, "分类未找到", http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidCategory), errors.Is(err, services.ErrCategoryCycle),
+		errors.Is(err, services.ErrInvalidOrphanPolicy):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrCategoryNotEmpty):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrSeriesNotFound):
+		http.Error(w, "系列未找到", http.StatusNotFound)
+	case errors.Is(err, services.ErrPostInSeries):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidSeriesPosts):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrUnknownReaction):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrCommentNotFound):
+		http.Error(w, "评论未找到", http.StatusNotFound)
+	case errors.Is(err, services.ErrCommentsDisabled):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrInvalidComment), errors.Is(err, services.ErrInvalidCommenter),
+		errors.Is(err, services.ErrInvalidReply), errors.Is(err, services.ErrInvalidCommentStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrMediaNotFound):
+		http.Error(w, "媒体文件未找到", http.StatusNotFound)
+	case errors.Is(err, services.ErrMediaTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, services.ErrInvalidMediaVariant):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrUnsupportedMedia):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, services.ErrMediaInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidExportFormat):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// parseScheduleTime 解析定时字段：nil 表示不修改，空字符串表示清除（返回指向零值的指针）
+func parseScheduleTime(value *string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if *value == "" {
+		return &time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// blogETag 以文章版本号作为强 ETag
+func blogETag(blog *models.Blog) string {
+	return fmt.Sprintf(`"%d"`, blog.Version)
+}
+
+// parseIfMatch 解析 If-Match 请求头中的版本号，未提供或为 * 时返回 nil（不做校验）
+// If-Match 使用强比较，弱 ETag 与无法解析的值被忽略，因此全部无效时返回空切片（任何版本都不匹配）
+func parseIfMatch(r *http.Request) []int64 {
+	header := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ","))
+	if header == "" || header == "*" {
+		return nil
+	}
+	versions := []int64{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// parsePagination 解析 page、limit 查询参数，非法值回退为默认值
+func parsePagination(r *http.Request) (int64, int64) {
+	pageStr := r.URL.Query().Get("page")
+	limitStr := r.URL.Query().Get("limit")
+
+	// 默认值
+	page := int64(1)
+	limit := int64(10)
+
+	if pageStr != "" {
+		if parsedPage, err := strconv.ParseInt(pageStr, 10, 64); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+	if limitStr != "" {
+		if parsedLimit, err := strconv.ParseInt(limitStr, 10, 64); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+	return page, limit
+}
+
+// parseListPagination 解析列表分页参数并写入 query，返回是否需要统计总数
+// 传入 cursor 时使用游标分页，默认不统计总数；否则使用页码分页，默认统计总数
+// 两种方式均可通过 with_total 显式指定
+func parseListPagination(r *http.Request, query *services.BlogQuery) (bool, error) {
+	page, limit := parsePagination(r)
+	query.Limit = limit
+
+	withTotal := true
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		cursor, err := services.ParseBlogCursor(cursorStr)
+		if err != nil {
+			return false, err
+		}
+		query.Cursor = cursor
+		withTotal = false
+	} else {
+		query.Page = page
+	}
+
+	if v, err := strconv.ParseBool(r.URL.Query().Get("with_total")); err == nil {
+		withTotal = v
+	}
+	return withTotal, nil
+}
+
+// parseTagFilter 解析 tag（可重复）与 tag_mode 参数并写入 query
+// tag_mode 为 any（默认，包含任一标签）或 all（包含全部标签）
+func parseTagFilter(r *http.Request, query *services.BlogQuery) error {
+	q := r.URL.Query()
+	query.Tags = services.NormalizeTags(q["tag"])
+
+	switch q.Get("tag_mode") {
+	case "", "any":
+		query.TagMatchAll = false
+	case "all":
+		query.TagMatchAll = true
+	default:
+		return errors.New("无效的 tag_mode 参数，可选值：any、all")
+	}
+	return nil
+}
+
+// writeBlogPage 输出文章列表响应（按 fields 参数选择字段），并通过 RFC 8288 Link 头提供翻页链接
+func writeBlogPage(w http.ResponseWriter, r *http.Request, query services.BlogQuery, page *services.BlogPage) {
+	fields, err := parseFields(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := selectBlogFields(page.Blogs, fields)
+	if err != nil {
+		http.Error(w, "获取文章失败", http.StatusInternalServerError)
+		return
+	}
+
+	pagination := Pagination{
+		Limit:      query.Limit,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	if query.Cursor == nil {
+		pagination.Page = query.Page
+	}
+
+	var links []string
+	if page.NextCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(r, page.NextCursor)))
+	}
+	if page.PrevCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorURL(r, page.PrevCursor)))
+		links = append(links, fmt.Sprintf(`<%s>; rel="first"`, cursorURL(r, "")))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BlogListResponse{Data: data, Pagination: pagination})
+}
+
+// cursorURL 基于当前请求生成指向指定游标的相对 URL，cursor 为空时指向第一页
+func cursorURL(r *http.Request, cursor string) string {
+	q := r.URL.Query()
+	q.Del("page")
+	q.Del("cursor")
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// parseTimeParam 解析 RFC3339 或 YYYY-MM-DD 格式的时间参数，空字符串返回零值
+// endOfDay 为 true 时，仅包含日期的值取当天最后一刻，便于作为闭区间上界
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
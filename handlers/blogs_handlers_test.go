@@ -0,0 +1,395 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/middleware"
+	"blog/models"
+	"blog/services"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestBlogRouter 基于内存存储组装文章相关的路由，返回路由与后台用户的认证令牌
+func newTestBlogRouter(t *testing.T) (*mux.Router, string) {
+	t.Helper()
+	return newTestBlogRouterWithStore(t, services.NewMemoryBlogStore())
+}
+
+// newTestBlogRouterWithStore 与 newTestBlogRouter 相同，但使用指定的文章存储
+func newTestBlogRouterWithStore(t *testing.T, blogStore services.BlogStore) (*mux.Router, string) {
+	t.Helper()
+	seriesStore := services.NewMemorySeriesStore()
+	blogService := services.NewBlogService(blogStore, services.NewMemoryRevisionStore(), services.NewMemoryCategoryStore(),
+		services.NewMemoryCommentStore(), services.NewMemoryReactionStore(), seriesStore, 50)
+	seriesService := services.NewSeriesService(seriesStore, blogService)
+	viewCounter := services.NewViewCounter(blogStore, time.Minute, time.Minute)
+	blogHandler := NewBlogHandler(blogService, viewCounter, seriesService)
+
+	authService := services.NewAuthService(services.NewMemoryUserStore(), "test-secret")
+	if _, err := authService.Register("alice", "password", "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	auth, err := authService.Login("alice", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtMiddleware := middleware.NewJWTMiddleware(authService)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/blog/{id}", blogHandler.GetBlog).Methods("GET")
+	r.HandleFunc("/api/blog/by-slug/{slug}", blogHandler.GetBlogBySlug).Methods("GET")
+	r.HandleFunc("/api/blogs", blogHandler.GetBlogsPaginated).Methods("GET")
+	r.HandleFunc("/api/admin/blogs", jwtMiddleware.Authenticate(blogHandler.GetBlogs)).Methods("GET")
+	r.HandleFunc("/api/admin/blog/{id}", jwtMiddleware.Authenticate(blogHandler.GetAdminBlog)).Methods("GET")
+	r.HandleFunc("/api/admin/blog", jwtMiddleware.Authenticate(blogHandler.CreateBlog)).Methods("POST")
+	r.HandleFunc("/api/admin/blog/{id}", jwtMiddleware.Authenticate(blogHandler.UpdateBlog)).Methods("PUT")
+	r.HandleFunc("/api/admin/blog/{id}", jwtMiddleware.Authenticate(blogHandler.DeleteBlog)).Methods("DELETE")
+	return r, auth.Token
+}
+
+// blogListBody 列表接口响应中测试关心的部分
+type blogListBody struct {
+	Data []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+func TestBlogHandlerRoundTrip(t *testing.T) {
+	router, token := newTestBlogRouter(t)
+
+	// 每一步的路径与请求体中的 {id}、{draft} 替换为之前创建的文章ID
+	ids := map[string]string{}
+	expand := func(s string) string {
+		for name, id := range ids {
+			s = strings.ReplaceAll(s, "{"+name+"}", id)
+		}
+		return s
+	}
+
+	steps := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		auth    bool
+		ifMatch string
+		status  int
+		check   func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name: "未认证不能创建", method: "POST", path: "/api/admin/blog",
+			body: `{"title":"Hello"}`, status: http.StatusUnauthorized,
+		},
+		{
+			name: "无效的请求数据", method: "POST", path: "/api/admin/blog",
+			body: `{`, auth: true, status: http.StatusBadRequest,
+		},
+		{
+			name: "创建文章", method: "POST", path: "/api/admin/blog", auth: true,
+			body:   `{"title":"Hello World","content":"# 标题\n\n正文","tags":["Go"," go ","Web"]}`,
+			status: http.StatusCreated,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp BlogResponse
+				decodeBody(t, rec, &resp)
+				blog := resp.Data
+				if blog.Title != "Hello World" || blog.Author != "alice" || !blog.Show || blog.Slug != "hello-world" {
+					t.Errorf("创建的文章 = %+v", blog)
+				}
+				if strings.Join(blog.Tags, ",") != "Go,Web" {
+					t.Errorf("tags = %v, want [Go Web]", blog.Tags)
+				}
+				if etag := rec.Header().Get("ETag"); etag != `"1"` {
+					t.Errorf("ETag = %s, want \"1\"", etag)
+				}
+				ids["id"] = blog.ID.Hex()
+			},
+		},
+		{
+			name: "创建草稿", method: "POST", path: "/api/admin/blog", auth: true,
+			body: `{"title":"Draft","content":"草稿","show":false}`, status: http.StatusCreated,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp BlogResponse
+				decodeBody(t, rec, &resp)
+				ids["draft"] = resp.Data.ID.Hex()
+			},
+		},
+		{
+			name: "前端获取文章", method: "GET", path: "/api/blog/{id}", status: http.StatusOK,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp BlogResponse
+				decodeBody(t, rec, &resp)
+				if resp.Data.ID.Hex() != ids["id"] || resp.Data.Content != "# 标题\n\n正文" {
+					t.Errorf("文章 = %+v", resp.Data)
+				}
+				// 浏览次数尚在缓冲区中，响应已计入
+				if resp.Data.Views != 1 {
+					t.Errorf("views = %d, want 1", resp.Data.Views)
+				}
+			},
+		},
+		{
+			name: "前端按 slug 获取文章", method: "GET", path: "/api/blog/by-slug/hello-world", status: http.StatusOK,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp BlogResponse
+				decodeBody(t, rec, &resp)
+				if resp.Data.ID.Hex() != ids["id"] || resp.Moved {
+					t.Errorf("文章 = %+v, moved = %v", resp.Data, resp.Moved)
+				}
+			},
+		},
+		{name: "前端看不到草稿", method: "GET", path: "/api/blog/{draft}", status: http.StatusNotFound},
+		{name: "后台可以看到草稿", method: "GET", path: "/api/admin/blog/{draft}", auth: true, status: http.StatusOK},
+		{
+			name: "前端列表不含草稿", method: "GET", path: "/api/blogs?with_total=true", status: http.StatusOK,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp blogListBody
+				decodeBody(t, rec, &resp)
+				if len(resp.Data) != 1 || resp.Data[0].ID != ids["id"] {
+					t.Errorf("列表 = %+v", resp.Data)
+				}
+			},
+		},
+		{
+			name: "后台列表包含草稿", method: "GET", path: "/api/admin/blogs", auth: true, status: http.StatusOK,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp blogListBody
+				decodeBody(t, rec, &resp)
+				if len(resp.Data) != 2 {
+					t.Errorf("列表 = %+v, want 2 篇文章", resp.Data)
+				}
+			},
+		},
+		{
+			name: "更新文章并修改 slug", method: "PUT", path: "/api/admin/blog/{id}", auth: true, ifMatch: `"1"`,
+			body: `{"title":"Hello Again","slug":"hello-again"}`, status: http.StatusOK,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp BlogResponse
+				decodeBody(t, rec, &resp)
+				if resp.Data.Title != "Hello Again" || resp.Data.Version != 2 || resp.Data.Content != "# 标题\n\n正文" {
+					t.Errorf("更新后的文章 = %+v", resp.Data)
+				}
+				if etag := rec.Header().Get("ETag"); etag != `"2"` {
+					t.Errorf("ETag = %s, want \"2\"", etag)
+				}
+			},
+		},
+		{
+			name: "基于旧版本的更新返回最新版本", method: "PUT", path: "/api/admin/blog/{id}", auth: true, ifMatch: `"1"`,
+			body: `{"title":"Stale"}`, status: http.StatusPreconditionFailed,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp VersionConflictResponse
+				decodeBody(t, rec, &resp)
+				if resp.CurrentVersion != 2 || resp.Data.Title != "Hello Again" {
+					t.Errorf("冲突响应 = %+v", resp)
+				}
+			},
+		},
+		{
+			name: "历史 slug 仍可访问", method: "GET", path: "/api/blog/by-slug/hello-world", status: http.StatusOK,
+			check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp BlogResponse
+				decodeBody(t, rec, &resp)
+				if !resp.Moved || resp.CanonicalSlug != "hello-again" {
+					t.Errorf("moved = %v, canonical_slug = %q", resp.Moved, resp.CanonicalSlug)
+				}
+			},
+		},
+		{
+			name: "slug 冲突", method: "PUT", path: "/api/admin/blog/{draft}", auth: true,
+			body: `{"slug":"hello-again"}`, status: http.StatusConflict,
+		},
+		{name: "更新不存在的文章", method: "PUT", path: "/api/admin/blog/000000000000000000000000", auth: true, body: `{"title":"x"}`, status: http.StatusNotFound},
+		{name: "未认证不能删除", method: "DELETE", path: "/api/admin/blog/{id}", status: http.StatusUnauthorized},
+		{name: "删除文章", method: "DELETE", path: "/api/admin/blog/{id}", auth: true, ifMatch: `"2"`, status: http.StatusNoContent},
+		{name: "删除后前端不可见", method: "GET", path: "/api/blog/{id}", status: http.StatusNotFound},
+		{name: "重复删除", method: "DELETE", path: "/api/admin/blog/{id}", auth: true, status: http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		ok := t.Run(step.name, func(t *testing.T) {
+			req := httptest.NewRequest(step.method, expand(step.path), strings.NewReader(expand(step.body)))
+			req.Header.Set("User-Agent", "Mozilla/5.0")
+			if step.auth {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			if step.ifMatch != "" {
+				req.Header.Set("If-Match", step.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != step.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, step.status, rec.Body.String())
+			}
+			if step.check != nil {
+				step.check(t, rec)
+			}
+		})
+		// 后续步骤依赖之前的结果
+		if !ok {
+			t.FailNow()
+		}
+	}
+}
+
+// decodeBody 解析 JSON 响应，失败时终止测试
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+}
+
+func TestBlogHandlerIfMatch(t *testing.T) {
+	router, token := newTestBlogRouter(t)
+	do := func(method, path, body, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("POST", "/api/admin/blog", `{"title":"Hello","content":"正文"}`, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("创建文章 status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created BlogResponse
+	decodeBody(t, rec, &created)
+	path := "/api/admin/blog/" + created.Data.ID.Hex()
+
+	// 每一步之后文章的版本号依次为 etag 中的值
+	steps := []struct {
+		name    string
+		method  string
+		body    string
+		ifMatch string
+		status  int
+		etag    string
+	}{
+		{"获取文章返回 ETag", "GET", "", "", http.StatusOK, `"1"`},
+		{"版本相符时更新", "PUT", `{"title":"v2"}`, `"1"`, http.StatusOK, `"2"`},
+		{"版本过期时返回 412 与最新版本", "PUT", `{"title":"stale"}`, `"1"`, http.StatusPreconditionFailed, `"2"`},
+		{"弱 ETag 不匹配", "PUT", `{"title":"weak"}`, `W/"2"`, http.StatusPreconditionFailed, `"2"`},
+		{"多个 ETag 之一相符", "PUT", `{"title":"v3"}`, `"7", "2"`, http.StatusOK, `"3"`},
+		{"If-Match 为 * 时不校验", "PUT", `{"title":"v4"}`, `*`, http.StatusOK, `"4"`},
+		{"不带 If-Match 时不校验", "PUT", `{"title":"v5"}`, "", http.StatusOK, `"5"`},
+		{"版本过期时不能删除", "DELETE", "", `"4"`, http.StatusPreconditionFailed, `"5"`},
+		{"获取最新版本", "GET", "", "", http.StatusOK, `"5"`},
+		{"版本相符时删除", "DELETE", "", `"5"`, http.StatusNoContent, ""},
+	}
+	for _, step := range steps {
+		ok := t.Run(step.name, func(t *testing.T) {
+			rec := do(step.method, path, step.body, step.ifMatch)
+			if rec.Code != step.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, step.status, rec.Body.String())
+			}
+			if etag := rec.Header().Get("ETag"); etag != step.etag {
+				t.Errorf("ETag = %s, want %s", etag, step.etag)
+			}
+			if step.status == http.StatusPreconditionFailed {
+				var resp VersionConflictResponse
+				decodeBody(t, rec, &resp)
+				if want := `"` + strconv.FormatInt(resp.CurrentVersion, 10) + `"`; want != step.etag || resp.Data.Version != resp.CurrentVersion {
+					t.Errorf("冲突响应 current_version = %d, data.version = %d", resp.CurrentVersion, resp.Data.Version)
+				}
+			}
+		})
+		// 后续步骤依赖之前的结果
+		if !ok {
+			t.FailNow()
+		}
+	}
+}
+
+// failingBlogStore 在 failing 为 true 时读取文章返回错误，模拟存储故障
+type failingBlogStore struct {
+	*services.MemoryBlogStore
+	failing bool
+}
+
+var errStoreDown = errors.New("数据库不可用")
+
+func (s *failingBlogStore) GetBlogByID(ctx context.Context, id primitive.ObjectID) (*models.Blog, error) {
+	if s.failing {
+		return nil, errStoreDown
+	}
+	return s.MemoryBlogStore.GetBlogByID(ctx, id)
+}
+
+func TestGetBlogErrors(t *testing.T) {
+	store := &failingBlogStore{MemoryBlogStore: services.NewMemoryBlogStore()}
+	router, token := newTestBlogRouterWithStore(t, store)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	create := func(body string) string {
+		t.Helper()
+		rec := do("POST", "/api/admin/blog", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("创建文章 status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp BlogResponse
+		decodeBody(t, rec, &resp)
+		return resp.Data.ID.Hex()
+	}
+	visible := create(`{"title":"已发布","content":"正文"}`)
+	draft := create(`{"title":"草稿","content":"正文","show":false}`)
+	trashed := create(`{"title":"已删除","content":"正文"}`)
+	if rec := do("DELETE", "/api/admin/blog/"+trashed, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("删除文章 status = %d", rec.Code)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		failing bool
+		status  int
+	}{
+		{"已发布的文章", "/api/blog/" + visible, false, http.StatusOK},
+		{"无效的ID", "/api/blog/not-an-id", false, http.StatusNotFound},
+		{"不存在的文章", "/api/blog/" + primitive.NewObjectID().Hex(), false, http.StatusNotFound},
+		{"未展示的文章", "/api/blog/" + draft, false, http.StatusNotFound},
+		{"回收站中的文章", "/api/blog/" + trashed, false, http.StatusNotFound},
+		{"存储出错", "/api/blog/" + visible, true, http.StatusInternalServerError},
+		{"后台获取草稿", "/api/admin/blog/" + draft, false, http.StatusOK},
+		{"后台获取回收站中的文章", "/api/admin/blog/" + trashed, false, http.StatusNotFound},
+		{"后台存储出错", "/api/admin/blog/" + draft, true, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.failing = tt.failing
+			defer func() { store.failing = false }()
+			rec := do("GET", tt.path, "")
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusInternalServerError && strings.Contains(rec.Body.String(), errStoreDown.Error()) {
+				t.Errorf("错误详情不应返回给客户端: %s", rec.Body.String())
+			}
+		})
+	}
+}
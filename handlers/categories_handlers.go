@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// CategoryHandler 处理分类的HTTP请求
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+}
+
+// NewCategoryHandler 创建新的CategoryHandler实例
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// GetCategories 获取分类树，文章数只统计前端可见的文章
+func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryService.Tree(true)
+	if err != nil {
+		http.Error(w, "获取分类失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryTreeResponse{Data: tree})
+}
+
+// GetCategoryBlogs 分页获取分类及其全部子孙分类下的文章（仅前端可见的文章）
+func (h *CategoryHandler) GetCategoryBlogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	var query services.BlogQuery
+	withTotal, err := parseListPagination(r, &query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, page, err := h.categoryService.ListVisibleCategoryBlogs(slug, query, withTotal)
+	if err != nil {
+		writeBlogError(w, err, "获取文章失败")
+		return
+	}
+
+	writeBlogPage(w, r, query, page)
+}
+
+// GetAdminCategories 后台获取分类树，文章数包含未展示的草稿（不含回收站）
+func (h *CategoryHandler) GetAdminCategories(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryService.Tree(false)
+	if err != nil {
+		http.Error(w, "获取分类失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryTreeResponse{Data: tree})
+}
+
+// categoryRequest 创建或更新分类的请求体，parent_id 为空字符串表示顶级分类
+type categoryRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Slug     *string `json:"slug,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+// CreateCategory 创建新分类
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		http.Error(w, "分类名称不能为空", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(services.CategoryInput{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		writeBlogError(w, err, "创建分类失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CategoryResponse{Data: category})
+}
+
+// UpdateCategory 重命名或移动分类，子孙分类与其中的文章随之移动
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		http.Error(w, "分类名称不能为空", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(id, services.CategoryInput{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		writeBlogError(w, err, "更新分类失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryResponse{Data: category})
+}
+
+// DeleteCategory 删除分类，子分类移动到其父分类下
+// ?orphans= 指定其下文章的处理方式：parent（默认，改为属于父分类）、uncategorize（设为未分类）、reject（有文章时拒绝删除）
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	policy, err := services.ParseOrphanPolicy(r.URL.Query().Get("orphans"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(id, policy); err != nil {
+		writeBlogError(w, err, "删除分类失败")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+)
+
+// clientIP 获取客户端 IP，经过受信任的反向代理时 r.RemoteAddr 已由 ClientIPMiddleware 还原为客户端地址
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientKey 组合 IP 与 User-Agent 作为匿名客户端的标识
+func clientKey(r *http.Request) string {
+	return clientIP(r) + "|" + r.UserAgent()
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"blog/middleware"
+	"blog/models"
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// CommentHandler 处理评论的HTTP请求
+type CommentHandler struct {
+	commentService *services.CommentService
+}
+
+// NewCommentHandler 创建新的CommentHandler实例
+func NewCommentHandler(commentService *services.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// GetComments 获取文章的评论树（仅前端可见的文章），已删除但有回复的评论以占位节点返回
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	tree, err := h.commentService.VisibleComments(id)
+	if err != nil {
+		writeBlogError(w, err, "获取评论失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CommentTreeResponse{Data: publicCommentTree(tree)})
+}
+
+// PostComment 发表评论或回复，携带认证令牌时以登录用户身份发表并直接通过审核
+func (h *CommentHandler) PostComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		ParentID string `json:"parent_id,omitempty"`
+		Name     string `json:"name,omitempty"`
+		Email    string `json:"email,omitempty"`
+		Website  string `json:"website,omitempty"`
+		Content  string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.commentService.PostComment(id, services.CommentInput{
+		ParentID:  req.ParentID,
+		UserID:    middleware.GetUserID(r),
+		Username:  middleware.GetUsername(r),
+		Name:      req.Name,
+		Email:     req.Email,
+		Website:   req.Website,
+		Content:   req.Content,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		writeBlogError(w, err, "发表评论失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(PublicCommentResponse{Data: publicComment(comment)})
+}
+
+// GetAdminComments 后台分页获取评论，按发表时间倒序
+// ?status= 默认为 pending（审核队列），all 表示全部状态；?post_id= 只返回该文章的评论
+func (h *CommentHandler) GetAdminComments(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var statuses []models.CommentStatus
+	switch value := q.Get("status"); value {
+	case "":
+		statuses = []models.CommentStatus{models.CommentPending}
+	case "all":
+	default:
+		status, err := services.ParseCommentStatus(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		statuses = []models.CommentStatus{status}
+	}
+
+	page, limit := parsePagination(r)
+	comments, total, err := h.commentService.ListComments(statuses, q.Get("post_id"), page, limit)
+	if err != nil {
+		writeBlogError(w, err, "获取评论失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CommentListResponse{
+		Data:       comments,
+		Pagination: Pagination{Page: page, Limit: limit, Total: &total},
+	})
+}
+
+// UpdateComment 审核评论（修改 status）或修改评论内容
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		Status  *string `json:"status,omitempty"`
+		Content *string `json:"content,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+
+	edit := services.CommentEdit{Content: req.Content}
+	if req.Status != nil {
+		status, err := services.ParseCommentStatus(*req.Status)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		edit.Status = &status
+	}
+
+	comment, err := h.commentService.UpdateComment(id, edit)
+	if err != nil {
+		writeBlogError(w, err, "更新评论失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CommentResponse{Data: comment})
+}
+
+// DeleteComment 删除评论（标记为 deleted），其下已通过的回复仍然展示
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.commentService.DeleteComment(id); err != nil {
+		writeBlogError(w, err, "删除评论失败")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PublicComment 前端展示的评论，不包含邮箱、IP 等信息
+// 未通过审核但有回复的评论以占位节点返回：status 为 deleted，不包含作者与内容
+type PublicComment struct {
+	ID          string           `json:"id"`
+	ParentID    string           `json:"parent_id,omitempty"`
+	AuthorName  string           `json:"author_name,omitempty"`
+	AuthorURL   string           `json:"author_url,omitempty"`
+	ContentHTML string           `json:"content_html,omitempty"`
+	Status      string           `json:"status"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Replies     []*PublicComment `json:"replies,omitempty"`
+}
+
+// publicComment 转换刚发表的评论，待审核的评论只返回给发表者本人
+func publicComment(comment *models.Comment) *PublicComment {
+	c := &PublicComment{
+		ID:          comment.ID.Hex(),
+		AuthorName:  comment.AuthorName,
+		AuthorURL:   comment.AuthorURL,
+		ContentHTML: comment.ContentHTML,
+		Status:      string(comment.Status),
+		CreatedAt:   comment.CreatedAt,
+	}
+	if comment.ParentID != nil {
+		c.ParentID = comment.ParentID.Hex()
+	}
+	return c
+}
+
+// publicCommentTree 转换评论树，未通过审核的节点只保留位置
+func publicCommentTree(nodes []*models.CommentNode) []*PublicComment {
+	list := make([]*PublicComment, len(nodes))
+	for i, node := range nodes {
+		c := publicComment(&node.Comment)
+		if node.Status != models.CommentApproved {
+			c.AuthorName, c.AuthorURL, c.ContentHTML = "", "", ""
+			c.Status = string(models.CommentDeleted)
+		}
+		c.Replies = publicCommentTree(node.Replies)
+		list[i] = c
+	}
+	return list
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"blog/services"
+)
+
+// ExportHandler 处理数据导出的HTTP请求
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+// NewExportHandler 创建新的ExportHandler实例
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// Export 下载全部数据的归档文件，?format=zip（默认）或 tar.gz
+// 归档可通过 blog import archive 完整恢复，其中 posts/ 下的 Markdown 文件也可通过 blog import markdown 单独导入
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format, err := services.ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeBlogError(w, err, "导出数据失败")
+		return
+	}
+	export, err := h.exportService.Export()
+	if err != nil {
+		writeBlogError(w, err, "导出数据失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+format.FileName(export.CreatedAt)+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	// 响应已经开始，写出失败时只能记录日志
+	if err := export.WriteArchive(w, format); err != nil {
+		log.Println("写出导出文件失败:", err)
+	}
+}
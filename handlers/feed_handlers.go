@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// FeedHandler 输出 RSS 2.0、Atom 与 JSON Feed 1.1 订阅源
+type FeedHandler struct {
+	feedService *services.FeedService
+}
+
+// NewFeedHandler 创建新的FeedHandler实例
+func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+	}
+}
+
+// GetRSS 输出 RSS 2.0 订阅源，路径中带 tag 或 author 时只包含对应的文章
+func (h *FeedHandler) GetRSS(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "rss", "application/rss+xml; charset=utf-8", encodeRSS)
+}
+
+// GetAtom 输出 Atom 订阅源，路径中带 tag 或 author 时只包含对应的文章
+func (h *FeedHandler) GetAtom(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "atom", "application/atom+xml; charset=utf-8", encodeAtom)
+}
+
+// GetJSONFeed 输出 JSON Feed 1.1 订阅源，路径中带 tag 或 author 时只包含对应的文章
+func (h *FeedHandler) GetJSONFeed(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "json", "application/feed+json; charset=utf-8", encodeJSONFeed)
+}
+
+// feedEncoder 将订阅源编码为具体格式，selfURL 为订阅源自身的地址
+type feedEncoder func(feed *services.Feed, selfURL string) ([]byte, error)
+
+// serveFeed 生成并输出订阅源，支持 If-None-Match 与 If-Modified-Since 条件请求
+func (h *FeedHandler) serveFeed(w http.ResponseWriter, r *http.Request, format, contentType string, encode feedEncoder) {
+	vars := mux.Vars(r)
+	feed, err := h.feedService.VisibleFeed(services.FeedFilter{Tag: vars["tag"], Author: vars["author"]})
+	if err != nil {
+		http.Error(w, "生成订阅源失败", http.StatusInternalServerError)
+		return
+	}
+
+	body, err := encode(feed, h.feedService.Site().AbsURL(r.URL.EscapedPath()))
+	if err != nil {
+		http.Error(w, "生成订阅源失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", fmt.Sprintf(`"%s-%s"`, feed.Digest, format))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	// ServeContent 根据 ETag 与修改时间处理条件请求，未变化时返回 304
+	http.ServeContent(w, r, "", feed.Updated, bytes.NewReader(body))
+}
+
+// rssFeed RSS 2.0 文档，作者使用 dc:creator（RSS 的 author 元素要求邮箱），全文使用 content:encoded
+type rssFeed struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	AtomNS    string   `xml:"xmlns:atom,attr"`
+	ContentNS string   `xml:"xmlns:content,attr"`
+	DCNS      string   `xml:"xmlns:dc,attr"`
+	Channel   struct {
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		SelfLink      atomLink  `xml:"atom:link"`
+		Items         []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     *cdata   `xml:"content:encoded,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+func encodeRSS(feed *services.Feed, selfURL string) ([]byte, error) {
+	doc := rssFeed{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+	}
+	doc.Channel.Title = feed.Title
+	doc.Channel.Link = feed.Link
+	doc.Channel.Description = feed.Description
+	doc.Channel.LastBuildDate = feed.Updated.Format(time.RFC1123Z)
+	doc.Channel.SelfLink = atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"}
+	doc.Channel.Items = make([]rssItem, len(feed.Items))
+	for i, item := range feed.Items {
+		doc.Channel.Items[i] = rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: "false", Value: item.ID},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Tags,
+			Description: item.Summary,
+		}
+		if item.ContentHTML != "" {
+			doc.Channel.Items[i].Content = &cdata{Value: item.ContentHTML}
+		}
+	}
+	return marshalXML(doc)
+}
+
+// atomFeed Atom（RFC 4287）文档
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomContent   `xml:"content,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func encodeAtom(feed *services.Feed, selfURL string) ([]byte, error) {
+	doc := atomFeed{
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		ID:       selfURL,
+		Updated:  feed.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]atomEntry, len(feed.Items)),
+	}
+	for i, item := range feed.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Link:      atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Author:    atomPerson{Name: item.Author},
+			Summary:   item.Summary,
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		if item.ContentHTML != "" {
+			entry.Content = &atomContent{Type: "html", Value: item.ContentHTML}
+		}
+		doc.Entries[i] = entry
+	}
+	return marshalXML(doc)
+}
+
+// marshalXML 编码 XML 文档并加上 XML 声明
+func marshalXML(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// jsonFeed JSON Feed 1.1 文档
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished time.Time        `json:"date_published"`
+	DateModified  time.Time        `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func encodeJSONFeed(feed *services.Feed, selfURL string) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     selfURL,
+		Description: feed.Description,
+		Items:       make([]jsonFeedItem, len(feed.Items)),
+	}
+	for i, item := range feed.Items {
+		entry := jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			Summary:       item.Summary,
+			DatePublished: item.Published,
+			DateModified:  item.Updated,
+			Tags:          item.Tags,
+		}
+		// 只输出摘要时以摘要作为纯文本正文，content_html 与 content_text 至少需要一个
+		if entry.ContentHTML == "" {
+			entry.ContentText = item.Summary
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonFeedAuthor{{Name: item.Author}}
+		}
+		doc.Items[i] = entry
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
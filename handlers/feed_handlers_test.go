@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+func TestFeedConditionalGet(t *testing.T) {
+	blogService := services.NewBlogService(services.NewMemoryBlogStore(), services.NewMemoryRevisionStore(), services.NewMemoryCategoryStore(),
+		services.NewMemoryCommentStore(), services.NewMemoryReactionStore(), services.NewMemorySeriesStore(), 50)
+	site := services.SiteConfig{Title: "博客", URL: "https://example.com", PostPath: "/blog/{slug}"}
+	feedHandler := NewFeedHandler(services.NewFeedService(blogService, site, true, 10))
+	r := mux.NewRouter()
+	r.HandleFunc("/feed.xml", feedHandler.GetRSS).Methods("GET", "HEAD")
+	r.HandleFunc("/atom.xml", feedHandler.GetAtom).Methods("GET", "HEAD")
+	r.HandleFunc("/tags/{tag}/feed.json", feedHandler.GetJSONFeed).Methods("GET", "HEAD")
+
+	blog, err := blogService.CreateBlog(services.CreateBlogInput{Title: "Hello", Content: "正文", Author: "alice", Show: true, Tags: []string{"Go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	etags := map[string]string{}
+	for _, tt := range []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/feed.xml", "application/rss+xml; charset=utf-8", "<title>Hello</title>"},
+		{"/atom.xml", "application/atom+xml; charset=utf-8", "<title>Hello</title>"},
+		{"/tags/go/feed.json", "application/feed+json; charset=utf-8", `"title": "Hello"`},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := get(tt.path, nil)
+			if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != tt.contentType || !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Fatalf("status = %d, Content-Type = %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+			}
+			etag, lastModified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+			if etag == "" || lastModified == "" {
+				t.Fatalf("ETag = %q, Last-Modified = %q", etag, lastModified)
+			}
+			etags[tt.path] = etag
+
+			tests := []struct {
+				name   string
+				header map[string]string
+				status int
+			}{
+				{"ETag 相符", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+				{"多个 ETag 之一相符", map[string]string{"If-None-Match": `"other", ` + etag}, http.StatusNotModified},
+				{"ETag 不符", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+				{"未修改", map[string]string{"If-Modified-Since": lastModified}, http.StatusNotModified},
+				// 同时带两者时以 If-None-Match 为准
+				{"ETag 不符时忽略修改时间", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified}, http.StatusOK},
+			}
+			for _, c := range tests {
+				rec := get(tt.path, c.header)
+				if rec.Code != c.status {
+					t.Errorf("%s: status = %d, want %d", c.name, rec.Code, c.status)
+				}
+				if c.status == http.StatusNotModified && (rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag) {
+					t.Errorf("%s: 304 响应 ETag = %q, body = %q", c.name, rec.Header().Get("ETag"), rec.Body.String())
+				}
+			}
+		})
+	}
+	if etags["/feed.xml"] == etags["/atom.xml"] {
+		t.Errorf("不同格式的 ETag 相同: %s", etags["/feed.xml"])
+	}
+
+	// 文章更新后旧的 ETag 不再相符
+	title := "Hello again"
+	if _, err := blogService.UpdateBlog(blog.ID.Hex(), services.UpdateBlogInput{Title: &title}); err != nil {
+		t.Fatal(err)
+	}
+	rec := get("/feed.xml", map[string]string{"If-None-Match": etags["/feed.xml"]})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etags["/feed.xml"] || !strings.Contains(rec.Body.String(), title) {
+		t.Errorf("更新后 status = %d, ETag = %s", rec.Code, rec.Header().Get("ETag"))
+	}
+}
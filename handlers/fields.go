@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"blog/models"
+)
+
+// listOmittedFields 列表接口默认不返回的字段，可通过 ?fields= 显式请求
+var listOmittedFields = []string{"content"}
+
+// blogFieldNames 文章可供选择的 JSON 字段名
+var blogFieldNames = jsonFieldNames(reflect.TypeOf(models.Blog{}))
+
+// jsonFieldNames 收集结构体（含内嵌结构体）序列化后的字段名
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" {
+			for name := range jsonFieldNames(f.Type) {
+				names[name] = true
+			}
+			continue
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parseFields 解析以逗号分隔的 fields 参数，未提供时返回 nil（使用默认字段）
+// id 总是包含在结果中
+func parseFields(r *http.Request) (map[string]bool, error) {
+	value := r.URL.Query().Get("fields")
+	if value == "" {
+		return nil, nil
+	}
+	fields := map[string]bool{"id": true}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !blogFieldNames[name] {
+			return nil, fmt.Errorf("未知的字段: %s", name)
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// selectBlogFields 按 fields 裁剪文章字段，fields 为 nil 时去掉列表默认不返回的字段
+func selectBlogFields(blogs []*models.Blog, fields map[string]bool) ([]BlogFields, error) {
+	result := make([]BlogFields, len(blogs))
+	for i, blog := range blogs {
+		data, err := json.Marshal(blog)
+		if err != nil {
+			return nil, err
+		}
+		var all BlogFields
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		if fields == nil {
+			for _, name := range listOmittedFields {
+				delete(all, name)
+			}
+		} else {
+			for name := range all {
+				if !fields[name] {
+					delete(all, name)
+				}
+			}
+		}
+		result[i] = all
+	}
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"blog/middleware"
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead 上传请求中文件以外的 multipart 边界与头部所允许的大小
+const multipartOverhead = 1 << 20
+
+// MediaHandler 处理媒体文件的HTTP请求
+type MediaHandler struct {
+	mediaService *services.MediaService
+}
+
+// NewMediaHandler 创建新的MediaHandler实例
+func NewMediaHandler(mediaService *services.MediaService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+	}
+}
+
+// UploadMedia 通过 multipart/form-data 的 file 字段上传图片
+// 返回 201 表示新保存的文件，内容相同的文件已存在时返回 200 及已有记录
+func (h *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	maxBytes := h.mediaService.MaxBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, services.ErrMediaTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "请通过 multipart/form-data 的 file 字段上传文件", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	defer file.Close()
+
+	// 多读一个字节用于判断是否超过大小限制
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		http.Error(w, "读取上传文件失败", http.StatusBadRequest)
+		return
+	}
+
+	item, created, err := h.mediaService.Upload(services.MediaUpload{
+		Data:     data,
+		Filename: header.Filename,
+		Uploader: middleware.GetUsername(r),
+	})
+	if err != nil {
+		writeBlogError(w, err, "上传文件失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", item.URL)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(MediaResponse{Data: item})
+}
+
+// GetMedia 后台按上传时间倒序分页获取媒体文件及引用它们的文章
+func (h *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	page, limit := parsePagination(r)
+	items, total, err := h.mediaService.ListMedia(page, limit)
+	if err != nil {
+		writeBlogError(w, err, "获取媒体文件失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MediaListResponse{
+		Data:       items,
+		Pagination: Pagination{Page: page, Limit: limit, Total: &total},
+	})
+}
+
+// DeleteMedia 删除媒体文件，仍被文章（包括回收站中的文章）引用时返回 409
+func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.mediaService.DeleteMedia(id); err != nil {
+		writeBlogError(w, err, "删除媒体文件失败")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeMedia 输出媒体文件，内容由哈希确定永不改变，允许长期缓存；支持 Range 请求
+// ?w= 指定缩略图宽度（须为配置的宽度之一），?format=jpeg|png 指定缩略图格式
+func (h *MediaHandler) ServeMedia(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	hash := vars["hash"]
+
+	q := r.URL.Query()
+	variant := services.MediaVariant{Format: q.Get("format")}
+	if value := q.Get("w"); value != "" {
+		width, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, services.ErrInvalidMediaVariant.Error(), http.StatusBadRequest)
+			return
+		}
+		variant.Width = width
+	}
+
+	file, err := h.mediaService.OpenMedia(hash, variant)
+	if err != nil {
+		writeBlogError(w, err, "读取媒体文件失败")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+file.Name+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", file.Media.CreatedAt, file)
+}
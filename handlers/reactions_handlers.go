@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"blog/middleware"
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ReactionHandler 处理文章表情回应的HTTP请求
+type ReactionHandler struct {
+	reactionService *services.ReactionService
+}
+
+// NewReactionHandler 创建新的ReactionHandler实例
+func NewReactionHandler(reactionService *services.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+	}
+}
+
+// GetReactionKinds 获取可用的表情回应种类
+func (h *ReactionHandler) GetReactionKinds(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReactionKindsResponse{Data: h.reactionService.Kinds()})
+}
+
+// GetReactions 获取文章的表情回应数量及当前读者做出的回应
+func (h *ReactionHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	summary, err := h.reactionService.VisibleReactions(vars["id"], reactionActor(r))
+	if err != nil {
+		writeBlogError(w, err, "获取表情回应失败")
+		return
+	}
+	writeReactionSummary(w, summary)
+}
+
+// AddReaction 对文章做出表情回应，同一读者重复回应只计一次
+func (h *ReactionHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	summary, err := h.reactionService.React(vars["id"], vars["kind"], reactionActor(r))
+	if err != nil {
+		writeBlogError(w, err, "表情回应失败")
+		return
+	}
+	writeReactionSummary(w, summary)
+}
+
+// RemoveReaction 撤销对文章的表情回应
+func (h *ReactionHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	summary, err := h.reactionService.Unreact(vars["id"], vars["kind"], reactionActor(r))
+	if err != nil {
+		writeBlogError(w, err, "撤销表情回应失败")
+		return
+	}
+	writeReactionSummary(w, summary)
+}
+
+// reactionActor 识别做出回应的读者：登录用户按用户ID，匿名读者按 IP 的摘要（不保存原始 IP）
+// 不计入 User-Agent，否则更换 User-Agent 即可重复回应；IP 只在经过受信任的代理时取自转发头
+func reactionActor(r *http.Request) string {
+	if userID := middleware.GetUserID(r); userID != "" {
+		return "user:" + userID
+	}
+	sum := sha256.Sum256([]byte(clientIP(r)))
+	return "client:" + hex.EncodeToString(sum[:16])
+}
+
+func writeReactionSummary(w http.ResponseWriter, summary *services.ReactionSummary) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReactionSummaryResponse{Data: ReactionSummary{
+		Counts: summary.Counts,
+		Mine:   summary.Mine,
+	}})
+}
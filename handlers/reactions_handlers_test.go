@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"blog/middleware"
+)
+
+func TestReactionActor(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}
+
+	// actorFor 模拟经过 ClientIPMiddleware 的匿名请求，返回识别出的读者
+	actorFor := func(remoteAddr, userAgent, forwardedFor string) string {
+		r := httptest.NewRequest("POST", "/api/blog/1/reactions/like", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", userAgent)
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		var actor string
+		middleware.NewClientIPMiddleware(trusted).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor = reactionActor(r)
+		})).ServeHTTP(httptest.NewRecorder(), r)
+		return actor
+	}
+	base := actorFor("203.0.113.5:1234", "Mozilla/5.0", "")
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		userAgent    string
+		forwardedFor string
+		same         bool
+	}{
+		{"更换端口", "203.0.113.5:5678", "Mozilla/5.0", "", true},
+		{"更换 User-Agent", "203.0.113.5:1234", "curl/8.0", "", true},
+		{"直连时伪造转发头", "203.0.113.5:1234", "Mozilla/5.0", "198.51.100.1", true},
+		{"经受信任的代理转发", "10.0.0.1:80", "Mozilla/5.0", "203.0.113.5", true},
+		{"经受信任的代理转发的其他客户端", "10.0.0.1:80", "Mozilla/5.0", "198.51.100.1", false},
+		{"不同的 IP", "198.51.100.1:1234", "Mozilla/5.0", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := actorFor(tt.remoteAddr, tt.userAgent, tt.forwardedFor)
+			if (got == base) != tt.same {
+				t.Errorf("reactionActor = %q, base %q, same = %v, want %v", got, base, got == base, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultRelatedLimit 未指定 limit 时返回的相关文章数
+const defaultRelatedLimit = 5
+
+// GetRelatedBlogs 获取与文章最相似的前端可见文章（按标签、分类与正文相似度排序）
+// 文章本身不可见时返回 404；?limit= 指定数量，默认 5，最多 20
+func (h *BlogHandler) GetRelatedBlogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	limit := defaultRelatedLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 || n > services.MaxRelatedLimit {
+			http.Error(w, "无效的 limit 参数，取值范围 1-20", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	related, err := h.blogService.RelatedVisibleBlogs(id, limit)
+	if err != nil {
+		writeBlogError(w, err, "获取相关文章失败")
+		return
+	}
+
+	data := make([]RelatedPost, 0, len(related))
+	for _, rel := range related {
+		data = append(data, RelatedPost{
+			ID:          rel.Blog.ID.Hex(),
+			Title:       rel.Blog.Title,
+			Slug:        rel.Blog.Slug,
+			Excerpt:     rel.Blog.Excerpt,
+			Tags:        rel.Blog.Tags,
+			PublishedAt: rel.Blog.PublishedAt,
+			Score:       rel.Score,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RelatedResponse{Data: data})
+}
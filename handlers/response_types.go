@@ -1,25 +1,206 @@
 package handlers
 
 import (
+	"encoding/json"
+	"time"
+
 	"blog/models"
 )
 
 // Pagination 分页信息
+// 页码分页时返回 page；游标分页时不返回 page，total 仅在 with_total=true 时返回
 type Pagination struct {
-	Page  int64 `json:"page"`
-	Limit int64 `json:"limit"`
-	Total int64 `json:"total"`
+	Page       int64  `json:"page,omitempty"`
+	Limit      int64  `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // BlogListResponse 公共博客列表响应，主数据在 data 字段
+// 默认不包含正文 content，可通过 ?fields= 指定返回的字段
 type BlogListResponse struct {
-	Data       []*models.Blog `json:"data"`
-	Pagination Pagination     `json:"pagination"`
+	Data       []BlogFields `json:"data"`
+	Pagination Pagination   `json:"pagination"`
 }
 
+// BlogFields 按字段选择裁剪后的文章，键为 JSON 字段名
+type BlogFields map[string]json.RawMessage
+
 // BlogResponse 单篇博客响应
 type BlogResponse struct {
 	Data *models.Blog `json:"data"`
+	// ContentHTML 请求 ?render=html 时返回正文渲染后的 HTML（已清理不安全内容）
+	ContentHTML string `json:"content_html,omitempty"`
+	// CanonicalSlug 按 slug 访问时返回文章当前的 slug
+	CanonicalSlug string `json:"canonical_slug,omitempty"`
+	// Moved 为 true 表示通过历史 slug 访问，前端应 301 跳转到 CanonicalSlug
+	Moved bool `json:"moved,omitempty"`
+	// Series 文章所属的系列及前后篇
+	Series []SeriesNav `json:"series,omitempty"`
+}
+
+// SeriesNav 文章在系列中的位置及前后篇
+type SeriesNav struct {
+	ID       string      `json:"id"`
+	Slug     string      `json:"slug"`
+	Title    string      `json:"title"`
+	Position int         `json:"position"` // 从 1 开始
+	Total    int         `json:"total"`
+	Prev     *SeriesPart `json:"prev,omitempty"`
+	Next     *SeriesPart `json:"next,omitempty"`
+}
+
+// SeriesPart 系列中的一篇文章，不包含正文
+type SeriesPart struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Excerpt     string    `json:"excerpt,omitempty"`
+	Show        *bool     `json:"show,omitempty"` // 仅后台返回
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// SeriesItem 公开的系列信息，只统计前端可见的文章
+type SeriesItem struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	PostCount   int       `json:"post_count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SeriesListResponse 公开的系列列表响应
+type SeriesListResponse struct {
+	Data []SeriesItem `json:"data"`
+}
+
+// SeriesDetailResponse 公开的系列详情响应，posts 按阅读顺序排列
+type SeriesDetailResponse struct {
+	Data struct {
+		SeriesItem
+		Posts []SeriesPart `json:"posts"`
+	} `json:"data"`
+}
+
+// AdminSeriesListResponse 后台系列列表响应
+type AdminSeriesListResponse struct {
+	Data []*models.Series `json:"data"`
+}
+
+// AdminSeriesResponse 后台单个系列响应，posts 为系列中现存的全部文章（包含草稿）
+type AdminSeriesResponse struct {
+	Data  *models.Series `json:"data"`
+	Posts []SeriesPart   `json:"posts,omitempty"`
+}
+
+// VersionConflictResponse 版本冲突（412）响应，data 为文章的最新内容
+type VersionConflictResponse struct {
+	Error          string       `json:"error"`
+	CurrentVersion int64        `json:"current_version"`
+	Data           *models.Blog `json:"data"`
+}
+
+// TagListResponse 标签列表响应
+type TagListResponse struct {
+	Data []*models.TagCount `json:"data"`
+}
+
+// CategoryTreeResponse 分类树响应，顶级分类按名称排序
+type CategoryTreeResponse struct {
+	Data []*models.CategoryNode `json:"data"`
+}
+
+// CategoryResponse 单个分类响应
+type CategoryResponse struct {
+	Data *models.Category `json:"data"`
+}
+
+// SearchResult 单条搜索结果，不包含完整正文
+type SearchResult struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Author    string          `json:"author"`
+	Tags      []string        `json:"tags,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Score     float64         `json:"score"`
+	Highlight SearchHighlight `json:"highlight"`
+}
+
+// SearchHighlight 搜索命中高亮，内容已做 HTML 转义，命中词以 <mark> 包裹
+type SearchHighlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchResponse 搜索结果列表响应
+type SearchResponse struct {
+	Data       []SearchResult `json:"data"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+// ArchiveResponse 文章归档响应，按年份倒序排列
+type ArchiveResponse struct {
+	Data []*models.ArchiveYear `json:"data"`
+}
+
+// ArchiveYearResponse 某一年的归档响应
+type ArchiveYearResponse struct {
+	Data *models.ArchiveYear `json:"data"`
+}
+
+// ArchiveMonthResponse 某年某月的归档响应
+type ArchiveMonthResponse struct {
+	Data *models.ArchiveMonth `json:"data"`
+}
+
+// RelatedPost 一篇相关文章，不包含完整正文
+type RelatedPost struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Excerpt     string    `json:"excerpt,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	Score       float64   `json:"score"` // 相似度，0-1
+}
+
+// RelatedResponse 相关文章响应，按相似度倒序排列
+type RelatedResponse struct {
+	Data []RelatedPost `json:"data"`
+}
+
+// RevisionListResponse 文章版本列表响应，版本不包含正文
+type RevisionListResponse struct {
+	Data       []*models.Revision `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// RevisionResponse 单个版本响应
+type RevisionResponse struct {
+	Data *models.Revision `json:"data"`
+}
+
+// RevisionDiffLine 行级差异中的一行，op 为 equal、insert 或 delete
+type RevisionDiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// RevisionDiffResponse 两个版本的差异响应
+type RevisionDiffResponse struct {
+	Data struct {
+		From          *models.Revision   `json:"from"`
+		To            *models.Revision   `json:"to"`
+		ChangedFields []string           `json:"changed_fields"`
+		Added         int                `json:"added"`
+		Removed       int                `json:"removed"`
+		Lines         []RevisionDiffLine `json:"lines"`
+	} `json:"data"`
 }
 
 // AuthUserResponse 注册返回用户数据
@@ -34,3 +215,51 @@ type LoginResponse struct {
 		User  *models.User `json:"user"`
 	} `json:"data"`
 }
+
+// CommentTreeResponse 文章的评论树响应
+type CommentTreeResponse struct {
+	Data []*PublicComment `json:"data"`
+}
+
+// PublicCommentResponse 发表评论的响应，status 为 pending 时评论需审核后才展示
+type PublicCommentResponse struct {
+	Data *PublicComment `json:"data"`
+}
+
+// CommentListResponse 后台评论列表响应
+type CommentListResponse struct {
+	Data       []*models.Comment `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// CommentResponse 后台单条评论响应
+type CommentResponse struct {
+	Data *models.Comment `json:"data"`
+}
+
+// ReactionKindsResponse 可用的表情回应种类响应
+type ReactionKindsResponse struct {
+	Data []models.ReactionKind `json:"data"`
+}
+
+// ReactionSummary 文章的表情回应数量，mine 为当前读者做出的回应
+type ReactionSummary struct {
+	Counts map[string]int64 `json:"counts"`
+	Mine   []string         `json:"mine"`
+}
+
+// ReactionSummaryResponse 表情回应响应
+type ReactionSummaryResponse struct {
+	Data ReactionSummary `json:"data"`
+}
+
+// MediaResponse 单个媒体文件响应
+type MediaResponse struct {
+	Data *models.MediaItem `json:"data"`
+}
+
+// MediaListResponse 后台媒体文件列表响应
+type MediaListResponse struct {
+	Data       []*models.MediaItem `json:"data"`
+	Pagination Pagination          `json:"pagination"`
+}
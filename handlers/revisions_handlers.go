@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"blog/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+// GetRevisions 分页获取文章的版本历史（按版本号倒序，不含正文）
+func (h *BlogHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	page, limit := parsePagination(r)
+	revs, total, err := h.blogService.ListRevisions(id, page, limit)
+	if err != nil {
+		writeBlogError(w, err, "获取版本历史失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RevisionListResponse{
+		Data:       revs,
+		Pagination: Pagination{Page: page, Limit: limit, Total: &total},
+	})
+}
+
+// GetRevision 获取文章的指定版本（含正文）
+func (h *BlogHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	number, ok := parseRevisionNumber(vars["rev"])
+	if !ok {
+		http.Error(w, "无效的版本号", http.StatusBadRequest)
+		return
+	}
+
+	rev, err := h.blogService.GetRevision(id, number)
+	if err != nil {
+		writeBlogError(w, err, "获取版本失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RevisionResponse{Data: rev})
+}
+
+// DiffRevisions 比较文章的两个版本（from、to 为版本号），返回正文的行级差异
+func (h *BlogHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	from, okFrom := parseRevisionNumber(r.URL.Query().Get("from"))
+	to, okTo := parseRevisionNumber(r.URL.Query().Get("to"))
+	if !okFrom || !okTo {
+		http.Error(w, "from 与 to 须为有效的版本号", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.blogService.DiffRevisions(id, from, to)
+	if err != nil {
+		writeBlogError(w, err, "比较版本失败")
+		return
+	}
+
+	var resp RevisionDiffResponse
+	resp.Data.From = diff.From
+	resp.Data.To = diff.To
+	resp.Data.ChangedFields = diff.ChangedFields
+	if resp.Data.ChangedFields == nil {
+		resp.Data.ChangedFields = []string{}
+	}
+	resp.Data.Added = diff.Added
+	resp.Data.Removed = diff.Removed
+	resp.Data.Lines = make([]RevisionDiffLine, len(diff.Lines))
+	for i, l := range diff.Lines {
+		resp.Data.Lines[i] = RevisionDiffLine{Op: l.Op, Text: l.Text, OldLine: l.OldLine, NewLine: l.NewLine}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RollbackBlog 将文章回滚到指定版本，回滚本身会记录为新版本
+func (h *BlogHandler) RollbackBlog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	number, ok := parseRevisionNumber(vars["rev"])
+	if !ok {
+		http.Error(w, "无效的版本号", http.StatusBadRequest)
+		return
+	}
+
+	username := middleware.GetUsername(r)
+	if username == "" {
+		http.Error(w, "未认证用户", http.StatusUnauthorized)
+		return
+	}
+
+	blog, err := h.blogService.RollbackBlog(id, number, username)
+	if err != nil {
+		writeBlogError(w, err, "回滚文章失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", blogETag(blog))
+	json.NewEncoder(w).Encode(BlogResponse{Data: blog})
+}
+
+// parseRevisionNumber 解析版本号，须为正整数
+func parseRevisionNumber(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
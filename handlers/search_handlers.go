@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Search 全文检索前端可见的文章，按相关度排序并返回高亮片段
+func (h *BlogHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, "搜索关键词不能为空", http.StatusBadRequest)
+		return
+	}
+	page, limit := parsePagination(r)
+
+	result, err := h.blogService.SearchVisibleBlogs(q, page, limit)
+	if err != nil {
+		http.Error(w, "搜索失败", http.StatusInternalServerError)
+		return
+	}
+
+	data := make([]SearchResult, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		data = append(data, SearchResult{
+			ID:        hit.Blog.ID.Hex(),
+			Title:     hit.Blog.Title,
+			Author:    hit.Blog.Author,
+			Tags:      hit.Blog.Tags,
+			CreatedAt: hit.Blog.CreatedAt,
+			UpdatedAt: hit.Blog.UpdatedAt,
+			Score:     hit.Score,
+			Highlight: SearchHighlight{Title: hit.Title, Snippet: hit.Snippet},
+		})
+	}
+
+	total := result.Total
+	response := SearchResponse{
+		Data:       data,
+		Pagination: Pagination{Page: page, Limit: limit, Total: &total},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"blog/models"
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// SeriesHandler 处理系列文章的HTTP请求
+type SeriesHandler struct {
+	seriesService *services.SeriesService
+}
+
+// NewSeriesHandler 创建新的SeriesHandler实例
+func NewSeriesHandler(seriesService *services.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		seriesService: seriesService,
+	}
+}
+
+// GetSeriesList 获取包含前端可见文章的全部系列
+func (h *SeriesHandler) GetSeriesList(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.seriesService.ListVisibleSeries()
+	if err != nil {
+		http.Error(w, "获取系列失败", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]SeriesItem, len(summaries))
+	for i, summary := range summaries {
+		items[i] = seriesItem(summary.Series, summary.PostCount)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SeriesListResponse{Data: items})
+}
+
+// GetSeriesBySlug 根据 slug 获取系列及其中前端可见的文章
+func (h *SeriesHandler) GetSeriesBySlug(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	series, posts, err := h.seriesService.GetVisibleSeriesBySlug(slug)
+	if err != nil {
+		writeBlogError(w, err, "获取系列失败")
+		return
+	}
+
+	var resp SeriesDetailResponse
+	resp.Data.SeriesItem = seriesItem(series, len(posts))
+	resp.Data.Posts = make([]SeriesPart, len(posts))
+	for i, post := range posts {
+		resp.Data.Posts[i] = *seriesPart(post, false)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetAdminSeriesList 后台获取全部系列
+func (h *SeriesHandler) GetAdminSeriesList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.seriesService.ListSeries()
+	if err != nil {
+		http.Error(w, "获取系列失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminSeriesListResponse{Data: list})
+}
+
+// GetAdminSeries 后台获取单个系列及其中的全部文章（包含草稿）
+func (h *SeriesHandler) GetAdminSeries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	series, posts, err := h.seriesService.GetSeries(id)
+	if err != nil {
+		writeBlogError(w, err, "获取系列失败")
+		return
+	}
+
+	parts := make([]SeriesPart, len(posts))
+	for i, post := range posts {
+		parts[i] = *seriesPart(post, true)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminSeriesResponse{Data: series, Posts: parts})
+}
+
+// seriesRequest 创建或更新系列的请求体，post_ids 为按阅读顺序排列的文章ID
+type seriesRequest struct {
+	Title       *string  `json:"title,omitempty"`
+	Slug        *string  `json:"slug,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	PostIDs     []string `json:"post_ids,omitempty"`
+}
+
+// CreateSeries 创建新系列
+func (h *SeriesHandler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	var req seriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+	if req.Title == nil || strings.TrimSpace(*req.Title) == "" {
+		http.Error(w, "系列标题不能为空", http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.seriesService.CreateSeries(services.SeriesInput{
+		Title:       req.Title,
+		Slug:        req.Slug,
+		Description: req.Description,
+		PostIDs:     req.PostIDs,
+	})
+	if err != nil {
+		writeBlogError(w, err, "创建系列失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(AdminSeriesResponse{Data: series})
+}
+
+// UpdateSeries 更新系列，传入 post_ids 时按其顺序完整替换系列中的文章（用于排序与移除）
+func (h *SeriesHandler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req seriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		http.Error(w, "系列标题不能为空", http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.seriesService.UpdateSeries(id, services.SeriesInput{
+		Title:       req.Title,
+		Slug:        req.Slug,
+		Description: req.Description,
+		PostIDs:     req.PostIDs,
+	})
+	if err != nil {
+		writeBlogError(w, err, "更新系列失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminSeriesResponse{Data: series})
+}
+
+// AddSeriesPost 将文章加入系列，position 为从 0 开始的插入位置，省略时追加到末尾
+func (h *SeriesHandler) AddSeriesPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		PostID   string `json:"post_id"`
+		Position *int   `json:"position,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		return
+	}
+	position := -1
+	if req.Position != nil && *req.Position >= 0 {
+		position = *req.Position
+	}
+
+	series, err := h.seriesService.AddPost(id, req.PostID, position)
+	if err != nil {
+		writeBlogError(w, err, "添加文章到系列失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminSeriesResponse{Data: series})
+}
+
+// RemoveSeriesPost 将文章移出系列，文章本身不受影响
+func (h *SeriesHandler) RemoveSeriesPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	postID := vars["postID"]
+
+	series, err := h.seriesService.RemovePost(id, postID)
+	if err != nil {
+		writeBlogError(w, err, "从系列移除文章失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminSeriesResponse{Data: series})
+}
+
+// DeleteSeries 删除系列，其中的文章不受影响
+func (h *SeriesHandler) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.seriesService.DeleteSeries(id); err != nil {
+		writeBlogError(w, err, "删除系列失败")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// seriesItem 转换为公开的系列信息
+func seriesItem(series *models.Series, postCount int) SeriesItem {
+	return SeriesItem{
+		ID:          series.ID.Hex(),
+		Title:       series.Title,
+		Slug:        series.Slug,
+		Description: series.Description,
+		PostCount:   postCount,
+		UpdatedAt:   series.UpdatedAt,
+	}
+}
+
+// seriesPart 转换为系列中的文章摘要，withShow 为 true 时附带展示状态（后台使用）
+func seriesPart(blog *models.Blog, withShow bool) *SeriesPart {
+	if blog == nil {
+		return nil
+	}
+	part := &SeriesPart{
+		ID:          blog.ID.Hex(),
+		Title:       blog.Title,
+		Slug:        blog.Slug,
+		Excerpt:     blog.Excerpt,
+		PublishedAt: blog.PublishedAt,
+	}
+	if withShow {
+		show := blog.Show
+		part.Show = &show
+	}
+	return part
+}
+
+// seriesNavs 转换文章的系列导航信息
+func seriesNavs(navs []*services.SeriesNav) []SeriesNav {
+	result := make([]SeriesNav, len(navs))
+	for i, nav := range navs {
+		result[i] = SeriesNav{
+			ID:       nav.Series.ID.Hex(),
+			Slug:     nav.Series.Slug,
+			Title:    nav.Series.Title,
+			Position: nav.Position,
+			Total:    nav.Total,
+			Prev:     seriesPart(nav.Prev, false),
+			Next:     seriesPart(nav.Next, false),
+		}
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// SitemapHandler 输出站点地图与 robots.txt
+type SitemapHandler struct {
+	sitemapService *services.SitemapService
+}
+
+// NewSitemapHandler 创建新的SitemapHandler实例
+func NewSitemapHandler(sitemapService *services.SitemapService) *SitemapHandler {
+	return &SitemapHandler{
+		sitemapService: sitemapService,
+	}
+}
+
+// GetSitemap 输出站点地图，链接数超过单个文件上限时输出指向 /sitemap-{n}.xml 的站点地图索引
+func (h *SitemapHandler) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	chunks, err := h.sitemapService.VisibleSitemap()
+	if err != nil {
+		http.Error(w, "生成站点地图失败", http.StatusInternalServerError)
+		return
+	}
+
+	if len(chunks) <= 1 {
+		var urls []services.SitemapURL
+		if len(chunks) == 1 {
+			urls = chunks[0]
+		}
+		writeSitemapXML(w, newURLSet(urls))
+		return
+	}
+
+	site := h.sitemapService.Site()
+	index := sitemapIndex{
+		XMLNS:    sitemapNS,
+		Sitemaps: make([]sitemapEntry, len(chunks)),
+	}
+	for i, chunk := range chunks {
+		var modified time.Time
+		for _, u := range chunk {
+			if u.LastMod.After(modified) {
+				modified = u.LastMod
+			}
+		}
+		index.Sitemaps[i] = sitemapEntry{
+			Loc:     site.AbsURL(fmt.Sprintf("/sitemap-%d.xml", i+1)),
+			LastMod: formatLastMod(modified),
+		}
+	}
+	writeSitemapXML(w, index)
+}
+
+// GetSitemapPart 输出站点地图索引中的第 n 个文件，n 从 1 开始
+func (h *SitemapHandler) GetSitemapPart(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	n, err := strconv.Atoi(vars["n"])
+	if err != nil || n < 1 {
+		http.Error(w, "站点地图不存在", http.StatusNotFound)
+		return
+	}
+
+	chunks, err := h.sitemapService.VisibleSitemap()
+	if err != nil {
+		http.Error(w, "生成站点地图失败", http.StatusInternalServerError)
+		return
+	}
+	if n > len(chunks) {
+		http.Error(w, "站点地图不存在", http.StatusNotFound)
+		return
+	}
+
+	writeSitemapXML(w, newURLSet(chunks[n-1]))
+}
+
+// GetRobots 输出 robots.txt
+func (h *SitemapHandler) GetRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(h.sitemapService.RobotsTxt()))
+}
+
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// urlSet 站点地图文件
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapIndex 站点地图索引文件
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	XMLNS    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func newURLSet(urls []services.SitemapURL) urlSet {
+	set := urlSet{XMLNS: sitemapNS, URLs: make([]sitemapURL, len(urls))}
+	for i, u := range urls {
+		set.URLs[i] = sitemapURL{Loc: u.Loc, LastMod: formatLastMod(u.LastMod)}
+	}
+	return set
+}
+
+// formatLastMod 按 W3C Datetime 格式输出最后修改时间，零值时省略
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeSitemapXML(w http.ResponseWriter, doc any) {
+	body, err := marshalXML(doc)
+	if err != nil {
+		http.Error(w, "生成站点地图失败", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(body)
+}
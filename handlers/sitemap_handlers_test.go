@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blog/models"
+	"blog/services"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sitemapPostsStore 返回 n 篇生成的站点地图文章，避免逐篇写入大量文章
+type sitemapPostsStore struct {
+	*services.MemoryBlogStore
+	n int
+}
+
+func (s *sitemapPostsStore) ListSitemapPosts(context.Context, services.BlogQuery) ([]*models.SitemapPost, error) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	posts := make([]*models.SitemapPost, s.n)
+	for i := range posts {
+		at := epoch.Add(-time.Duration(i) * time.Minute)
+		posts[i] = &models.SitemapPost{ID: primitive.NewObjectID(), Slug: fmt.Sprintf("post-%d", i), PublishedAt: at, UpdatedAt: at}
+	}
+	return posts, nil
+}
+
+func TestSitemapIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		posts int
+		index bool
+		parts int
+	}{
+		// 首页加文章恰好达到上限时仍为单个文件
+		{"未超出上限", services.SitemapMaxURLs - 1, false, 1},
+		{"超出上限时输出索引", services.SitemapMaxURLs, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &sitemapPostsStore{MemoryBlogStore: services.NewMemoryBlogStore(), n: tt.posts}
+			blogService := services.NewBlogService(store, services.NewMemoryRevisionStore(), services.NewMemoryCategoryStore(),
+				services.NewMemoryCommentStore(), services.NewMemoryReactionStore(), services.NewMemorySeriesStore(), 50)
+			site := services.SiteConfig{URL: "https://example.com", PostPath: "/blog/{slug}"}
+			sitemapHandler := NewSitemapHandler(services.NewSitemapService(blogService, site, time.UTC, services.RobotsConfig{}))
+			r := mux.NewRouter()
+			r.HandleFunc("/sitemap.xml", sitemapHandler.GetSitemap).Methods("GET")
+			r.HandleFunc("/sitemap-{n:[0-9]+}.xml", sitemapHandler.GetSitemapPart).Methods("GET")
+			get := func(path string) *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+				return rec
+			}
+
+			rec := get("/sitemap.xml")
+			if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+				t.Fatalf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+			}
+			if !tt.index {
+				var set urlSet
+				if err := xml.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+					t.Fatal(err)
+				}
+				if len(set.URLs) != services.SitemapMaxURLs {
+					t.Errorf("链接数 = %d, want %d", len(set.URLs), services.SitemapMaxURLs)
+				}
+				return
+			}
+
+			var index sitemapIndex
+			if err := xml.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+				t.Fatal(err)
+			}
+			if len(index.Sitemaps) != tt.parts {
+				t.Fatalf("索引中的文件数 = %d, want %d", len(index.Sitemaps), tt.parts)
+			}
+			// 每个文件的 lastmod 为其中最晚的时间，第二个文件只有最早的一篇文章
+			wantLastMod := []string{"2024-01-01T00:00:00Z", "2023-11-27T06:41:00Z"}
+			total := 0
+			for i, entry := range index.Sitemaps {
+				path := fmt.Sprintf("/sitemap-%d.xml", i+1)
+				if entry.Loc != "https://example.com"+path || entry.LastMod != wantLastMod[i] {
+					t.Errorf("索引项 %d = %s %s", i+1, entry.Loc, entry.LastMod)
+				}
+				rec := get(path)
+				var set urlSet
+				if err := xml.Unmarshal(rec.Body.Bytes(), &set); rec.Code != http.StatusOK || err != nil {
+					t.Fatalf("%s status = %d, err = %v", path, rec.Code, err)
+				}
+				total += len(set.URLs)
+			}
+			if total != tt.posts+1 {
+				t.Errorf("链接总数 = %d, want %d", total, tt.posts+1)
+			}
+			for _, path := range []string{fmt.Sprintf("/sitemap-%d.xml", tt.parts+1), "/sitemap-0.xml"} {
+				if rec := get(path); rec.Code != http.StatusNotFound {
+					t.Errorf("%s status = %d, want 404", path, rec.Code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTags 获取前端可见文章使用的全部标签及文章数（用于标签云）
+func (h *BlogHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.blogService.ListVisibleTags()
+	if err != nil {
+		http.Error(w, "获取标签失败", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TagListResponse{Data: tags})
+}
+
+// GetTagBlogs 分页获取包含指定标签的文章（仅前端可见的文章）
+func (h *BlogHandler) GetTagBlogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tag := vars["tag"]
+
+	query := services.BlogQuery{Tags: services.NormalizeTags([]string{tag})}
+	if len(query.Tags) == 0 {
+		http.Error(w, "标签不能为空", http.StatusBadRequest)
+		return
+	}
+	withTotal, err := parseListPagination(r, &query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.blogService.ListVisibleBlogs(query, withTotal)
+	if err != nil {
+		http.Error(w, "获取文章失败", http.StatusInternalServerError)
+		return
+	}
+
+	writeBlogPage(w, r, query, page)
+}
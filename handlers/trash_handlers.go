@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"blog/services"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTrash 分页获取回收站中的文章
+func (h *BlogHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	var query services.BlogQuery
+	withTotal, err := parseListPagination(r, &query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.blogService.ListTrash(query, withTotal)
+	if err != nil {
+		http.Error(w, "获取回收站失败", http.StatusInternalServerError)
+		return
+	}
+
+	writeBlogPage(w, r, query, page)
+}
+
+// RestoreBlog 将文章移出回收站
+func (h *BlogHandler) RestoreBlog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	blog, err := h.blogService.RestoreBlog(id)
+	if err != nil {
+		writeBlogError(w, err, "恢复文章失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", blogETag(blog))
+	json.NewEncoder(w).Encode(BlogResponse{Data: blog})
+}
+
+// PurgeBlog 永久删除回收站中的文章
+func (h *BlogHandler) PurgeBlog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.blogService.PurgeBlog(id); err != nil {
+		writeBlogError(w, err, "永久删除文章失败")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"blog/handlers"
@@ -19,53 +24,146 @@ import (
 )
 
 func main() {
-	// 从环境变量读取配置，若未设置使用默认值
-	mongoURI := getEnv("MONGO_URI", "mongodb://localhost:27017")
-	// MongoDB 数据库名称
-	dbName := getEnv("DB_NAME", "blogs-db-dev")
-	// MongoDB 集合名称
-	collectionName := getEnv("COLLECTION_NAME", "blogs-db")
-
-	// 连接到 MongoDB
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// 带参数时执行子命令（如 import markdown），不带参数时启动服务器
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	st, closeStores := openStores()
+	defer closeStores()
+
+	// 初始化服务
+	blogService := newBlogService(st)
+	seriesService := services.NewSeriesService(st.series, blogService)
+	categoryService := services.NewCategoryService(st.categories, blogService)
+	// 匿名评论默认需审核后展示，COMMENT_MODERATION=false 时直接展示
+	commentService := services.NewCommentService(st.comments, blogService, getBoolEnv("COMMENT_MODERATION", true))
+	// 可用的表情回应由 REACTIONS 配置，格式为逗号分隔的 name:emoji
+	reactionKinds, err := services.ParseReactionKinds(getEnv("REACTIONS", "like:👍,heart:❤️,tada:🎉"))
 	if err != nil {
-		log.Fatal("连接 MongoDB 失败:", err)
+		log.Fatal(err)
 	}
-	defer func() {
-		if err = client.Disconnect(ctx); err != nil {
-			log.Fatal("断开 MongoDB 连接失败:", err)
-		}
-	}()
+	reactionService := services.NewReactionService(st.reactions, blogService, reactionKinds)
 
-	// 验证连接
-	err = client.Ping(ctx, nil)
+	// 单个上传的媒体文件默认不超过 10MB
+	// 图片的缩略图宽度由 MEDIA_WIDTHS 配置（逗号分隔），按需生成并缓存在本地目录 MEDIA_CACHE_DIR
+	mediaStore := openMediaStore()
+	mediaWidths, err := services.ParseMediaWidths(getEnv("MEDIA_WIDTHS", "320,768,1280"))
 	if err != nil {
-		log.Fatal("MongoDB 连接验证失败:", err)
+		log.Fatal(err)
 	}
-	fmt.Println("成功连接到 MongoDB")
+	mediaService := services.NewMediaService(st.mediaMeta, mediaStore, blogService,
+		getIntEnv("MEDIA_MAX_BYTES", 10<<20), mediaWidths, getEnv("MEDIA_CACHE_DIR", "media-cache"))
+	// 渲染正文时为上传的图片输出指向缩略图的 srcset
+	blogService.SetImageSrcset(mediaService.Srcset)
 
-	// 初始化服务
-	blogService := services.NewBlogService(client, dbName, collectionName)
+	// 归档按 ARCHIVE_TIMEZONE 时区（IANA 名称，如 Asia/Shanghai）划分年月
+	archiveLocation, err := time.LoadLocation(getEnv("ARCHIVE_TIMEZONE", "UTC"))
+	if err != nil || archiveLocation == time.Local {
+		log.Fatal("无效的 ARCHIVE_TIMEZONE，请使用 IANA 时区名称，如 Asia/Shanghai")
+	}
+	archiveService := services.NewArchiveService(blogService, archiveLocation)
+
+	// 订阅源与站点地图中的绝对链接以前端站点 SITE_URL 为根，*_URL_PATTERN 为前端页面的路径模板，
+	// 标签与归档页面的模板设为 - 表示前端没有对应页面
+	site := services.SiteConfig{
+		Title:            getEnv("SITE_TITLE", "博客"),
+		Description:      getEnv("SITE_DESCRIPTION", ""),
+		URL:              getEnv("SITE_URL", "http://localhost:"+getEnv("PORT", "8080")),
+		PostPath:         getEnv("POST_URL_PATTERN", "/blog/{slug}"),
+		TagPath:          getPatternEnv("TAG_URL_PATTERN", "/tags/{tag}"),
+		ArchiveYearPath:  getPatternEnv("ARCHIVE_YEAR_URL_PATTERN", "/archive/{year}"),
+		ArchiveMonthPath: getPatternEnv("ARCHIVE_MONTH_URL_PATTERN", "/archive/{year}/{month}"),
+	}
+	// FEED_CONTENT=full 时订阅源输出渲染后的全文，默认只输出摘要
+	feedService := services.NewFeedService(blogService, site, getEnv("FEED_CONTENT", "excerpt") == "full", getIntEnv("FEED_LIMIT", 20))
+
+	// robots.txt 默认禁止抓取 API（ROBOTS_DISALLOW 为逗号分隔的路径前缀），ROBOTS_TXT_FILE 指定时原样输出该文件
+	robots := services.RobotsConfig{Disallow: splitEnv("ROBOTS_DISALLOW", "/api/")}
+	if path := os.Getenv("ROBOTS_TXT_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal("读取 ROBOTS_TXT_FILE 失败:", err)
+		}
+		robots.Content = string(content)
+	}
+	sitemapService := services.NewSitemapService(blogService, site, archiveLocation, robots)
+	exportService := services.NewExportService(blogService, st.series, st.users, st.mediaMeta, mediaStore)
+
+	// 为旧文章补齐检索词、slug、媒体文件引用等派生字段
+	go func() {
+		if n, err := blogService.BackfillDerivedFields(); err != nil {
+			log.Println("补齐文章派生字段失败:", err)
+		} else if n > 0 {
+			log.Printf("已为 %d 篇文章补齐派生字段", n)
+		}
+	}()
+
+	// 建立相关文章索引，之后随文章的创建与修改增量更新
+	go func() {
+		if n, err := blogService.BuildRelatedIndex(); err != nil {
+			log.Println("建立相关文章索引失败:", err)
+		} else {
+			log.Printf("相关文章索引已建立，共 %d 篇文章", n)
+		}
+	}()
 
 	// 初始化认证服务
 	jwtSecret := getEnv("JWT_SECRET", "your-secret-key") // 在生产环境中请通过环境变量注入
-	authService := services.NewAuthService(client, dbName, "users", jwtSecret)
+	authService := services.NewAuthService(st.users, jwtSecret)
+
+	// 浏览计数：同一客户端在去重窗口内只计一次，缓冲后定期批量写入
+	viewCounter := services.NewViewCounter(st.blogs,
+		getDurationEnv("VIEW_DEDUP_WINDOW", 30*time.Minute),
+		getDurationEnv("VIEW_FLUSH_INTERVAL", 10*time.Second),
+	)
+	viewCounter.Start()
+
+	// 回收站清理：超过保留期的文章被永久删除
+	trashPurger := services.NewTrashPurger(blogService,
+		getDurationEnv("TRASH_RETENTION", 30*24*time.Hour),
+		getDurationEnv("TRASH_PURGE_INTERVAL", time.Hour),
+	)
+	trashPurger.Start()
+
+	// 定时发布：文章到达发布/下线时间时发出事件
+	publishScheduler := services.NewPublishScheduler(blogService,
+		getDurationEnv("PUBLISH_CHECK_INTERVAL", time.Minute),
+	)
+	publishScheduler.Subscribe(func(e services.PublishEvent) {
+		log.Printf("文章 %s《%s》定时事件: %s", e.Blog.ID.Hex(), e.Blog.Title, e.Type)
+	})
+	publishScheduler.Start()
 
 	// 初始化处理器
-	blogHandler := handlers.NewBlogHandler(blogService)
+	blogHandler := handlers.NewBlogHandler(blogService, viewCounter, seriesService)
+	seriesHandler := handlers.NewSeriesHandler(seriesService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+	feedHandler := handlers.NewFeedHandler(feedService)
+	sitemapHandler := handlers.NewSitemapHandler(sitemapService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	reactionHandler := handlers.NewReactionHandler(reactionService)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	exportHandler := handlers.NewExportHandler(exportService)
 	authHandler := handlers.NewAuthHandler(authService)
 
 	// 初始化中间件
 	jwtMiddleware := middleware.NewJWTMiddleware(authService)
+	// 只有来自 TRUSTED_PROXIES（逗号分隔的 IP 或网段，如 127.0.0.1,172.16.0.0/12）的请求才读取
+	// X-Forwarded-For 与 X-Real-IP，浏览去重、评论 IP 与表情回应去重都依赖客户端地址
+	trustedProxies, err := middleware.ParseTrustedProxies(splitEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatal("无效的 TRUSTED_PROXIES: ", err)
+	}
+	clientIPMiddleware := middleware.NewClientIPMiddleware(trustedProxies)
 
 	// 创建路由
 	r := mux.NewRouter()
+	r.Use(clientIPMiddleware.Handler)
 
 	// 注册路由（集中管理）
-	routes.RegisterRoutes(r, blogHandler, authHandler, jwtMiddleware)
+	routes.RegisterRoutes(r, blogHandler, seriesHandler, categoryHandler, archiveHandler, feedHandler, sitemapHandler, commentHandler, reactionHandler, mediaHandler, exportHandler, authHandler, jwtMiddleware)
 
 	// 健康检查端点
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -76,9 +174,102 @@ func main() {
 	// 启动服务器
 	port := getEnv("PORT", "8080")
 	addr := fmt.Sprintf(":%s", port)
-	fmt.Printf("博客服务器启动在 http://localhost:%s\n", port)
-	log.Fatal(http.ListenAndServe(addr, r))
+	srv := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		fmt.Printf("博客服务器启动在 http://localhost:%s\n", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// 收到退出信号后优雅关闭：等待进行中的请求完成，再写入缓冲的浏览次数
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	fmt.Println("正在关闭服务器...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("关闭服务器失败:", err)
+	}
+	publishScheduler.Stop()
+	trashPurger.Stop()
+	viewCounter.Stop()
+}
+
+// connectMongo 连接 MongoDB 并验证连接，失败时直接退出
+func connectMongo(mongoURI string) *mongo.Client {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatal("连接 MongoDB 失败:", err)
+	}
 
+	// 验证连接
+	if err = client.Ping(ctx, nil); err != nil {
+		log.Fatal("MongoDB 连接验证失败:", err)
+	}
+	log.Println("成功连接到 MongoDB")
+	return client
+}
+
+// getDurationEnv 从环境变量读取时长（如 30m、10s），若为空或格式错误则返回默认值
+func getDurationEnv(key string, defaultVal time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值 %s", key, v, defaultVal)
+	}
+	return defaultVal
+}
+
+// getIntEnv 从环境变量读取非负整数，若为空或格式错误则返回默认值
+func getIntEnv(key string, defaultVal int64) int64 {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			return n
+		}
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值 %d", key, v, defaultVal)
+	}
+	return defaultVal
+}
+
+// getBoolEnv 从环境变量读取布尔值，若为空或格式错误则返回默认值
+func getBoolEnv(key string, defaultVal bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值 %t", key, v, defaultVal)
+	}
+	return defaultVal
+}
+
+// getPatternEnv 从环境变量读取页面路径模板，若为空则返回默认值，为 - 时返回空字符串
+func getPatternEnv(key, defaultVal string) string {
+	if v := getEnv(key, defaultVal); v != "-" {
+		return v
+	}
+	return ""
+}
+
+// splitEnv 从环境变量读取逗号分隔的列表，若未设置则使用默认值，忽略空项
+func splitEnv(key, defaultVal string) []string {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		v = defaultVal
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
 }
 
 // getEnv 从环境变量读取值，若为空则返回默认值
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIPMiddleware 还原经过反向代理的客户端地址
+// 只有直接连接方是受信任的代理时才读取 X-Forwarded-For 与 X-Real-IP，否则任何客户端都能伪造地址
+type ClientIPMiddleware struct {
+	trusted []netip.Prefix
+}
+
+// NewClientIPMiddleware 创建新的ClientIPMiddleware实例，trusted 为空时不信任任何转发头
+func NewClientIPMiddleware(trusted []netip.Prefix) *ClientIPMiddleware {
+	return &ClientIPMiddleware{
+		trusted: trusted,
+	}
+}
+
+// ParseTrustedProxies 解析受信任的代理列表，每项为 IP 地址（如 10.0.0.1）或网段（如 172.16.0.0/12）
+func ParseTrustedProxies(list []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(list))
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("无效的代理网段 %q", item)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("无效的代理地址 %q", item)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Handler 请求来自受信任的代理时，将 r.RemoteAddr 替换为转发头中的客户端地址
+// X-Forwarded-For 由右向左跳过受信任的代理，取第一个不受信任的地址；没有 X-Forwarded-For 时使用 X-Real-IP
+func (m *ClientIPMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if addr, ok := m.clientAddr(r); ok {
+			r.RemoteAddr = netip.AddrPortFrom(addr, 0).String()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientAddr 返回转发头中的客户端地址，直接连接方不受信任或转发头无效时返回 false
+func (m *ClientIPMiddleware) clientAddr(r *http.Request) (netip.Addr, bool) {
+	peer, ok := parseHostAddr(r.RemoteAddr)
+	if !ok || !m.isTrusted(peer) {
+		return netip.Addr{}, false
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			hops = []string{realIP}
+		}
+	}
+
+	client, found := peer, false
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// 无效的地址之前的内容都不可信，使用最后一个经过验证的地址
+			break
+		}
+		client, found = addr.Unmap(), true
+		if !m.isTrusted(client) {
+			break
+		}
+	}
+	return client, found
+}
+
+// isTrusted 判断地址是否属于受信任的代理
+func (m *ClientIPMiddleware) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range m.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHostAddr 解析 host:port 或纯 IP 形式的地址
+func parseHostAddr(s string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		host = s
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		list    []string
+		want    []string
+		wantErr bool
+	}{
+		{nil, []string{}, false},
+		{[]string{"127.0.0.1", " 10.0.0.0/8 "}, []string{"127.0.0.1/32", "10.0.0.0/8"}, false},
+		{[]string{"::1", "172.16.5.4/12"}, []string{"::1/128", "172.16.0.0/12"}, false},
+		{[]string{"::ffff:192.168.1.1"}, []string{"192.168.1.1/32"}, false},
+		{[]string{"localhost"}, nil, true},
+		{[]string{"10.0.0.0/33"}, nil, true},
+	}
+	for _, tt := range tests {
+		prefixes, err := ParseTrustedProxies(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTrustedProxies(%q) err = %v, wantErr %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		got := make([]string, len(prefixes))
+		for i, p := range prefixes {
+			got[i] = p.String()
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseTrustedProxies(%q) = %v, want %v", tt.list, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseTrustedProxies(%q) = %v, want %v", tt.list, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestClientIPMiddleware(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewClientIPMiddleware(trusted)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		realIP     string
+		want       string
+	}{
+		{"直接访问", "203.0.113.7:5000", nil, "", "203.0.113.7:5000"},
+		{"不受信任的来源伪造转发头", "203.0.113.7:5000", []string{"1.2.3.4"}, "5.6.7.8", "203.0.113.7:5000"},
+		{"受信任的代理", "10.0.0.1:443", []string{"198.51.100.2"}, "", "198.51.100.2:0"},
+		{"跳过多级受信任的代理", "10.0.0.1:443", []string{"198.51.100.2, 172.16.3.4"}, "", "198.51.100.2:0"},
+		{"客户端在最左侧伪造的地址被忽略", "10.0.0.1:443", []string{"1.1.1.1, 198.51.100.2"}, "", "198.51.100.2:0"},
+		{"多个转发头按顺序合并", "10.0.0.1:443", []string{"1.1.1.1", "198.51.100.2"}, "", "198.51.100.2:0"},
+		{"转发链全部受信任时取最左侧", "10.0.0.1:443", []string{"172.16.0.9, 172.16.0.8"}, "", "172.16.0.9:0"},
+		{"无效地址之前的内容不可信", "10.0.0.1:443", []string{"1.1.1.1, garbage, 198.51.100.2"}, "", "198.51.100.2:0"},
+		{"最右侧无效时保留连接地址", "10.0.0.1:443", []string{"1.1.1.1, garbage"}, "", "10.0.0.1:443"},
+		{"没有 X-Forwarded-For 时使用 X-Real-IP", "10.0.0.1:443", nil, "198.51.100.3", "198.51.100.3:0"},
+		{"X-Forwarded-For 优先于 X-Real-IP", "10.0.0.1:443", []string{"198.51.100.2"}, "198.51.100.3", "198.51.100.2:0"},
+		{"IPv6 代理与客户端", "[::1]:8080", []string{"2001:db8::1"}, "", "[2001:db8::1]:0"},
+		{"受信任的代理未带转发头", "10.0.0.1:443", nil, "", "10.0.0.1:443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
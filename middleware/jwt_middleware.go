@@ -70,6 +70,18 @@ func (m *JWTMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// OptionalAuthenticate 请求未携带认证令牌时按匿名用户放行，携带时与 Authenticate 一样验证
+func (m *JWTMiddleware) OptionalAuthenticate(next http.HandlerFunc) http.HandlerFunc {
+	authenticated := m.Authenticate(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next(w, r)
+			return
+		}
+		authenticated(w, r)
+	}
+}
+
 // GetUserID 从请求上下文中获取用户ID
 func GetUserID(r *http.Request) string {
 	if userID, ok := r.Context().Value("user_id").(string); ok {
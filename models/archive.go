@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArchiveYear 归档中的一年
+type ArchiveYear struct {
+	Year   int             `json:"year"`
+	Count  int64           `json:"count"`  // 当年的文章数
+	Months []*ArchiveMonth `json:"months"` // 按月份倒序排列
+}
+
+// ArchiveMonth 归档中的一个月及当月的文章
+type ArchiveMonth struct {
+	Year  int            `bson:"year" json:"year"`
+	Month int            `bson:"month" json:"month"`
+	Count int64          `bson:"count" json:"count"`
+	Posts []ArchiveEntry `bson:"posts" json:"posts"` // 按发布时间倒序排列
+}
+
+// ArchiveEntry 归档中的一篇文章，只包含列表展示所需的字段
+type ArchiveEntry struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Slug        string             `bson:"slug" json:"slug"`
+	Title       string             `bson:"title" json:"title"`
+	PublishedAt time.Time          `bson:"published_at" json:"published_at"`
+}
@@ -8,13 +8,46 @@ import (
 
 // Blog 表示一篇博客文章
 type Blog struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`    // 博客文章的唯一标识符
-	Title     string             `bson:"title" json:"title"`                   // 博客文章的标题
-	Content   string             `bson:"content" json:"content"`               // 博客文章的内容
-	Author    string             `bson:"author" json:"author"`                 // 博客文章的作者
-	Tags      []string           `bson:"tags,omitempty" json:"tags,omitempty"` // 内标签数组
-	Views     int64              `bson:"views" json:"views"`                   // 浏览次数
-	Show      bool               `bson:"show" json:"show"`                     // 是否在前端展示
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`         // 博客文章的创建时间
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`         // 博客文章的更新时间
+	ID               primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`                    // 博客文章的唯一标识符
+	Title            string              `bson:"title" json:"title"`                                   // 博客文章的标题
+	Slug             string              `bson:"slug,omitempty" json:"slug,omitempty"`                 // URL 友好的唯一标识，由标题生成，可修改
+	OldSlugs         []string            `bson:"old_slugs,omitempty" json:"-"`                         // 曾经使用过的 slug，仍可解析并重定向到当前 slug
+	Content          string              `bson:"content" json:"content"`                               // 博客文章的内容
+	Author           string              `bson:"author" json:"author"`                                 // 博客文章的作者
+	Tags             []string            `bson:"tags,omitempty" json:"tags,omitempty"`                 // 内标签数组
+	TagKeys          []string            `bson:"tag_keys,omitempty" json:"-"`                          // 标签的匹配键（小写），与 Tags 一一对应
+	CategoryID       *primitive.ObjectID `bson:"category_id,omitempty" json:"category_id,omitempty"`   // 所属分类，为空表示未分类
+	SearchTerms      []string            `bson:"search_terms,omitempty" json:"-"`                      // 全文检索词（标题、标签与正文切分结果）
+	MediaRefs        []string            `bson:"media_refs,omitempty" json:"-"`                        // 正文引用的媒体文件哈希，删除媒体文件前据此检查引用
+	Source           string              `bson:"source,omitempty" json:"-"`                            // 从 Markdown 文件导入时的源文件路径（相对导入目录），重复导入时据此匹配
+	Views            int64               `bson:"views" json:"views"`                                   // 浏览次数
+	CommentCount     int64               `bson:"comment_count" json:"comment_count"`                   // 已通过审核的评论数
+	CommentsDisabled bool                `bson:"comments_disabled" json:"comments_disabled"`           // 是否关闭评论，关闭后不能发表新评论，已有评论仍然展示
+	Reactions        map[string]int64    `bson:"reactions,omitempty" json:"reactions,omitempty"`       // 各种表情回应的数量，键为 ReactionKind.Name
+	Show             bool                `bson:"show" json:"show"`                                     // 是否在前端展示
+	PublishAt        *time.Time          `bson:"publish_at,omitempty" json:"publish_at,omitempty"`     // 定时发布时间，为空表示创建后即发布
+	UnpublishAt      *time.Time          `bson:"unpublish_at,omitempty" json:"unpublish_at,omitempty"` // 定时下线时间，为空表示不下线
+	PublishedAt      time.Time           `bson:"published_at" json:"published_at"`                     // 生效的发布时间（publish_at，未设置时为 created_at），列表按此排序
+	Version          int64               `bson:"version" json:"version"`                               // 版本号，每次修改递增，用于乐观并发控制
+	CreatedAt        time.Time           `bson:"created_at" json:"created_at"`                         // 博客文章的创建时间
+	UpdatedAt        time.Time           `bson:"updated_at" json:"updated_at"`                         // 博客文章的更新时间
+	DeletedAt        *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`     // 移入回收站的时间，为空表示未删除
+
+	BlogMetadata `bson:",inline"` // 由正文计算的摘要、目录与字数等信息
+}
+
+// BlogMetadata 由正文计算并随文章保存的派生信息，列表接口默认用它代替完整正文
+type BlogMetadata struct {
+	Excerpt        string     `bson:"excerpt" json:"excerpt"`                 // 纯文本摘要，正文含 <!--more--> 时取其之前的部分
+	TOC            []TOCEntry `bson:"toc,omitempty" json:"toc,omitempty"`     // 按标题生成的目录
+	WordCount      int        `bson:"word_count" json:"word_count"`           // 字数：每个汉字计一个字，英文按单词计
+	CharCount      int        `bson:"char_count" json:"char_count"`           // 非空白字符数
+	ReadingMinutes int        `bson:"reading_minutes" json:"reading_minutes"` // 预计阅读时长（分钟）
+}
+
+// TOCEntry 目录中的一个标题
+type TOCEntry struct {
+	Level int    `bson:"level" json:"level"` // 标题级别，1-6
+	Text  string `bson:"text" json:"text"`   // 标题的纯文本
+	ID    string `bson:"id" json:"id"`       // 锚点，与渲染后 HTML 中标题的 id 一致
 }
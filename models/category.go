@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Category 文章分类，通过 ParentID 组成树（如 后端 → Go → 并发），每篇文章最多属于一个分类
+type Category struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Name      string              `bson:"name" json:"name"`                               // 分类名称
+	Slug      string              `bson:"slug" json:"slug"`                               // URL 友好的唯一标识
+	ParentID  *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"` // 父分类，为空表示顶级分类
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// CategoryNode 分类树中的一个节点及文章数
+type CategoryNode struct {
+	Category
+	PostCount  int64           `json:"post_count"`  // 直接属于该分类的文章数
+	TotalCount int64           `json:"total_count"` // 包含全部子孙分类的文章数
+	Children   []*CategoryNode `json:"children"`    // 子分类，按名称排序
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentStatus 评论的审核状态
+type CommentStatus string
+
+const (
+	CommentPending  CommentStatus = "pending"  // 待审核
+	CommentApproved CommentStatus = "approved" // 已通过，前端可见
+	CommentSpam     CommentStatus = "spam"     // 垃圾评论
+	CommentDeleted  CommentStatus = "deleted"  // 已删除
+)
+
+// Comment 读者对文章的评论，通过 ParentID 组成回复树
+type Comment struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	PostID      primitive.ObjectID  `bson:"post_id" json:"post_id"`                               // 所属文章
+	ParentID    *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`       // 回复的评论，为空表示直接评论文章
+	UserID      string              `bson:"user_id,omitempty" json:"user_id,omitempty"`           // 登录用户发表时的用户ID，匿名评论为空
+	AuthorName  string              `bson:"author_name" json:"author_name"`                       // 昵称，登录用户为用户名
+	AuthorEmail string              `bson:"author_email,omitempty" json:"author_email,omitempty"` // 邮箱，只在后台展示
+	AuthorURL   string              `bson:"author_url,omitempty" json:"author_url,omitempty"`     // 个人网站
+	Content     string              `bson:"content" json:"content"`                               // 原始内容（简化的 Markdown）
+	ContentHTML string              `bson:"content_html" json:"content_html"`                     // 渲染并清理后的 HTML
+	Status      CommentStatus       `bson:"status" json:"status"`
+	IP          string              `bson:"ip,omitempty" json:"ip,omitempty"`                 // 发表时的客户端 IP，只在后台展示
+	UserAgent   string              `bson:"user_agent,omitempty" json:"user_agent,omitempty"` // 发表时的 User-Agent，只在后台展示
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// CommentNode 回复树中的一条评论及其回复
+type CommentNode struct {
+	Comment
+	Replies []*CommentNode `json:"replies"` // 按发表时间正序排列
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Media 上传的媒体文件的元数据，文件本身按内容哈希保存在 MediaStore 中，相同内容只保存一份
+type Media struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Hash        string             `bson:"hash" json:"hash"`                             // 文件内容的 SHA-256（十六进制），唯一
+	Key         string             `bson:"key" json:"-"`                                 // 文件在 MediaStore 中的路径
+	ContentType string             `bson:"content_type" json:"content_type"`             // 由文件内容识别的 MIME 类型
+	Size        int64              `bson:"size" json:"size"`                             // 字节数
+	Width       int                `bson:"width" json:"width"`                           // 图片宽度（像素）
+	Height      int                `bson:"height" json:"height"`                         // 图片高度（像素）
+	Filename    string             `bson:"filename,omitempty" json:"filename,omitempty"` // 上传时的原始文件名
+	Uploader    string             `bson:"uploader" json:"uploader"`                     // 上传者用户名
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// MediaReference 正文引用了媒体文件的一篇文章，只包含展示引用关系所需的字段
+type MediaReference struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	Title     string             `bson:"title" json:"title"`
+	Slug      string             `bson:"slug" json:"slug"`
+	MediaRefs []string           `bson:"media_refs" json:"-"`
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"` // 文章在回收站中时不为空
+}
+
+// MediaItem 媒体文件及引用它的文章（包括回收站中的文章）
+type MediaItem struct {
+	Media
+	URL    string            `json:"url"`              // 访问路径，如 /media/<hash>
+	Srcset string            `json:"srcset,omitempty"` // 原图与各宽度缩略图组成的 srcset，没有更小的缩略图时为空
+	Posts  []*MediaReference `json:"posts"`            // 引用该文件的文章，不为空时不能删除
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReactionKind 一种可用的表情回应，Name 用于接口路径与计数，Emoji 供前端展示
+type ReactionKind struct {
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+}
+
+// Reaction 读者对文章的一次表情回应，同一读者对同一文章的同一种回应只记录一次
+type Reaction struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	PostID    primitive.ObjectID `bson:"post_id" json:"post_id"`
+	Kind      string             `bson:"kind" json:"kind"`
+	Actor     string             `bson:"actor" json:"-"` // 登录用户为 user:<用户ID>，匿名读者为客户端指纹的摘要
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Revision 博客文章某一时刻的内容快照，每次创建或修改文章时记录
+type Revision struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	BlogID        primitive.ObjectID `bson:"blog_id" json:"blog_id"`               // 所属文章
+	Number        int64              `bson:"number" json:"number"`                 // 文章内递增的版本号，从 1 开始
+	Title         string             `bson:"title" json:"title"`                   // 快照：标题
+	Content       string             `bson:"content" json:"content,omitempty"`     // 快照：正文
+	Tags          []string           `bson:"tags,omitempty" json:"tags,omitempty"` // 快照：标签
+	Show          bool               `bson:"show" json:"show"`                     // 快照：是否展示
+	Slug          string             `bson:"slug,omitempty" json:"slug,omitempty"` // 快照：slug
+	Editor        string             `bson:"editor" json:"editor"`                 // 进行本次修改的用户
+	ChangedFields []string           `bson:"changed_fields" json:"changed_fields"` // 本次修改涉及的字段
+	Note          string             `bson:"note,omitempty" json:"note,omitempty"` // 备注，例如回滚来源
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`         // 记录时间
+}
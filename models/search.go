@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchCandidate 参与搜索排序的一篇文章，只包含打分所需的字段，不含正文
+type SearchCandidate struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Title       string             `bson:"title"`
+	Tags        []string           `bson:"tags"`
+	SearchTerms []string           `bson:"search_terms"` // 文章检索词中与查询相同的部分
+	PublishedAt time.Time          `bson:"published_at"`
+}
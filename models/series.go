@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Series 系列文章（如多篇连载的教程），按 PostIDs 的顺序组织文章
+type Series struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	Title       string               `bson:"title" json:"title"`                                 // 系列标题
+	Slug        string               `bson:"slug" json:"slug"`                                   // URL 友好的唯一标识
+	Description string               `bson:"description,omitempty" json:"description,omitempty"` // 系列简介
+	PostIDs     []primitive.ObjectID `bson:"post_ids" json:"post_ids"`                           // 按阅读顺序排列的文章ID
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+}
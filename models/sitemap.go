@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SitemapPost 站点地图中的一篇文章，只包含生成链接与 lastmod 所需的字段
+type SitemapPost struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Slug        string             `bson:"slug"`
+	Tags        []string           `bson:"tags"`
+	PublishedAt time.Time          `bson:"published_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// TagCount 标签及使用该标签的文章数
+type TagCount struct {
+	Tag   string `bson:"tag" json:"tag"`     // 标签的展示名称
+	Count int64  `bson:"count" json:"count"` // 使用该标签的文章数
+}
@@ -7,14 +7,57 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// RegisterAdminRoutes 注册后台管理相关路由：需要鉴权的写操作与认证
-func RegisterAdminRoutes(r *mux.Router, blogHandler *handlers.BlogHandler, authHandler *handlers.AuthHandler, jwtMiddleware *middleware.JWTMiddleware) {
+// RegisterAdminRoutes 注册后台管理相关路由：需要鉴权的读写操作与认证
+func RegisterAdminRoutes(r *mux.Router, blogHandler *handlers.BlogHandler, seriesHandler *handlers.SeriesHandler, categoryHandler *handlers.CategoryHandler, commentHandler *handlers.CommentHandler, mediaHandler *handlers.MediaHandler, exportHandler *handlers.ExportHandler, authHandler *handlers.AuthHandler, jwtMiddleware *middleware.JWTMiddleware) {
 	// 认证端点（登录/注册）
 	r.HandleFunc("/api/admin/auth/register", authHandler.Register).Methods("POST")
 	r.HandleFunc("/api/admin/auth/login", authHandler.Login).Methods("POST")
 
+	// 博客管理端点（需要鉴权，包含未展示的草稿）
+	r.HandleFunc("/api/admin/blogs", jwtMiddleware.Authenticate(blogHandler.GetBlogs)).Methods("GET")
+	r.HandleFunc("/api/admin/blog/{id}", jwtMiddleware.Authenticate(blogHandler.GetAdminBlog)).Methods("GET")
+
 	// 博客管理端点（需要鉴权的写操作）
 	r.HandleFunc("/api/admin/blog", jwtMiddleware.Authenticate(blogHandler.CreateBlog)).Methods("POST")
 	r.HandleFunc("/api/admin/blog/{id}", jwtMiddleware.Authenticate(blogHandler.DeleteBlog)).Methods("DELETE")
 	r.HandleFunc("/api/admin/blog/{id}", jwtMiddleware.Authenticate(blogHandler.UpdateBlog)).Methods("PUT")
+
+	// 版本历史：列出、查看、比较与回滚文章版本
+	r.HandleFunc("/api/admin/blog/{id}/revisions", jwtMiddleware.Authenticate(blogHandler.GetRevisions)).Methods("GET")
+	r.HandleFunc("/api/admin/blog/{id}/revisions/diff", jwtMiddleware.Authenticate(blogHandler.DiffRevisions)).Methods("GET")
+	r.HandleFunc("/api/admin/blog/{id}/revisions/{rev:[0-9]+}", jwtMiddleware.Authenticate(blogHandler.GetRevision)).Methods("GET")
+	r.HandleFunc("/api/admin/blog/{id}/revisions/{rev:[0-9]+}/rollback", jwtMiddleware.Authenticate(blogHandler.RollbackBlog)).Methods("POST")
+
+	// 系列管理：创建、编辑、排序系列及增删其中的文章
+	r.HandleFunc("/api/admin/series", jwtMiddleware.Authenticate(seriesHandler.GetAdminSeriesList)).Methods("GET")
+	r.HandleFunc("/api/admin/series", jwtMiddleware.Authenticate(seriesHandler.CreateSeries)).Methods("POST")
+	r.HandleFunc("/api/admin/series/{id}", jwtMiddleware.Authenticate(seriesHandler.GetAdminSeries)).Methods("GET")
+	r.HandleFunc("/api/admin/series/{id}", jwtMiddleware.Authenticate(seriesHandler.UpdateSeries)).Methods("PUT")
+	r.HandleFunc("/api/admin/series/{id}", jwtMiddleware.Authenticate(seriesHandler.DeleteSeries)).Methods("DELETE")
+	r.HandleFunc("/api/admin/series/{id}/posts", jwtMiddleware.Authenticate(seriesHandler.AddSeriesPost)).Methods("POST")
+	r.HandleFunc("/api/admin/series/{id}/posts/{postID}", jwtMiddleware.Authenticate(seriesHandler.RemoveSeriesPost)).Methods("DELETE")
+
+	// 分类管理：创建、重命名、移动与删除分类
+	r.HandleFunc("/api/admin/categories", jwtMiddleware.Authenticate(categoryHandler.GetAdminCategories)).Methods("GET")
+	r.HandleFunc("/api/admin/categories", jwtMiddleware.Authenticate(categoryHandler.CreateCategory)).Methods("POST")
+	r.HandleFunc("/api/admin/categories/{id}", jwtMiddleware.Authenticate(categoryHandler.UpdateCategory)).Methods("PUT")
+	r.HandleFunc("/api/admin/categories/{id}", jwtMiddleware.Authenticate(categoryHandler.DeleteCategory)).Methods("DELETE")
+
+	// 评论审核：默认列出待审核的评论，可通过、标记为垃圾、修改或删除
+	r.HandleFunc("/api/admin/comments", jwtMiddleware.Authenticate(commentHandler.GetAdminComments)).Methods("GET")
+	r.HandleFunc("/api/admin/comments/{id}", jwtMiddleware.Authenticate(commentHandler.UpdateComment)).Methods("PUT")
+	r.HandleFunc("/api/admin/comments/{id}", jwtMiddleware.Authenticate(commentHandler.DeleteComment)).Methods("DELETE")
+
+	// 媒体文件：上传图片，列出并删除未被文章引用的文件
+	r.HandleFunc("/api/admin/media", jwtMiddleware.Authenticate(mediaHandler.GetMedia)).Methods("GET")
+	r.HandleFunc("/api/admin/media", jwtMiddleware.Authenticate(mediaHandler.UploadMedia)).Methods("POST")
+	r.HandleFunc("/api/admin/media/{id}", jwtMiddleware.Authenticate(mediaHandler.DeleteMedia)).Methods("DELETE")
+
+	// 回收站：删除的文章先进入回收站，可恢复或永久删除
+	r.HandleFunc("/api/admin/trash", jwtMiddleware.Authenticate(blogHandler.GetTrash)).Methods("GET")
+	r.HandleFunc("/api/admin/trash/{id}/restore", jwtMiddleware.Authenticate(blogHandler.RestoreBlog)).Methods("POST")
+	r.HandleFunc("/api/admin/trash/{id}", jwtMiddleware.Authenticate(blogHandler.PurgeBlog)).Methods("DELETE")
+
+	// 数据导出：下载全部文章（Markdown）与其他数据（JSON）的归档文件
+	r.HandleFunc("/api/admin/export", jwtMiddleware.Authenticate(exportHandler.Export)).Methods("GET")
 }
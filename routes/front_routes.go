@@ -2,11 +2,18 @@ package routes
 
 import (
 	"blog/handlers"
+	"blog/middleware"
 
 	"github.com/gorilla/mux"
 )
 
 // RegisterFrontRoutes 注册前端路由：用户相关公开操作
-func RegisterFrontRoutes(r *mux.Router, authHandler *handlers.AuthHandler) {
+func RegisterFrontRoutes(r *mux.Router, commentHandler *handlers.CommentHandler, reactionHandler *handlers.ReactionHandler, authHandler *handlers.AuthHandler, jwtMiddleware *middleware.JWTMiddleware) {
+	// 发表评论或回复：匿名访客填写昵称，携带认证令牌时以登录用户身份发表
+	r.HandleFunc("/api/blog/{id}/comments", jwtMiddleware.OptionalAuthenticate(commentHandler.PostComment)).Methods("POST")
 
+	// 表情回应：登录用户按用户去重，匿名读者按客户端指纹去重
+	r.HandleFunc("/api/blog/{id}/reactions", jwtMiddleware.OptionalAuthenticate(reactionHandler.GetReactions)).Methods("GET")
+	r.HandleFunc("/api/blog/{id}/reactions/{kind}", jwtMiddleware.OptionalAuthenticate(reactionHandler.AddReaction)).Methods("POST")
+	r.HandleFunc("/api/blog/{id}/reactions/{kind}", jwtMiddleware.OptionalAuthenticate(reactionHandler.RemoveReaction)).Methods("DELETE")
 }
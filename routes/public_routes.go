@@ -7,9 +7,55 @@ import (
 )
 
 // RegisterPublicRoutes 注册前端可访问的公开路由：内容读取
-func RegisterPublicRoutes(r *mux.Router, blogHandler *handlers.BlogHandler, _ *handlers.AuthHandler) {
+func RegisterPublicRoutes(r *mux.Router, blogHandler *handlers.BlogHandler, seriesHandler *handlers.SeriesHandler, categoryHandler *handlers.CategoryHandler, archiveHandler *handlers.ArchiveHandler, feedHandler *handlers.FeedHandler, sitemapHandler *handlers.SitemapHandler, commentHandler *handlers.CommentHandler, reactionHandler *handlers.ReactionHandler, mediaHandler *handlers.MediaHandler, _ *handlers.AuthHandler) {
 	// 获取单篇博客（公开访问）
 	r.HandleFunc("/api/blog/{id}", blogHandler.GetBlog).Methods("GET")
+	// 根据 slug 获取单篇博客，历史 slug 仍可访问（公开访问）
+	r.HandleFunc("/api/blog/by-slug/{slug}", blogHandler.GetBlogBySlug).Methods("GET")
+	// 获取与文章相似的博客（公开访问）
+	r.HandleFunc("/api/blog/{id}/related", blogHandler.GetRelatedBlogs).Methods("GET")
+	// 获取文章的评论树（公开访问）
+	r.HandleFunc("/api/blog/{id}/comments", commentHandler.GetComments).Methods("GET")
 	// 分页获取博客列表（公开访问）
 	r.HandleFunc("/api/blogs", blogHandler.GetBlogsPaginated).Methods("GET")
+
+	// 标签列表及文章数（公开访问）
+	r.HandleFunc("/api/tags", blogHandler.GetTags).Methods("GET")
+	// 分页获取指定标签下的博客（公开访问）
+	r.HandleFunc("/api/tags/{tag}/blogs", blogHandler.GetTagBlogs).Methods("GET")
+
+	// 分类树及文章数（公开访问）
+	r.HandleFunc("/api/categories", categoryHandler.GetCategories).Methods("GET")
+	// 分页获取分类及其子孙分类下的博客（公开访问）
+	r.HandleFunc("/api/categories/{slug}/blogs", categoryHandler.GetCategoryBlogs).Methods("GET")
+
+	// 按年月分组的文章归档（公开访问）
+	r.HandleFunc("/api/archive", archiveHandler.GetArchive).Methods("GET")
+	r.HandleFunc("/api/archive/{year:[0-9]{1,4}}", archiveHandler.GetArchiveYear).Methods("GET")
+	r.HandleFunc("/api/archive/{year:[0-9]{1,4}}/{month:[0-9]{1,2}}", archiveHandler.GetArchiveMonth).Methods("GET")
+
+	// 可用的表情回应种类（公开访问）
+	r.HandleFunc("/api/reactions", reactionHandler.GetReactionKinds).Methods("GET")
+
+	// 全文检索（公开访问）
+	r.HandleFunc("/api/search", blogHandler.Search).Methods("GET")
+
+	// 系列列表与系列详情，只包含前端可见的文章（公开访问）
+	r.HandleFunc("/api/series", seriesHandler.GetSeriesList).Methods("GET")
+	r.HandleFunc("/api/series/{slug}", seriesHandler.GetSeriesBySlug).Methods("GET")
+
+	// 全站、按标签、按作者的 RSS / Atom / JSON Feed 订阅源（公开访问）
+	for _, prefix := range []string{"", "/tags/{tag}", "/authors/{author}"} {
+		r.HandleFunc(prefix+"/feed.xml", feedHandler.GetRSS).Methods("GET", "HEAD")
+		r.HandleFunc(prefix+"/atom.xml", feedHandler.GetAtom).Methods("GET", "HEAD")
+		r.HandleFunc(prefix+"/feed.json", feedHandler.GetJSONFeed).Methods("GET", "HEAD")
+	}
+
+	// 站点地图（链接过多时为索引及分片）与 robots.txt（公开访问）
+	r.HandleFunc("/sitemap.xml", sitemapHandler.GetSitemap).Methods("GET", "HEAD")
+	r.HandleFunc("/sitemap-{n:[0-9]+}.xml", sitemapHandler.GetSitemapPart).Methods("GET", "HEAD")
+	r.HandleFunc("/robots.txt", sitemapHandler.GetRobots).Methods("GET", "HEAD")
+
+	// 上传的媒体文件，按内容哈希访问（公开访问）
+	r.HandleFunc("/media/{hash:[0-9a-f]{64}}", mediaHandler.ServeMedia).Methods("GET", "HEAD")
 }
@@ -8,8 +8,8 @@ import (
 )
 
 // RegisterRoutes 聚合调用前端(public)与后台(admin)路由注册，保持向后兼容
-func RegisterRoutes(r *mux.Router, blogHandler *handlers.BlogHandler, authHandler *handlers.AuthHandler, jwtMiddleware *middleware.JWTMiddleware) {
-	RegisterPublicRoutes(r, blogHandler, authHandler)
-	RegisterFrontRoutes(r, authHandler)
-	RegisterAdminRoutes(r, blogHandler, authHandler, jwtMiddleware)
+func RegisterRoutes(r *mux.Router, blogHandler *handlers.BlogHandler, seriesHandler *handlers.SeriesHandler, categoryHandler *handlers.CategoryHandler, archiveHandler *handlers.ArchiveHandler, feedHandler *handlers.FeedHandler, sitemapHandler *handlers.SitemapHandler, commentHandler *handlers.CommentHandler, reactionHandler *handlers.ReactionHandler, mediaHandler *handlers.MediaHandler, exportHandler *handlers.ExportHandler, authHandler *handlers.AuthHandler, jwtMiddleware *middleware.JWTMiddleware) {
+	RegisterPublicRoutes(r, blogHandler, seriesHandler, categoryHandler, archiveHandler, feedHandler, sitemapHandler, commentHandler, reactionHandler, mediaHandler, authHandler)
+	RegisterFrontRoutes(r, commentHandler, reactionHandler, authHandler, jwtMiddleware)
+	RegisterAdminRoutes(r, blogHandler, seriesHandler, categoryHandler, commentHandler, mediaHandler, exportHandler, authHandler, jwtMiddleware)
 }
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"blog/models"
+)
+
+// ErrInvalidArchiveDate 归档的年份或月份超出范围
+var ErrInvalidArchiveDate = errors.New("无效的归档日期：年份须在 1-9999 之间，月份须在 1-12 之间")
+
+// ArchiveService 按年月归档前端可见的文章
+type ArchiveService struct {
+	blogService *BlogService
+	location    *time.Location
+}
+
+// NewArchiveService 创建新的ArchiveService实例，location 为划分年月所用的时区
+func NewArchiveService(blogService *BlogService, location *time.Location) *ArchiveService {
+	return &ArchiveService{
+		blogService: blogService,
+		location:    location,
+	}
+}
+
+// VisibleArchive 获取全部前端可见文章的归档，按年份倒序排列
+func (s *ArchiveService) VisibleArchive() ([]*models.ArchiveYear, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	months, err := s.blogService.visibleArchive(ctx, time.Time{}, time.Time{}, s.location)
+	if err != nil {
+		return nil, err
+	}
+	return groupArchiveYears(months), nil
+}
+
+// VisibleArchiveYear 获取某一年前端可见文章的归档，当年没有文章时 Count 为 0
+func (s *ArchiveService) VisibleArchiveYear(year int) (*models.ArchiveYear, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if year < 1 || year > 9999 {
+		return nil, ErrInvalidArchiveDate
+	}
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, s.location)
+	months, err := s.blogService.visibleArchive(ctx, from, from.AddDate(1, 0, 0).Add(-time.Nanosecond), s.location)
+	if err != nil {
+		return nil, err
+	}
+	if years := groupArchiveYears(months); len(years) > 0 {
+		return years[0], nil
+	}
+	return &models.ArchiveYear{Year: year, Months: []*models.ArchiveMonth{}}, nil
+}
+
+// VisibleArchiveMonth 获取某年某月前端可见文章的归档，当月没有文章时 Count 为 0
+func (s *ArchiveService) VisibleArchiveMonth(year, month int) (*models.ArchiveMonth, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if year < 1 || year > 9999 || month < 1 || month > 12 {
+		return nil, ErrInvalidArchiveDate
+	}
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.location)
+	months, err := s.blogService.visibleArchive(ctx, from, from.AddDate(0, 1, 0).Add(-time.Nanosecond), s.location)
+	if err != nil {
+		return nil, err
+	}
+	if len(months) > 0 {
+		return months[0], nil
+	}
+	return &models.ArchiveMonth{Year: year, Month: month, Posts: []models.ArchiveEntry{}}, nil
+}
+
+// groupArchiveYears 将按年月倒序排列的月份归入年份
+func groupArchiveYears(months []*models.ArchiveMonth) []*models.ArchiveYear {
+	years := []*models.ArchiveYear{}
+	for _, month := range months {
+		if len(years) == 0 || years[len(years)-1].Year != month.Year {
+			years = append(years, &models.ArchiveYear{Year: month.Year, Months: []*models.ArchiveMonth{}})
+		}
+		year := years[len(years)-1]
+		year.Count += month.Count
+		year.Months = append(year.Months, month)
+	}
+	return years
+}
+
+// visibleArchive 按年月分组发布时间在 [from, to] 内的前端可见文章，零值端点表示不限制
+func (s *BlogService) visibleArchive(ctx context.Context, from, to time.Time, loc *time.Location) ([]*models.ArchiveMonth, error) {
+	visible := true
+	return s.store.ArchiveBlogs(ctx, BlogQuery{
+		Show:          &visible,
+		VisibleAt:     time.Now(),
+		PublishedFrom: from,
+		PublishedTo:   to,
+	}, loc)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/models"
+)
+
+func TestVisibleArchive(t *testing.T) {
+	blogService := newTestBlogService()
+	cst := time.FixedZone("CST", 8*3600)
+	archiveService := NewArchiveService(blogService, cst)
+
+	for _, p := range []struct {
+		title string
+		at    time.Time
+		show  bool
+	}{
+		// UTC 的 2023 年最后一天，在 CST 时区已是 2024 年 1 月
+		{"跨年", time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), true},
+		{"一月", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), true},
+		{"三月", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), true},
+		{"六月", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), true},
+		{"草稿", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"定时发布", time.Now().Add(time.Hour), true},
+	} {
+		at := p.at
+		if _, err := blogService.CreateBlog(CreateBlogInput{Title: p.title, Content: "正文", Author: "alice", Show: p.show, PublishAt: &at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	month := func(m *models.ArchiveMonth) string {
+		titles := make([]string, len(m.Posts))
+		for i, post := range m.Posts {
+			titles[i] = post.Title
+		}
+		return fmt.Sprintf("%d-%02d=%d[%s]", m.Year, m.Month, m.Count, strings.Join(titles, ","))
+	}
+
+	years, err := archiveService.VisibleArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, year := range years {
+		got = append(got, fmt.Sprintf("%d=%d", year.Year, year.Count))
+		for _, m := range year.Months {
+			got = append(got, month(m))
+		}
+	}
+	// 年月倒序，月内文章按发布时间倒序；草稿与未到发布时间的文章不计入
+	want := []string{"2024=3", "2024-03=1[三月]", "2024-01=2[一月,跨年]", "2023=1", "2023-06=1[六月]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("VisibleArchive = %q, want %q", got, want)
+	}
+
+	yearTests := []struct {
+		year   int
+		count  int64
+		months int
+		err    error
+	}{
+		{2024, 3, 2, nil},
+		{2023, 1, 1, nil},
+		{2022, 0, 0, nil},
+		{0, 0, 0, ErrInvalidArchiveDate},
+		{10000, 0, 0, ErrInvalidArchiveDate},
+	}
+	for _, tt := range yearTests {
+		year, err := archiveService.VisibleArchiveYear(tt.year)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("VisibleArchiveYear(%d) err = %v, want %v", tt.year, err, tt.err)
+			continue
+		}
+		if err == nil && (year.Year != tt.year || year.Count != tt.count || len(year.Months) != tt.months) {
+			t.Errorf("VisibleArchiveYear(%d) = %d 篇 %d 个月, want %d 篇 %d 个月", tt.year, year.Count, len(year.Months), tt.count, tt.months)
+		}
+	}
+
+	monthTests := []struct {
+		year, month int
+		want        string
+		err         error
+	}{
+		{2024, 1, "2024-01=2[一月,跨年]", nil},
+		{2023, 12, "2023-12=0[]", nil},
+		{2024, 13, "", ErrInvalidArchiveDate},
+		{2024, 0, "", ErrInvalidArchiveDate},
+	}
+	for _, tt := range monthTests {
+		m, err := archiveService.VisibleArchiveMonth(tt.year, tt.month)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("VisibleArchiveMonth(%d, %d) err = %v, want %v", tt.year, tt.month, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := month(m); got != tt.want || m.Posts == nil {
+			t.Errorf("VisibleArchiveMonth(%d, %d) = %s, want %s", tt.year, tt.month, got, tt.want)
+		}
+	}
+}
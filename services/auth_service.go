@@ -8,24 +8,21 @@ import (
 	"blog/models"
 
 	"github.com/golang-jwt/jwt/v5"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthService 处理用户认证的业务逻辑
 type AuthService struct {
-	collection *mongo.Collection
-	jwtSecret  []byte
+	store     UserStore
+	jwtSecret []byte
 }
 
 // NewAuthService 创建新的AuthService实例
-func NewAuthService(client *mongo.Client, dbName, collectionName string, jwtSecret string) *AuthService {
-	collection := client.Database(dbName).Collection(collectionName)
+func NewAuthService(store UserStore, jwtSecret string) *AuthService {
 	return &AuthService{
-		collection: collection,
-		jwtSecret:  []byte(jwtSecret),
+		store:     store,
+		jwtSecret: []byte(jwtSecret),
 	}
 }
 
@@ -35,14 +32,13 @@ func (s *AuthService) Register(username, password, email string) (*models.User,
 	defer cancel()
 
 	// 检查用户名是否已存在
-	var existingUser models.User
-	err := s.collection.FindOne(ctx, bson.M{"username": username}).Decode(&existingUser)
+	_, err := s.store.GetUserByUsername(ctx, username)
 	if err == nil {
 		return nil, errors.New("用户名已存在")
 	}
 
 	// 检查邮箱是否已存在
-	err = s.collection.FindOne(ctx, bson.M{"email": email}).Decode(&existingUser)
+	_, err = s.store.GetUserByEmail(ctx, email)
 	if err == nil {
 		return nil, errors.New("邮箱已存在")
 	}
@@ -61,11 +57,9 @@ func (s *AuthService) Register(username, password, email string) (*models.User,
 		UpdatedAt: time.Now(),
 	}
 
-	result, err := s.collection.InsertOne(ctx, user)
-	if err != nil {
+	if err := s.store.CreateUser(ctx, user); err != nil {
 		return nil, err
 	}
-	user.ID = result.InsertedID.(primitive.ObjectID)
 
 	return user, nil
 }
@@ -75,8 +69,7 @@ func (s *AuthService) Login(username, password string) (*models.AuthResponse, er
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var user models.User
-	err := s.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	user, err := s.store.GetUserByUsername(ctx, username)
 	if err != nil {
 		return nil, errors.New("用户名或密码错误")
 	}
@@ -95,7 +88,7 @@ func (s *AuthService) Login(username, password string) (*models.AuthResponse, er
 
 	return &models.AuthResponse{
 		Token: token,
-		User:  user,
+		User:  *user,
 	}, nil
 }
 
@@ -129,12 +122,7 @@ func (s *AuthService) GetUserByID(id string) (*models.User, error) {
 		return nil, err
 	}
 
-	var user models.User
-	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return s.store.GetUserByID(ctx, objID)
 }
 
 // generateToken 生成 JWT token
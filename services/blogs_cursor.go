@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor 分页游标无法解析
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// BlogCursor 基于 (published_at, _id) 的分页游标
+// Before 为 false 时获取游标之后（更早）的文章，为 true 时获取游标之前（更新）的文章
+type BlogCursor struct {
+	PublishedAt time.Time
+	ID          primitive.ObjectID
+	Before      bool
+}
+
+// cursorPayload 游标序列化后的结构，对客户端不透明
+type cursorPayload struct {
+	T string `json:"t"`
+	I string `json:"i"`
+	B bool   `json:"b,omitempty"`
+}
+
+// newBlogCursor 以文章在排序中的位置生成游标
+func newBlogCursor(blog *models.Blog, before bool) *BlogCursor {
+	return &BlogCursor{PublishedAt: blog.PublishedAt, ID: blog.ID, Before: before}
+}
+
+// String 将游标编码为 URL 安全的字符串
+func (c *BlogCursor) String() string {
+	data, _ := json.Marshal(cursorPayload{
+		T: c.PublishedAt.UTC().Format(time.RFC3339Nano),
+		I: c.ID.Hex(),
+		B: c.Before,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseBlogCursor 解析客户端传回的游标字符串
+func ParseBlogCursor(s string) (*BlogCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, p.T)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(p.I)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &BlogCursor{PublishedAt: t, ID: id, Before: p.B}, nil
+}
+
+// blogAfterCursor 判断文章在倒序排列中是否位于游标之后（更早）
+func blogAfterCursor(blog *models.Blog, c *BlogCursor) bool {
+	if !blog.PublishedAt.Equal(c.PublishedAt) {
+		return blog.PublishedAt.Before(c.PublishedAt)
+	}
+	return blog.ID.Hex() < c.ID.Hex()
+}
+
+// blogBeforeCursor 判断文章在倒序排列中是否位于游标之前（更新）
+func blogBeforeCursor(blog *models.Blog, c *BlogCursor) bool {
+	if !blog.PublishedAt.Equal(c.PublishedAt) {
+		return blog.PublishedAt.After(c.PublishedAt)
+	}
+	return blog.ID.Hex() > c.ID.Hex()
+}
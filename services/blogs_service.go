@@ -2,26 +2,58 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"blog/models"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // BlogService 处理博客文章的业务逻辑
 type BlogService struct {
-	collection *mongo.Collection
+	store   BlogStore
+	counts  *countCache
+	renders *renderCache
+	related *relatedIndex
+
+	revisions     RevisionStore
+	revisionLimit int64 // 每篇文章保留的版本数，<= 0 表示不限制
+
+	categories CategoryStore // 用于校验文章引用的分类
+	comments   CommentStore  // 文章被永久删除时一并删除其评论
+	reactions  ReactionStore // 文章被永久删除时一并删除其表情回应
+	series     SeriesStore   // 文章被永久删除时从所有系列中移除
+
+	scheduleChanged chan struct{} // 定时发布/下线时间变化时通知 PublishScheduler 重新计算
 }
 
-// NewBlogService 创建新的BlogService实例
-func NewBlogService(client *mongo.Client, dbName, collectionName string) *BlogService {
-	collection := client.Database(dbName).Collection(collectionName)
+// BlogPage 一页博客文章及翻页信息
+type BlogPage struct {
+	Blogs      []*models.Blog
+	Total      *int64 // 仅在请求总数时设置
+	NextCursor string // 下一页（更早文章）游标，没有更多时为空
+	PrevCursor string // 上一页（更新文章）游标，位于开头时为空
+}
+
+// NewBlogService 创建新的BlogService实例，revisionLimit 为每篇文章保留的版本数，<= 0 表示不限制
+func NewBlogService(store BlogStore, revisions RevisionStore, categories CategoryStore, comments CommentStore, reactions ReactionStore, series SeriesStore, revisionLimit int64) *BlogService {
 	return &BlogService{
-		collection: collection,
+		store:         store,
+		counts:        newCountCache(countCacheTTL, countCacheSize),
+		renders:       newRenderCache(),
+		related:       newRelatedIndex(),
+		revisions:     revisions,
+		revisionLimit: revisionLimit,
+		categories:    categories,
+		comments:      comments,
+		reactions:     reactions,
+		series:        series,
+
+		scheduleChanged: make(chan struct{}, 1),
 	}
 }
 
@@ -30,157 +62,705 @@ func (s *BlogService) GetAllBlogs() ([]*models.Blog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := s.collection.Find(ctx, bson.M{})
+	return s.store.ListBlogs(ctx, BlogQuery{})
+}
+
+// ListVisibleBlogs 分页获取前端可见（已展示且处于发布窗口内）的博客文章
+func (s *BlogService) ListVisibleBlogs(query BlogQuery, withTotal bool) (*BlogPage, error) {
+	visible := true
+	query.Show = &visible
+	query.VisibleAt = time.Now()
+	return s.ListBlogs(query, withTotal)
+}
+
+// ListBlogs 按条件分页获取博客文章，包含未展示的草稿，供后台管理使用
+// 支持页码与游标两种分页方式，withTotal 为 true 时附带（缓存的）总数
+func (s *BlogService) ListBlogs(query BlogQuery, withTotal bool) (*BlogPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 多取一条用于判断是否还有更多
+	limit := query.Limit
+	fetch := query
+	if limit > 0 {
+		fetch.Limit = limit + 1
+		if query.Cursor == nil && query.Page > 1 {
+			fetch.Offset = (query.Page - 1) * limit
+		}
+	}
+	blogs, err := s.store.ListBlogs(ctx, fetch)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var blogs []*models.Blog
-	if err = cursor.All(ctx, &blogs); err != nil {
-		return nil, err
+	cursor := query.Cursor
+	backward := cursor != nil && cursor.Before
+	hasMore := limit > 0 && int64(len(blogs)) > limit
+	if hasMore {
+		// 向前翻页时多出的一条是最新的文章，位于开头
+		if backward {
+			blogs = blogs[1:]
+		} else {
+			blogs = blogs[:limit]
+		}
+	}
+
+	page := &BlogPage{Blogs: blogs}
+	if len(blogs) > 0 {
+		first, last := blogs[0], blogs[len(blogs)-1]
+		if backward {
+			page.NextCursor = newBlogCursor(last, false).String()
+			if hasMore {
+				page.PrevCursor = newBlogCursor(first, true).String()
+			}
+		} else {
+			if hasMore {
+				page.NextCursor = newBlogCursor(last, false).String()
+			}
+			if cursor != nil || query.Page > 1 {
+				page.PrevCursor = newBlogCursor(first, true).String()
+			}
+		}
+	} else if cursor != nil {
+		// 翻过边界得到空页时，仍可从原游标位置往回翻
+		reverse := *cursor
+		reverse.Before = !cursor.Before
+		if backward {
+			page.NextCursor = reverse.String()
+		} else {
+			page.PrevCursor = reverse.String()
+		}
 	}
-	return blogs, nil
+
+	if withTotal {
+		total, err := s.countBlogs(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+	return page, nil
 }
 
-// GetBlogsWithPagination 分页获取博客文章
-func (s *BlogService) GetBlogsWithPagination(page, limit int64) ([]*models.Blog, int64, error) {
+// countBlogs 统计符合条件的文章总数，优先使用缓存
+func (s *BlogService) countBlogs(ctx context.Context, query BlogQuery) (int64, error) {
+	key := countKey(query)
+	if total, ok := s.counts.get(key); ok {
+		return total, nil
+	}
+	total, err := s.store.CountBlogs(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	s.counts.set(key, total)
+	return total, nil
+}
+
+// ListVisibleTags 获取前端可见文章使用的全部标签及文章数
+func (s *BlogService) ListVisibleTags() ([]*models.TagCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	visible := true
+	return s.store.CountTags(ctx, BlogQuery{Show: &visible, VisibleAt: time.Now()})
+}
+
+// SearchVisibleBlogs 在前端可见的文章中全文检索标题、正文与标签，按相关度分页返回
+// 排序只读取标题、标签与检索词，最多对最新的 maxSearchCandidates 篇候选文章排序，只为当前页读取完整文章
+func (s *BlogService) SearchVisibleBlogs(q string, page, limit int64) (*SearchPage, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 计算跳过的文档数
-	skip := (page - 1) * limit
+	terms := uniqueTokens(tokenize(q))
+	if len(terms) == 0 {
+		return &SearchPage{Hits: []*SearchHit{}}, nil
+	}
 
-	// 获取总数
-	total, err := s.collection.CountDocuments(ctx, bson.M{})
+	visible, now := true, time.Now()
+	candidates, err := s.store.ListSearchCandidates(ctx, BlogQuery{Show: &visible, VisibleAt: now, SearchTerms: terms, Limit: maxSearchCandidates})
+	if err != nil {
+		return nil, err
+	}
+	totalDocs, err := s.countBlogs(ctx, BlogQuery{Show: &visible, VisibleAt: now})
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	// 分页查询，按创建时间倒序
-	cursor, err := s.collection.Find(ctx, bson.M{}, &options.FindOptions{
-		Skip:  &skip,
-		Limit: &limit,
-		Sort:  bson.M{"created_at": -1},
-	})
+	ranked := rankBlogs(candidates, terms, totalDocs)
+	result := &SearchPage{Hits: []*SearchHit{}, Total: int64(len(ranked))}
+	start := min((page-1)*limit, int64(len(ranked)))
+	end := min(start+limit, int64(len(ranked)))
+	ranked = ranked[start:end]
+	if len(ranked) == 0 {
+		return result, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(ranked))
+	for i, c := range ranked {
+		ids[i] = c.ID
+	}
+	blogs, err := s.store.ListBlogs(ctx, BlogQuery{IDs: ids})
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*models.Blog, len(blogs))
+	for _, blog := range blogs {
+		byID[blog.ID] = blog
+	}
+	for _, c := range ranked {
+		// 排序之后被删除的文章不再返回
+		blog, ok := byID[c.ID]
+		if !ok {
+			continue
+		}
+		result.Hits = append(result.Hits, &SearchHit{
+			Blog:    blog,
+			Score:   c.score,
+			Title:   highlightTitle(blog.Title, terms),
+			Snippet: buildSnippet(blog.Content, terms),
+		})
 	}
-	defer cursor.Close(ctx)
+	return result, nil
+}
 
-	var blogs []*models.Blog
-	if err = cursor.All(ctx, &blogs); err != nil {
-		return nil, 0, err
+// BackfillDerivedFields 为旧文章补齐检索词、slug、摘要与媒体文件引用等派生字段，不修改更新时间
+func (s *BlogService) BackfillDerivedFields() (int, error) {
+	blogs, err := s.GetAllBlogs()
+	if err != nil {
+		return 0, err
 	}
-	return blogs, total, nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	n := 0
+	for _, blog := range blogs {
+		var update BlogUpdate
+		changed := false
+		// 切分规则调整后检索词随之更新
+		if terms := blogSearchTerms(blog); !slices.Equal(terms, blog.SearchTerms) {
+			update.SearchTerms = terms
+			changed = true
+		}
+		// 摘要标记的识别规则调整后摘要随之更新
+		if meta := blogMetadata(blog.Content); meta.Excerpt != blog.Excerpt ||
+			(blog.ReadingMinutes == 0 && strings.TrimSpace(blog.Content) != "") {
+			update.Metadata = &meta
+			changed = true
+		}
+		if blog.MediaRefs == nil {
+			if refs := contentMediaRefs(blog.Content); len(refs) > 0 {
+				update.MediaRefs = refs
+				changed = true
+			}
+		}
+		if blog.Slug == "" {
+			slug, err := s.uniqueSlug(ctx, Slugify(blog.Title), blog.ID)
+			if err != nil {
+				return n, err
+			}
+			update.Slug = &slug
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if _, err := s.store.UpdateBlog(ctx, blog.ID, update); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
 }
 
-// GetBlogByID 根据ID获取单篇博客文章
+// GetBlogByID 根据ID获取单篇博客文章，包含未展示的草稿
 func (s *BlogService) GetBlogByID(id string) (*models.Blog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objID, err := primitive.ObjectIDFromHex(id)
+	objID, err := parseBlogID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	var blog models.Blog
-	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&blog)
+	blog, err := s.store.GetBlogByID(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+	if blog.DeletedAt != nil {
+		return nil, ErrBlogNotFound
+	}
+	return blog, nil
+}
+
+// GetVisibleBlogByID 根据ID获取前端可见的博客文章，未展示或不在发布窗口内的文章视为不存在
+func (s *BlogService) GetVisibleBlogByID(id string) (*models.Blog, error) {
+	blog, err := s.GetBlogByID(id)
 	if err != nil {
 		return nil, err
 	}
-	return &blog, nil
+	if !blog.Show || !blogInPublishWindow(blog, time.Now()) {
+		return nil, ErrBlogNotFound
+	}
+	return blog, nil
+}
+
+// GetVisibleBlogBySlug 根据 slug 获取前端可见的博客文章
+// 通过历史 slug 访问时 moved 为 true，调用方应引导客户端跳转到 blog.Slug
+func (s *BlogService) GetVisibleBlogBySlug(slug string) (blog *models.Blog, moved bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blog, err = s.store.GetBlogBySlug(ctx, slug)
+	if err != nil {
+		return nil, false, err
+	}
+	if !blog.Show || blog.DeletedAt != nil || !blogInPublishWindow(blog, time.Now()) {
+		return nil, false, ErrBlogNotFound
+	}
+	return blog, blog.Slug != slug, nil
+}
+
+// RenderContentHTML 将文章正文（Markdown）渲染为经过清理的 HTML，结果按文章版本缓存
+func (s *BlogService) RenderContentHTML(blog *models.Blog) string {
+	return s.renders.render(blog)
+}
+
+// SetImageSrcset 设置为正文中的图片生成 srcset 的函数，需在开始处理请求前调用
+func (s *BlogService) SetImageSrcset(srcset SrcsetFunc) {
+	s.renders.srcset = srcset
+}
+
+// CreateBlogInput 创建博客文章的参数
+type CreateBlogInput struct {
+	Title   string
+	Content string
+	Author  string
+	Tags    []string
+	Show    bool
+	Slug    string // 为空时根据标题生成，冲突时自动追加序号
+
+	CategoryID       string // 所属分类ID，为空表示未分类
+	CommentsDisabled bool   // 是否关闭评论
+
+	PublishAt   *time.Time // 定时发布时间，nil 表示创建后即发布
+	UnpublishAt *time.Time // 定时下线时间，nil 表示不下线
+
+	// 以下字段用于导入已有文章
+	ID        primitive.ObjectID // 文章ID，零值表示生成新ID
+	CreatedAt time.Time          // 创建时间，零值表示当前时间
+	UpdatedAt time.Time          // 更新时间，零值表示与创建时间相同
+	Views     int64              // 浏览次数
+	Source    string             // 源文件路径
 }
 
 // CreateBlog 创建新博客文章
-func (s *BlogService) CreateBlog(title, content, author string, tags []string, show bool) (*models.Blog, error) {
+func (s *BlogService) CreateBlog(input CreateBlogInput) (*models.Blog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	blog := &models.Blog{
-		ID:        primitive.NewObjectID(),
-		Title:     title,
-		Content:   content,
-		Author:    author,
-		Tags:      tags,
-		Views:     0,
-		Show:      show,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	id := input.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	slug := input.Slug
+	if slug == "" {
+		var err error
+		if slug, err = s.uniqueSlug(ctx, Slugify(input.Title), id); err != nil {
+			return nil, err
+		}
+	} else if err := s.checkSlug(ctx, slug, id); err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now()
+	if !input.CreatedAt.IsZero() {
+		createdAt = input.CreatedAt
+	}
+	updatedAt := createdAt
+	if !input.UpdatedAt.IsZero() {
+		updatedAt = input.UpdatedAt
+	}
+	publishedAt := createdAt
+	if input.PublishAt != nil {
+		publishedAt = *input.PublishAt
+	}
+	if input.UnpublishAt != nil && !input.UnpublishAt.After(publishedAt) {
+		return nil, ErrInvalidSchedule
 	}
 
-	_, err := s.collection.InsertOne(ctx, blog)
+	categoryID, err := s.categoryRef(ctx, input.CategoryID)
 	if err != nil {
 		return nil, err
 	}
+
+	tags := NormalizeTags(input.Tags)
+	blog := &models.Blog{
+		ID:          id,
+		Title:       input.Title,
+		Slug:        slug,
+		Content:     input.Content,
+		Author:      input.Author,
+		Tags:        tags,
+		TagKeys:     tagKeys(tags),
+		CategoryID:  optionalID(categoryID),
+		Views:       input.Views,
+		Show:        input.Show,
+		PublishAt:   input.PublishAt,
+		UnpublishAt: input.UnpublishAt,
+		PublishedAt: publishedAt,
+		Version:     1,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		Source:      input.Source,
+	}
+	blog.CommentsDisabled = input.CommentsDisabled
+	blog.BlogMetadata = blogMetadata(blog.Content)
+	blog.SearchTerms = blogSearchTerms(blog)
+	blog.MediaRefs = contentMediaRefs(blog.Content)
+
+	if err := s.store.CreateBlog(ctx, blog); err != nil {
+		return nil, err
+	}
+	s.counts.invalidate()
+	if blog.PublishAt != nil || blog.UnpublishAt != nil {
+		s.notifyScheduleChanged()
+	}
+	s.related.put(blog)
+	s.recordRevision(ctx, blog, input.Author, revisionFields, "")
 	return blog, nil
 }
 
-// UpdateBlog 更新博客文章
-func (s *BlogService) UpdateBlog(id string, title, content, author *string, tags []string, show *bool, views *int64) (*models.Blog, error) {
+// UpdateBlogInput 更新博客文章的参数，nil 字段表示不修改
+type UpdateBlogInput struct {
+	Title   *string
+	Content *string
+	Author  *string
+	Tags    []string // nil 表示不修改，空切片表示清空
+	Show    *bool
+	Views   *int64
+	Slug    *string // 修改后旧 slug 进入历史记录，仍可解析
+
+	CategoryID       *string // 所属分类ID，nil 表示不修改，空字符串表示设为未分类
+	CommentsDisabled *bool   // 是否关闭评论，nil 表示不修改
+
+	PublishAt   *time.Time // 定时发布时间，nil 表示不修改，指向零值表示清除（即以创建时间为发布时间）
+	UnpublishAt *time.Time // 定时下线时间，nil 表示不修改，指向零值表示清除
+
+	Editor    string    // 进行修改的用户，记录在版本历史中
+	IfMatch   []int64   // 非 nil 时要求文章当前版本号为其中之一，否则返回 *VersionConflictError
+	UpdatedAt time.Time // 更新时间，零值表示当前时间（导入时保留源文件中的时间）
+}
+
+// UpdateBlog 更新博客文章，内容有变化时记录新版本
+func (s *BlogService) UpdateBlog(id string, input UpdateBlogInput) (*models.Blog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objID, err := primitive.ObjectIDFromHex(id)
+	objID, err := parseBlogID(id)
 	if err != nil {
 		return nil, err
 	}
+	return s.updateBlog(ctx, objID, input, "")
+}
 
-	setFields := bson.M{
-		"updated_at": time.Now(),
+// maxUpdateAttempts 未指定 IfMatch 时，读取与写入之间文章被并发修改的重试次数
+const maxUpdateAttempts = 3
+
+// updateBlog 基于当前文档计算派生字段与变更字段并写入，note 记录在新版本中
+// 写入以读取时的版本号为条件，保证派生字段基于最新内容计算；
+// 调用方未指定 IfMatch 时，并发修改导致的冲突会自动重试
+func (s *BlogService) updateBlog(ctx context.Context, id primitive.ObjectID, input UpdateBlogInput, note string) (*models.Blog, error) {
+	for attempt := 1; ; attempt++ {
+		blog, err := s.tryUpdateBlog(ctx, id, input, note)
+		if !errors.Is(err, ErrVersionConflict) {
+			return blog, err
+		}
+		if input.IfMatch != nil || attempt == maxUpdateAttempts {
+			return nil, s.versionConflict(ctx, id)
+		}
 	}
+}
 
-	if title != nil {
-		setFields["title"] = *title
+// tryUpdateBlog 执行一次读取-计算-条件写入
+func (s *BlogService) tryUpdateBlog(ctx context.Context, id primitive.ObjectID, input UpdateBlogInput, note string) (*models.Blog, error) {
+	current, err := s.store.GetBlogByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	if content != nil {
-		setFields["content"] = *content
+	if current.DeletedAt != nil {
+		return nil, ErrBlogNotFound
 	}
-	if author != nil {
-		setFields["author"] = *author
+	if input.IfMatch != nil && !slices.Contains(input.IfMatch, current.Version) {
+		return nil, ErrVersionConflict
 	}
 
-	// tags == nil -> don't change tags; empty slice -> clear tags
-	if tags != nil {
-		setFields["tags"] = tags
+	updatedAt := input.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	update := BlogUpdate{
+		Title:     input.Title,
+		Content:   input.Content,
+		Author:    input.Author,
+		Tags:      NormalizeTags(input.Tags),
+		Show:      input.Show,
+		Views:     input.Views,
+		UpdatedAt: updatedAt,
+		Version:   &current.Version,
+
+		CommentsDisabled: input.CommentsDisabled,
+	}
+	changed := changedFields(current, update, input.Slug)
+
+	if input.CategoryID != nil {
+		categoryID, err := s.categoryRef(ctx, *input.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		update.CategoryID = &categoryID
 	}
 
-	if show != nil {
-		setFields["show"] = *show
+	if input.PublishAt != nil || input.UnpublishAt != nil {
+		publishedAt, unpublishAt := current.PublishedAt, current.UnpublishAt
+		if input.PublishAt != nil {
+			publishedAt = current.CreatedAt
+			if !input.PublishAt.IsZero() {
+				publishedAt = *input.PublishAt
+			}
+		}
+		if input.UnpublishAt != nil {
+			unpublishAt = optionalTime(*input.UnpublishAt)
+		}
+		if unpublishAt != nil && !unpublishAt.After(publishedAt) {
+			return nil, ErrInvalidSchedule
+		}
+		update.PublishAt = input.PublishAt
+		update.UnpublishAt = input.UnpublishAt
+		update.PublishedAt = publishedAt
 	}
 
-	// views == nil -> don't change; non-nil -> set to provided value
-	if views != nil {
-		setFields["views"] = *views
+	if input.Slug != nil && *input.Slug != current.Slug {
+		if err := s.checkSlug(ctx, *input.Slug, id); err != nil {
+			return nil, err
+		}
+		// 旧 slug 进入历史记录；若新 slug 曾经使用过，则从历史中移除
+		oldSlugs := slices.DeleteFunc(slices.Clone(current.OldSlugs), func(old string) bool { return old == *input.Slug })
+		if current.Slug != "" {
+			oldSlugs = append(oldSlugs, current.Slug)
+		}
+		update.Slug = input.Slug
+		update.OldSlugs = oldSlugs
 	}
 
-	update := bson.M{"$set": setFields}
+	if input.Title != nil || input.Content != nil || input.Tags != nil {
+		if input.Title != nil {
+			current.Title = *input.Title
+		}
+		if input.Content != nil {
+			current.Content = *input.Content
+			meta := blogMetadata(current.Content)
+			update.Metadata = &meta
+			update.MediaRefs = contentMediaRefs(current.Content)
+		}
+		if update.Tags != nil {
+			current.Tags = update.Tags
+		}
+		update.SearchTerms = blogSearchTerms(current)
+	}
 
-	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	blog, err := s.store.UpdateBlog(ctx, id, update)
 	if err != nil {
 		return nil, err
 	}
+	s.counts.invalidate()
+	if input.PublishAt != nil || input.UnpublishAt != nil {
+		s.notifyScheduleChanged()
+	}
+	if input.Title != nil || input.Content != nil || input.Tags != nil || input.CategoryID != nil ||
+		input.Show != nil || input.UnpublishAt != nil {
+		s.related.put(blog)
+	}
+	// 回滚即使内容未变化也记录版本，保留操作痕迹
+	if len(changed) > 0 || note != "" {
+		s.recordRevision(ctx, blog, input.Editor, changed, note)
+	}
+	return blog, nil
+}
+
+// DeleteBlog 将博客文章移入回收站，可通过 RestoreBlog 恢复
+// ifMatch 非 nil 时要求文章当前版本号为其中之一，否则返回 *VersionConflictError
+func (s *BlogService) DeleteBlog(id string, ifMatch []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	// 获取更新后的文档
-	var blog models.Blog
-	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&blog)
+	objID, err := parseBlogID(id)
+	if err != nil {
+		return err
+	}
+
+	var version *int64
+	if ifMatch != nil {
+		current, err := s.store.GetBlogByID(ctx, objID)
+		if err != nil {
+			return err
+		}
+		if current.DeletedAt != nil {
+			return ErrBlogNotFound
+		}
+		if !slices.Contains(ifMatch, current.Version) {
+			return &VersionConflictError{Current: current}
+		}
+		version = &current.Version
+	}
+
+	if err := s.store.TrashBlog(ctx, objID, time.Now(), version); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return s.versionConflict(ctx, objID)
+		}
+		return err
+	}
+	s.counts.invalidate()
+	s.related.remove(objID)
+	return nil
+}
+
+// ListTrash 分页获取回收站中的文章
+func (s *BlogService) ListTrash(query BlogQuery, withTotal bool) (*BlogPage, error) {
+	query.Trashed = true
+	return s.ListBlogs(query, withTotal)
+}
+
+// RestoreBlog 将文章移出回收站
+func (s *BlogService) RestoreBlog(id string) (*models.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseBlogID(id)
 	if err != nil {
 		return nil, err
 	}
-	return &blog, nil
+
+	if err := s.store.RestoreBlog(ctx, objID); err != nil {
+		return nil, err
+	}
+	s.counts.invalidate()
+	s.notifyScheduleChanged()
+	blog, err := s.store.GetBlogByID(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+	s.related.put(blog)
+	return blog, nil
 }
 
-// DeleteBlog 删除博客文章
-func (s *BlogService) DeleteBlog(id string) error {
+// PurgeBlog 永久删除回收站中的文章，不在回收站中的文章返回 ErrBlogNotFound
+func (s *BlogService) PurgeBlog(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objID, err := primitive.ObjectIDFromHex(id)
+	objID, err := parseBlogID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteBlog(ctx, objID); err != nil {
+		return err
+	}
+	s.counts.invalidate()
+	s.renders.remove(objID)
+	if err := s.comments.DeletePostComments(ctx, objID); err != nil {
+		return err
+	}
+	if err := s.reactions.DeletePostReactions(ctx, objID); err != nil {
+		return err
+	}
+	if _, err := s.series.PurgeSeriesPosts(ctx, []primitive.ObjectID{objID}, time.Now()); err != nil {
+		return err
+	}
+	return s.revisions.DeleteRevisions(ctx, objID)
+}
+
+// PurgeExpiredTrash 永久删除在回收站中超过保留期的文章，返回删除数量
+func (s *BlogService) PurgeExpiredTrash(retention time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// 出错之前已删除的文章同样需要清理关联的数据
+	ids, purgeErr := s.store.PurgeTrash(ctx, time.Now().Add(-retention))
+	if len(ids) > 0 {
+		s.counts.invalidate()
+		if _, err := s.series.PurgeSeriesPosts(ctx, ids, time.Now()); err != nil {
+			return int64(len(ids)), err
+		}
+	}
+	for _, id := range ids {
+		s.renders.remove(id)
+		if err := s.comments.DeletePostComments(ctx, id); err != nil {
+			return int64(len(ids)), err
+		}
+		if err := s.reactions.DeletePostReactions(ctx, id); err != nil {
+			return int64(len(ids)), err
+		}
+		if err := s.revisions.DeleteRevisions(ctx, id); err != nil {
+			return int64(len(ids)), err
+		}
+	}
+	return int64(len(ids)), purgeErr
+}
+
+// versionConflict 读取文章的最新内容并构造版本冲突错误
+func (s *BlogService) versionConflict(ctx context.Context, id primitive.ObjectID) error {
+	current, err := s.store.GetBlogByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.DeletedAt != nil {
+		return ErrBlogNotFound
+	}
+	return &VersionConflictError{Current: current}
+}
+
+// uniqueSlug 基于 base 生成未被其他文章占用（包括历史 slug）的 slug，冲突时追加 -2、-3…
+func (s *BlogService) uniqueSlug(ctx context.Context, base string, self primitive.ObjectID) (string, error) {
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		existing, err := s.store.GetBlogBySlug(ctx, candidate)
+		if errors.Is(err, ErrBlogNotFound) || (err == nil && existing.ID == self) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// checkSlug 校验手动指定的 slug 格式，并确认未被其他文章占用（包括历史 slug）
+func (s *BlogService) checkSlug(ctx context.Context, slug string, self primitive.ObjectID) error {
+	if err := ValidateSlug(slug); err != nil {
+		return err
+	}
+	existing, err := s.store.GetBlogBySlug(ctx, slug)
+	if errors.Is(err, ErrBlogNotFound) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
+	if existing.ID != self {
+		return ErrSlugTaken
+	}
+	return nil
+}
 
-	_, err = s.collection.DeleteOne(ctx, bson.M{"_id": objID})
-	return err
+// parseBlogID 解析文章ID，格式错误的ID视为文章不存在
+func parseBlogID(id string) (primitive.ObjectID, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrBlogNotFound
+	}
+	return objID, nil
 }
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// failingPurgeStore 在删除第一篇文章后返回错误的存储，模拟逐篇删除中途失败
+type failingPurgeStore struct {
+	*MemoryBlogStore
+}
+
+var errPurgeFailed = errors.New("删除失败")
+
+func (s failingPurgeStore) PurgeTrash(ctx context.Context, _ time.Time) ([]primitive.ObjectID, error) {
+	trashed, err := s.ListBlogs(ctx, BlogQuery{Trashed: true})
+	if err != nil || len(trashed) == 0 {
+		return nil, err
+	}
+	if err := s.DeleteBlog(ctx, trashed[0].ID); err != nil {
+		return nil, err
+	}
+	return []primitive.ObjectID{trashed[0].ID}, errPurgeFailed
+}
+
+func TestPurgeExpiredTrashKeepsRestoredPosts(t *testing.T) {
+	ctx := context.Background()
+	blogService := newTestBlogService()
+
+	var posts []*models.Blog
+	for _, title := range []string{"已删除", "已恢复"} {
+		blog, err := blogService.CreateBlog(CreateBlogInput{Title: title, Content: "正文", Author: "alice", Show: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		comment := &models.Comment{ID: primitive.NewObjectID(), PostID: blog.ID, AuthorName: "bob", Content: "评论", Status: models.CommentApproved}
+		if err := blogService.comments.CreateComment(ctx, comment); err != nil {
+			t.Fatal(err)
+		}
+		if err := blogService.DeleteBlog(blog.ID.Hex(), nil); err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, blog)
+	}
+	deleted, restored := posts[0], posts[1]
+	if _, err := blogService.RestoreBlog(restored.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := blogService.PurgeExpiredTrash(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("删除数量 = %d, want 1", n)
+	}
+	commentCount := func(id primitive.ObjectID) int64 {
+		n, err := blogService.comments.CountComments(ctx, CommentQuery{PostID: &id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if _, err := blogService.store.GetBlogByID(ctx, deleted.ID); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("过期文章 err = %v, want ErrBlogNotFound", err)
+	}
+	if commentCount(deleted.ID) != 0 {
+		t.Error("过期文章的评论应被删除")
+	}
+	if commentCount(restored.ID) != 1 {
+		t.Error("已恢复文章的评论不应被删除")
+	}
+	if revs, total, err := blogService.revisions.ListRevisions(ctx, restored.ID, 0, 0); err != nil || total == 0 {
+		t.Errorf("已恢复文章的版本 = %v, %v", revs, err)
+	}
+}
+
+func TestPurgeExpiredTrashCleansUpBeforeError(t *testing.T) {
+	ctx := context.Background()
+	store := failingPurgeStore{NewMemoryBlogStore()}
+	blogService := NewBlogService(store, NewMemoryRevisionStore(), NewMemoryCategoryStore(),
+		NewMemoryCommentStore(), NewMemoryReactionStore(), NewMemorySeriesStore(), 50)
+
+	for _, title := range []string{"第一篇", "第二篇"} {
+		blog, err := blogService.CreateBlog(CreateBlogInput{Title: title, Content: "正文", Author: "alice", Show: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := blogService.DeleteBlog(blog.ID.Hex(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	remaining, err := store.ListBlogs(ctx, BlogQuery{Trashed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := remaining[1].ID
+
+	n, err := blogService.PurgeExpiredTrash(0)
+	if !errors.Is(err, errPurgeFailed) {
+		t.Errorf("err = %v, want %v", err, errPurgeFailed)
+	}
+	if n != 1 {
+		t.Errorf("删除数量 = %d, want 1", n)
+	}
+	// 出错之前已删除的文章的版本同样被清理，未删除的文章保留版本
+	revisions, err := blogService.revisions.ListAllRevisions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rev := range revisions {
+		if rev.BlogID != kept {
+			t.Errorf("已删除文章 %s 的版本未被清理", rev.BlogID.Hex())
+		}
+	}
+	if len(revisions) == 0 {
+		t.Error("未删除文章的版本不应被清理")
+	}
+}
+
+func TestUpdateBlogIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  []int64
+		conflict bool
+	}{
+		{"不指定版本", nil, false},
+		{"版本相符", []int64{1}, false},
+		{"多个版本之一相符", []int64{5, 1}, false},
+		{"版本过期", []int64{2}, true},
+		{"没有有效版本", []int64{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogService := newTestBlogService()
+			blog, err := blogService.CreateBlog(CreateBlogInput{Title: "原标题", Content: "正文", Author: "alice", Show: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			title := "新标题"
+			updated, err := blogService.UpdateBlog(blog.ID.Hex(), UpdateBlogInput{Title: &title, IfMatch: tt.ifMatch})
+			if !tt.conflict {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if updated.Title != title || updated.Version != 2 {
+					t.Errorf("title/version = %q/%d", updated.Title, updated.Version)
+				}
+				return
+			}
+
+			var conflict *VersionConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("err = %v, want *VersionConflictError", err)
+			}
+			if conflict.Current.Version != 1 || conflict.Current.Title != "原标题" {
+				t.Errorf("冲突时的最新版本 = %d %q", conflict.Current.Version, conflict.Current.Title)
+			}
+			// 冲突时不写入
+			current, _ := blogService.GetBlogByID(blog.ID.Hex())
+			if current.Title != "原标题" || current.Version != 1 {
+				t.Errorf("冲突后的文章 = %q %d", current.Title, current.Version)
+			}
+
+			if err := blogService.DeleteBlog(blog.ID.Hex(), tt.ifMatch); !errors.As(err, &conflict) {
+				t.Errorf("DeleteBlog err = %v, want *VersionConflictError", err)
+			}
+		})
+	}
+}
+
+// racingBlogStore 在前 conflicts 次条件更新之前修改文章，模拟读取与写入之间的并发修改
+type racingBlogStore struct {
+	*MemoryBlogStore
+	conflicts int
+}
+
+func (s *racingBlogStore) UpdateBlog(ctx context.Context, id primitive.ObjectID, update BlogUpdate) (*models.Blog, error) {
+	if s.conflicts > 0 && update.Version != nil {
+		s.conflicts--
+		views := int64(s.conflicts)
+		if _, err := s.MemoryBlogStore.UpdateBlog(ctx, id, BlogUpdate{Views: &views}); err != nil {
+			return nil, err
+		}
+	}
+	return s.MemoryBlogStore.UpdateBlog(ctx, id, update)
+}
+
+func TestUpdateBlogRetriesOnConflict(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts int
+		ifMatch   []int64
+		conflict  bool
+	}{
+		{"冲突后重试成功", maxUpdateAttempts - 1, nil, false},
+		{"超过重试次数", maxUpdateAttempts, nil, true},
+		{"指定版本时不重试", 1, []int64{1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &racingBlogStore{MemoryBlogStore: NewMemoryBlogStore()}
+			blogService := NewBlogService(store, NewMemoryRevisionStore(), NewMemoryCategoryStore(),
+				NewMemoryCommentStore(), NewMemoryReactionStore(), NewMemorySeriesStore(), 50)
+			blog, err := blogService.CreateBlog(CreateBlogInput{Title: "原标题", Content: "正文", Author: "alice", Show: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store.conflicts = tt.conflicts
+			content := "新的正文"
+			updated, err := blogService.UpdateBlog(blog.ID.Hex(), UpdateBlogInput{Content: &content, IfMatch: tt.ifMatch})
+			if tt.conflict {
+				var conflict *VersionConflictError
+				if !errors.As(err, &conflict) {
+					t.Fatalf("err = %v, want *VersionConflictError", err)
+				}
+				if conflict.Current.Content != "正文" {
+					t.Errorf("冲突时的正文 = %q", conflict.Current.Content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 每次并发修改递增一次版本号，重试基于最新文档重新计算派生字段
+			if updated.Version != int64(tt.conflicts)+2 || updated.Content != content || updated.Excerpt != content {
+				t.Errorf("version/content/excerpt = %d/%q/%q", updated.Version, updated.Content, updated.Excerpt)
+			}
+		})
+	}
+}
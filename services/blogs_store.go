@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrBlogNotFound 文章不存在
+var ErrBlogNotFound = errors.New("文章不存在")
+
+// ErrVersionConflict 文章已被他人修改，请求基于的版本已过期
+var ErrVersionConflict = errors.New("文章已被修改，请获取最新版本后重试")
+
+// VersionConflictError 版本冲突错误，携带文章的最新内容，errors.Is(err, ErrVersionConflict) 为 true
+type VersionConflictError struct {
+	Current *models.Blog
+}
+
+func (e *VersionConflictError) Error() string { return ErrVersionConflict.Error() }
+
+func (e *VersionConflictError) Unwrap() error { return ErrVersionConflict }
+
+// IncrementViewsError 批量累加浏览次数时部分文章写入失败，Failed 只包含未写入的增量，其余已经生效
+type IncrementViewsError struct {
+	Failed map[primitive.ObjectID]int64
+	Err    error
+}
+
+func (e *IncrementViewsError) Error() string { return e.Err.Error() }
+
+func (e *IncrementViewsError) Unwrap() error { return e.Err }
+
+// BlogQuery 博客列表查询条件，零值字段表示不过滤
+type BlogQuery struct {
+	IDs           []primitive.ObjectID // 只返回这些ID的文章
+	Show          *bool                // 按是否展示过滤
+	Author        string               // 按作者过滤
+	Tags          []string             // 按标签过滤，大小写不敏感
+	TagMatchAll   bool                 // 为 true 时须包含全部 Tags，否则包含任一即可
+	CategoryIDs   []primitive.ObjectID // 只返回属于这些分类的文章
+	CreatedFrom   time.Time            // 创建时间下界（含）
+	CreatedTo     time.Time            // 创建时间上界（含）
+	UpdatedFrom   time.Time            // 更新时间下界（含）
+	UpdatedTo     time.Time            // 更新时间上界（含）
+	PublishedFrom time.Time            // 生效的发布时间下界（含）
+	PublishedTo   time.Time            // 生效的发布时间上界（含）
+	SearchTerms   []string             // 全文检索词，包含任一即匹配
+	Trashed       bool                 // 为 true 时只查询回收站中的文章，否则排除回收站中的文章
+	VisibleAt     time.Time            // 非零时只返回该时刻处于发布窗口内的文章（已到发布时间且未到下线时间）
+
+	Cursor *BlogCursor // 游标分页位置，设置时忽略 Page 与 Offset
+	Page   int64       // 页码，从 1 开始，BlogService 据此计算 Offset
+	Offset int64       // 跳过的文章数，存储层使用
+	Limit  int64       // 每页数量，<= 0 表示不分页
+}
+
+// BlogUpdate 博客文章的部分更新字段，nil 表示不修改
+type BlogUpdate struct {
+	Title   *string
+	Content *string
+	Author  *string
+	Tags    []string // nil 表示不修改，空切片表示清空
+	Show    *bool
+	Views   *int64
+
+	CommentsDisabled *bool
+
+	CategoryID *primitive.ObjectID // 所属分类，nil 表示不修改，指向 NilObjectID 表示清除
+
+	Slug     *string
+	OldSlugs []string // 完整替换历史 slug 列表，nil 表示不修改
+
+	PublishAt   *time.Time // 定时发布时间，nil 表示不修改，指向零值表示清除
+	UnpublishAt *time.Time // 定时下线时间，nil 表示不修改，指向零值表示清除
+	PublishedAt time.Time  // 生效的发布时间，零值表示不修改
+
+	Metadata    *models.BlogMetadata // 重新计算的摘要、目录与字数等信息，nil 表示不修改
+	SearchTerms []string             // 重新计算的全文检索词，nil 表示不修改
+	MediaRefs   []string             // 重新计算的媒体文件引用，nil 表示不修改，空切片表示清空
+	UpdatedAt   time.Time            // 更新时间，零值表示不修改（用于补齐派生字段等非编辑操作）
+
+	Version *int64 // 非 nil 时要求文章当前版本号与之相同，否则返回 ErrVersionConflict
+}
+
+// BlogStore 博客文章的存储接口，BlogService 通过它访问数据
+type BlogStore interface {
+	// CreateBlog 保存新文章，blog.ID 由调用方生成，slug 冲突时返回 ErrSlugTaken
+	CreateBlog(ctx context.Context, blog *models.Blog) error
+	// GetBlogByID 根据ID获取文章（包括回收站中的文章），不存在时返回 ErrBlogNotFound
+	GetBlogByID(ctx context.Context, id primitive.ObjectID) (*models.Blog, error)
+	// GetBlogBySlug 根据当前或历史 slug 获取文章（包括回收站中的文章），不存在时返回 ErrBlogNotFound
+	GetBlogBySlug(ctx context.Context, slug string) (*models.Blog, error)
+	// ListBlogs 按生效的发布时间倒序分页获取文章，游标分页时结果同样按倒序返回
+	ListBlogs(ctx context.Context, query BlogQuery) ([]*models.Blog, error)
+	// CountBlogs 统计符合条件的文章总数，忽略分页参数
+	CountBlogs(ctx context.Context, query BlogQuery) (int64, error)
+	// CountTags 统计符合条件的文章中每个标签的使用次数，按次数倒序、名称正序返回
+	CountTags(ctx context.Context, query BlogQuery) ([]*models.TagCount, error)
+	// CountCategories 统计符合条件的文章中直接属于每个分类的文章数，未分类的文章不计入
+	CountCategories(ctx context.Context, query BlogQuery) (map[primitive.ObjectID]int64, error)
+	// ArchiveBlogs 按生效发布时间在 loc 时区下的年月对符合条件的文章分组，按年月倒序返回，组内文章按发布时间倒序
+	ArchiveBlogs(ctx context.Context, query BlogQuery, loc *time.Location) ([]*models.ArchiveMonth, error)
+	// ListSitemapPosts 按发布时间倒序获取符合条件的全部文章，每篇文章只取站点地图所需的字段，忽略分页参数
+	ListSitemapPosts(ctx context.Context, query BlogQuery) ([]*models.SitemapPost, error)
+	// ListSearchCandidates 按发布时间倒序获取匹配 query.SearchTerms 的文章，每篇文章只取搜索排序所需的字段，
+	// SearchTerms 只保留与 query.SearchTerms 相同的词；Limit 大于 0 时最多返回 Limit 篇，忽略 Offset 与游标
+	ListSearchCandidates(ctx context.Context, query BlogQuery) ([]*models.SearchCandidate, error)
+	// ListMediaReferences 获取正文引用了 hashes 中任一媒体文件的全部文章（包括回收站中的文章），按ID排序
+	ListMediaReferences(ctx context.Context, hashes []string) ([]*models.MediaReference, error)
+	// ReassignCategory 将属于 from 中任一分类的全部文章（包括回收站中的文章）改为属于 to，to 为 nil 时清除分类
+	// 每篇被修改的文章版本号递增，返回被修改的文章数
+	ReassignCategory(ctx context.Context, from []primitive.ObjectID, to *primitive.ObjectID) (int64, error)
+	// UpdateBlog 以单次原子操作更新文章、递增版本号并返回更新后的文档
+	// 不存在或已在回收站时返回 ErrBlogNotFound，slug 冲突时返回 ErrSlugTaken，版本不符时返回 ErrVersionConflict
+	UpdateBlog(ctx context.Context, id primitive.ObjectID, update BlogUpdate) (*models.Blog, error)
+	// ListScheduledBlogs 获取不在回收站中、publish_at 或 unpublish_at 落在 (from, to] 内的文章
+	ListScheduledBlogs(ctx context.Context, from, to time.Time) ([]*models.Blog, error)
+	// IncrementViews 以原子自增方式批量累加浏览次数，不存在的文章会被忽略
+	// 部分写入失败时返回 *IncrementViewsError，其他错误按全部未写入处理
+	IncrementViews(ctx context.Context, increments map[primitive.ObjectID]int64) error
+	// IncrementComments 以原子自增方式调整评论数，不修改版本号与更新时间，不存在的文章会被忽略
+	IncrementComments(ctx context.Context, id primitive.ObjectID, delta int64) error
+	// IncrementReactions 以原子自增方式调整某种表情回应的数量，不修改版本号与更新时间，不存在的文章会被忽略
+	IncrementReactions(ctx context.Context, id primitive.ObjectID, kind string, delta int64) error
+	// SetReactions 以 counts 整体替换各种表情回应的数量，用于按回应记录修复数量，不修改版本号与更新时间，不存在的文章会被忽略
+	SetReactions(ctx context.Context, id primitive.ObjectID, counts map[string]int64) error
+	// TrashBlog 将文章移入回收站，不存在或已在回收站时返回 ErrBlogNotFound
+	// version 非 nil 时要求文章当前版本号与之相同，否则返回 ErrVersionConflict
+	TrashBlog(ctx context.Context, id primitive.ObjectID, at time.Time, version *int64) error
+	// RestoreBlog 将文章移出回收站，不在回收站时返回 ErrBlogNotFound
+	RestoreBlog(ctx context.Context, id primitive.ObjectID) error
+	// DeleteBlog 永久删除回收站中的文章，不在回收站时返回 ErrBlogNotFound
+	DeleteBlog(ctx context.Context, id primitive.ObjectID) error
+	// PurgeTrash 永久删除在 before 之前移入回收站的文章，只返回实际被删除的文章的ID（期间被恢复的文章不会被删除也不返回）
+	// 出错时同时返回出错之前已删除的文章的ID
+	PurgeTrash(ctx context.Context, before time.Time) ([]primitive.ObjectID, error)
+}
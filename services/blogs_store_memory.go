@@ -0,0 +1,676 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryBlogStore 基于内存的博客存储，用于本地运行与单元测试，进程退出后数据丢失
+type MemoryBlogStore struct {
+	mu    sync.RWMutex
+	blogs map[primitive.ObjectID]*models.Blog
+	// index 全文检索倒排索引：检索词 -> 包含该词的文章
+	index map[string]map[primitive.ObjectID]struct{}
+}
+
+// NewMemoryBlogStore 创建新的MemoryBlogStore实例
+func NewMemoryBlogStore() *MemoryBlogStore {
+	return &MemoryBlogStore{
+		blogs: make(map[primitive.ObjectID]*models.Blog),
+		index: make(map[string]map[primitive.ObjectID]struct{}),
+	}
+}
+
+// CreateBlog 保存新文章
+func (s *MemoryBlogStore) CreateBlog(_ context.Context, blog *models.Blog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if blog.ID.IsZero() {
+		blog.ID = primitive.NewObjectID()
+	}
+	if s.slugTaken(blog.Slug, blog.ID) {
+		return ErrSlugTaken
+	}
+	s.blogs[blog.ID] = cloneBlog(blog)
+	s.indexTerms(blog.ID, blog.SearchTerms)
+	return nil
+}
+
+// GetBlogByID 根据ID获取文章
+func (s *MemoryBlogStore) GetBlogByID(_ context.Context, id primitive.ObjectID) (*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blog, ok := s.blogs[id]
+	if !ok {
+		return nil, ErrBlogNotFound
+	}
+	return cloneBlog(blog), nil
+}
+
+// GetBlogBySlug 根据当前或历史 slug 获取文章
+func (s *MemoryBlogStore) GetBlogBySlug(_ context.Context, slug string) (*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, blog := range s.blogs {
+		if blog.Slug == slug || slices.Contains(blog.OldSlugs, slug) {
+			return cloneBlog(blog), nil
+		}
+	}
+	return nil, ErrBlogNotFound
+}
+
+// slugTaken 判断 slug 是否已被其他文章作为当前 slug 使用，与 Mongo 的唯一索引保持一致，调用方需持有锁
+func (s *MemoryBlogStore) slugTaken(slug string, self primitive.ObjectID) bool {
+	if slug == "" {
+		return false
+	}
+	for id, blog := range s.blogs {
+		if id != self && blog.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// ListBlogs 分页获取文章，按创建时间倒序
+func (s *MemoryBlogStore) ListBlogs(_ context.Context, query BlogQuery) ([]*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := s.filter(query)
+
+	if c := query.Cursor; c != nil {
+		// 游标之后的文章是倒序切片中的后缀，之前的文章是前缀
+		if c.Before {
+			end := sort.Search(len(matched), func(i int) bool { return !blogBeforeCursor(matched[i], c) })
+			start := 0
+			if query.Limit > 0 && int64(end) > query.Limit {
+				start = end - int(query.Limit)
+			}
+			matched = matched[start:end]
+		} else {
+			start := sort.Search(len(matched), func(i int) bool { return blogAfterCursor(matched[i], c) })
+			matched = matched[start:]
+			if query.Limit > 0 && int64(len(matched)) > query.Limit {
+				matched = matched[:query.Limit]
+			}
+		}
+	} else {
+		matched = matched[min(query.Offset, int64(len(matched))):]
+		if query.Limit > 0 && int64(len(matched)) > query.Limit {
+			matched = matched[:query.Limit]
+		}
+	}
+
+	blogs := make([]*models.Blog, 0, len(matched))
+	for _, blog := range matched {
+		blogs = append(blogs, cloneBlog(blog))
+	}
+	return blogs, nil
+}
+
+// CountBlogs 统计符合条件的文章总数
+func (s *MemoryBlogStore) CountBlogs(_ context.Context, query BlogQuery) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.filter(query))), nil
+}
+
+// CountTags 统计每个标签的使用次数
+func (s *MemoryBlogStore) CountTags(_ context.Context, query BlogQuery) ([]*models.TagCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]*models.TagCount)
+	keys := []string{}
+	for _, blog := range s.filter(query) {
+		for i, key := range blog.TagKeys {
+			if tc, ok := counts[key]; ok {
+				tc.Count++
+				continue
+			}
+			counts[key] = &models.TagCount{Tag: blog.Tags[i], Count: 1}
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]].Count != counts[keys[j]].Count {
+			return counts[keys[i]].Count > counts[keys[j]].Count
+		}
+		return keys[i] < keys[j]
+	})
+	tags := make([]*models.TagCount, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, counts[key])
+	}
+	return tags, nil
+}
+
+// CountCategories 统计直接属于每个分类的文章数
+func (s *MemoryBlogStore) CountCategories(_ context.Context, query BlogQuery) (map[primitive.ObjectID]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[primitive.ObjectID]int64)
+	for _, blog := range s.filter(query) {
+		if blog.CategoryID != nil {
+			counts[*blog.CategoryID]++
+		}
+	}
+	return counts, nil
+}
+
+// ArchiveBlogs 按年月分组文章
+func (s *MemoryBlogStore) ArchiveBlogs(_ context.Context, query BlogQuery, loc *time.Location) ([]*models.ArchiveMonth, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// filter 的结果已按发布时间倒序排列，同一年月的文章相邻
+	months := []*models.ArchiveMonth{}
+	var current *models.ArchiveMonth
+	for _, blog := range s.filter(query) {
+		t := blog.PublishedAt.In(loc)
+		if current == nil || current.Year != t.Year() || current.Month != int(t.Month()) {
+			current = &models.ArchiveMonth{Year: t.Year(), Month: int(t.Month()), Posts: []models.ArchiveEntry{}}
+			months = append(months, current)
+		}
+		current.Count++
+		current.Posts = append(current.Posts, models.ArchiveEntry{
+			ID:          blog.ID,
+			Slug:        blog.Slug,
+			Title:       blog.Title,
+			PublishedAt: blog.PublishedAt,
+		})
+	}
+	return months, nil
+}
+
+// ListSitemapPosts 获取站点地图所需的文章字段
+func (s *MemoryBlogStore) ListSitemapPosts(_ context.Context, query BlogQuery) ([]*models.SitemapPost, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := s.filter(query)
+	posts := make([]*models.SitemapPost, len(matched))
+	for i, blog := range matched {
+		posts[i] = &models.SitemapPost{
+			ID:          blog.ID,
+			Slug:        blog.Slug,
+			Tags:        slices.Clone(blog.Tags),
+			PublishedAt: blog.PublishedAt,
+			UpdatedAt:   blog.UpdatedAt,
+		}
+	}
+	return posts, nil
+}
+
+// ListSearchCandidates 获取搜索排序所需的文章字段
+func (s *MemoryBlogStore) ListSearchCandidates(_ context.Context, query BlogQuery) ([]*models.SearchCandidate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := s.filter(query)
+	if query.Limit > 0 && int64(len(matched)) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	candidates := make([]*models.SearchCandidate, len(matched))
+	for i, blog := range matched {
+		terms := []string{}
+		for _, term := range blog.SearchTerms {
+			if slices.Contains(query.SearchTerms, term) {
+				terms = append(terms, term)
+			}
+		}
+		candidates[i] = &models.SearchCandidate{
+			ID:          blog.ID,
+			Title:       blog.Title,
+			Tags:        slices.Clone(blog.Tags),
+			SearchTerms: terms,
+			PublishedAt: blog.PublishedAt,
+		}
+	}
+	return candidates, nil
+}
+
+// ListMediaReferences 获取引用了任一媒体文件的文章
+func (s *MemoryBlogStore) ListMediaReferences(_ context.Context, hashes []string) ([]*models.MediaReference, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	refs := []*models.MediaReference{}
+	for _, blog := range s.blogs {
+		if !slices.ContainsFunc(blog.MediaRefs, func(h string) bool { return slices.Contains(hashes, h) }) {
+			continue
+		}
+		ref := &models.MediaReference{
+			ID:        blog.ID,
+			Title:     blog.Title,
+			Slug:      blog.Slug,
+			MediaRefs: slices.Clone(blog.MediaRefs),
+		}
+		if blog.DeletedAt != nil {
+			deletedAt := *blog.DeletedAt
+			ref.DeletedAt = &deletedAt
+		}
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].ID.Hex() < refs[j].ID.Hex() })
+	return refs, nil
+}
+
+// filter 返回符合条件并已排序的文章，调用方需持有读锁
+func (s *MemoryBlogStore) filter(query BlogQuery) []*models.Blog {
+	candidates := s.blogs
+	if len(query.SearchTerms) > 0 {
+		// 通过倒排索引缩小候选范围
+		candidates = make(map[primitive.ObjectID]*models.Blog)
+		for _, term := range query.SearchTerms {
+			for id := range s.index[term] {
+				candidates[id] = s.blogs[id]
+			}
+		}
+	}
+
+	matched := make([]*models.Blog, 0, len(candidates))
+	for _, blog := range candidates {
+		if blogMatchesQuery(blog, query) {
+			matched = append(matched, blog)
+		}
+	}
+	sortBlogsByPublishedDesc(matched)
+	return matched
+}
+
+// indexTerms 将文章加入倒排索引，调用方需持有写锁
+func (s *MemoryBlogStore) indexTerms(id primitive.ObjectID, terms []string) {
+	for _, term := range terms {
+		ids, ok := s.index[term]
+		if !ok {
+			ids = make(map[primitive.ObjectID]struct{})
+			s.index[term] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+// unindexTerms 将文章移出倒排索引，调用方需持有写锁
+func (s *MemoryBlogStore) unindexTerms(id primitive.ObjectID, terms []string) {
+	for _, term := range terms {
+		delete(s.index[term], id)
+		if len(s.index[term]) == 0 {
+			delete(s.index, term)
+		}
+	}
+}
+
+// UpdateBlog 更新文章并返回更新后的文档
+func (s *MemoryBlogStore) UpdateBlog(_ context.Context, id primitive.ObjectID, update BlogUpdate) (*models.Blog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, ok := s.blogs[id]
+	if !ok || blog.DeletedAt != nil {
+		return nil, ErrBlogNotFound
+	}
+	if update.Version != nil && *update.Version != blog.Version {
+		return nil, ErrVersionConflict
+	}
+	if update.Slug != nil && s.slugTaken(*update.Slug, id) {
+		return nil, ErrSlugTaken
+	}
+
+	if !update.UpdatedAt.IsZero() {
+		blog.UpdatedAt = update.UpdatedAt
+	}
+	if update.Title != nil {
+		blog.Title = *update.Title
+	}
+	if update.Content != nil {
+		blog.Content = *update.Content
+	}
+	if update.Author != nil {
+		blog.Author = *update.Author
+	}
+	if update.Tags != nil {
+		blog.Tags = append([]string{}, update.Tags...)
+		blog.TagKeys = tagKeys(update.Tags)
+	}
+	if update.Show != nil {
+		blog.Show = *update.Show
+	}
+	if update.Views != nil {
+		blog.Views = *update.Views
+	}
+	if update.CommentsDisabled != nil {
+		blog.CommentsDisabled = *update.CommentsDisabled
+	}
+	if update.CategoryID != nil {
+		blog.CategoryID = optionalID(*update.CategoryID)
+	}
+	if update.Slug != nil {
+		blog.Slug = *update.Slug
+	}
+	if update.OldSlugs != nil {
+		blog.OldSlugs = append([]string{}, update.OldSlugs...)
+	}
+	if update.PublishAt != nil {
+		blog.PublishAt = optionalTime(*update.PublishAt)
+	}
+	if update.UnpublishAt != nil {
+		blog.UnpublishAt = optionalTime(*update.UnpublishAt)
+	}
+	if !update.PublishedAt.IsZero() {
+		blog.PublishedAt = update.PublishedAt
+	}
+	if update.Metadata != nil {
+		blog.BlogMetadata = *update.Metadata
+		blog.TOC = slices.Clone(update.Metadata.TOC)
+	}
+	if update.SearchTerms != nil {
+		s.unindexTerms(id, blog.SearchTerms)
+		blog.SearchTerms = append([]string{}, update.SearchTerms...)
+		s.indexTerms(id, blog.SearchTerms)
+	}
+	if update.MediaRefs != nil {
+		blog.MediaRefs = slices.Clone(update.MediaRefs)
+	}
+	blog.Version++
+	return cloneBlog(blog), nil
+}
+
+// ListScheduledBlogs 获取定时发布或下线时间落在 (from, to] 内的文章
+func (s *MemoryBlogStore) ListScheduledBlogs(_ context.Context, from, to time.Time) ([]*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inWindow := func(t *time.Time) bool {
+		return t != nil && t.After(from) && !t.After(to)
+	}
+	blogs := []*models.Blog{}
+	for _, blog := range s.blogs {
+		if blog.DeletedAt == nil && (inWindow(blog.PublishAt) || inWindow(blog.UnpublishAt)) {
+			blogs = append(blogs, cloneBlog(blog))
+		}
+	}
+	return blogs, nil
+}
+
+// ReassignCategory 批量修改文章所属分类
+func (s *MemoryBlogStore) ReassignCategory(_ context.Context, from []primitive.ObjectID, to *primitive.ObjectID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, blog := range s.blogs {
+		if blog.CategoryID == nil || !slices.Contains(from, *blog.CategoryID) {
+			continue
+		}
+		blog.CategoryID = nil
+		if to != nil {
+			blog.CategoryID = optionalID(*to)
+		}
+		blog.Version++
+		n++
+	}
+	return n, nil
+}
+
+// IncrementViews 批量累加浏览次数
+func (s *MemoryBlogStore) IncrementViews(_ context.Context, increments map[primitive.ObjectID]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, n := range increments {
+		if blog, ok := s.blogs[id]; ok {
+			blog.Views += n
+		}
+	}
+	return nil
+}
+
+// IncrementComments 调整评论数
+func (s *MemoryBlogStore) IncrementComments(_ context.Context, id primitive.ObjectID, delta int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if blog, ok := s.blogs[id]; ok {
+		blog.CommentCount += delta
+	}
+	return nil
+}
+
+// IncrementReactions 调整表情回应的数量
+func (s *MemoryBlogStore) IncrementReactions(_ context.Context, id primitive.ObjectID, kind string, delta int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if blog, ok := s.blogs[id]; ok {
+		if blog.Reactions == nil {
+			blog.Reactions = make(map[string]int64)
+		}
+		blog.Reactions[kind] += delta
+	}
+	return nil
+}
+
+// SetReactions 替换表情回应的数量
+func (s *MemoryBlogStore) SetReactions(_ context.Context, id primitive.ObjectID, counts map[string]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if blog, ok := s.blogs[id]; ok {
+		blog.Reactions = nil
+		if len(counts) > 0 {
+			blog.Reactions = maps.Clone(counts)
+		}
+	}
+	return nil
+}
+
+// TrashBlog 将文章移入回收站
+func (s *MemoryBlogStore) TrashBlog(_ context.Context, id primitive.ObjectID, at time.Time, version *int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, ok := s.blogs[id]
+	if !ok || blog.DeletedAt != nil {
+		return ErrBlogNotFound
+	}
+	if version != nil && *version != blog.Version {
+		return ErrVersionConflict
+	}
+	blog.DeletedAt = &at
+	return nil
+}
+
+// RestoreBlog 将文章移出回收站
+func (s *MemoryBlogStore) RestoreBlog(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, ok := s.blogs[id]
+	if !ok || blog.DeletedAt == nil {
+		return ErrBlogNotFound
+	}
+	blog.DeletedAt = nil
+	return nil
+}
+
+// DeleteBlog 永久删除回收站中的文章
+func (s *MemoryBlogStore) DeleteBlog(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, ok := s.blogs[id]
+	if !ok || blog.DeletedAt == nil {
+		return ErrBlogNotFound
+	}
+	s.remove(blog)
+	return nil
+}
+
+// PurgeTrash 永久删除在 before 之前移入回收站的文章
+func (s *MemoryBlogStore) PurgeTrash(_ context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []primitive.ObjectID
+	for _, blog := range s.blogs {
+		if blog.DeletedAt != nil && blog.DeletedAt.Before(before) {
+			s.remove(blog)
+			ids = append(ids, blog.ID)
+		}
+	}
+	return ids, nil
+}
+
+// remove 从存储与倒排索引中移除文章，调用方需持有写锁
+func (s *MemoryBlogStore) remove(blog *models.Blog) {
+	s.unindexTerms(blog.ID, blog.SearchTerms)
+	delete(s.blogs, blog.ID)
+}
+
+// blogMatchesQuery 判断文章是否满足查询条件，语义与 blogQueryFilter 保持一致
+func blogMatchesQuery(blog *models.Blog, query BlogQuery) bool {
+	if query.Trashed != (blog.DeletedAt != nil) {
+		return false
+	}
+	if len(query.IDs) > 0 && !slices.Contains(query.IDs, blog.ID) {
+		return false
+	}
+	if query.Show != nil && blog.Show != *query.Show {
+		return false
+	}
+	if query.Author != "" && blog.Author != query.Author {
+		return false
+	}
+	if len(query.Tags) > 0 {
+		matchedAny, matchedAll := false, true
+		for _, key := range tagKeys(query.Tags) {
+			if slices.Contains(blog.TagKeys, key) {
+				matchedAny = true
+			} else {
+				matchedAll = false
+			}
+		}
+		if (query.TagMatchAll && !matchedAll) || (!query.TagMatchAll && !matchedAny) {
+			return false
+		}
+	}
+	if len(query.CategoryIDs) > 0 && (blog.CategoryID == nil || !slices.Contains(query.CategoryIDs, *blog.CategoryID)) {
+		return false
+	}
+	if len(query.SearchTerms) > 0 && !slices.ContainsFunc(query.SearchTerms, func(t string) bool {
+		return slices.Contains(blog.SearchTerms, t)
+	}) {
+		return false
+	}
+	if !timeInRange(blog.CreatedAt, query.CreatedFrom, query.CreatedTo) {
+		return false
+	}
+	if !timeInRange(blog.UpdatedAt, query.UpdatedFrom, query.UpdatedTo) {
+		return false
+	}
+	if !timeInRange(blog.PublishedAt, query.PublishedFrom, query.PublishedTo) {
+		return false
+	}
+	if !query.VisibleAt.IsZero() && !blogInPublishWindow(blog, query.VisibleAt) {
+		return false
+	}
+	return true
+}
+
+// blogInPublishWindow 判断文章在 at 时刻是否已到发布时间且未到下线时间（不考虑 Show）
+func blogInPublishWindow(blog *models.Blog, at time.Time) bool {
+	if blog.PublishedAt.After(at) {
+		return false
+	}
+	return blog.UnpublishAt == nil || blog.UnpublishAt.After(at)
+}
+
+// timeInRange 判断时间是否落在闭区间内，零值端点表示不限制
+func timeInRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
+	return true
+}
+
+// sortBlogsByPublishedDesc 按生效的发布时间倒序排列，时间相同时按ID倒序，与 Mongo 存储保持一致
+func sortBlogsByPublishedDesc(blogs []*models.Blog) {
+	sort.Slice(blogs, func(i, j int) bool {
+		if !blogs[i].PublishedAt.Equal(blogs[j].PublishedAt) {
+			return blogs[i].PublishedAt.After(blogs[j].PublishedAt)
+		}
+		return blogs[i].ID.Hex() > blogs[j].ID.Hex()
+	})
+}
+
+// optionalTime 将零值时间转换为 nil，非零值返回其副本的指针
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// optionalID 将 NilObjectID 转换为 nil，其他值返回其副本的指针
+func optionalID(id primitive.ObjectID) *primitive.ObjectID {
+	if id.IsZero() {
+		return nil
+	}
+	return &id
+}
+
+// cloneBlog 复制文章，避免调用方修改存储内部的数据
+func cloneBlog(blog *models.Blog) *models.Blog {
+	c := *blog
+	if blog.Tags != nil {
+		c.Tags = append([]string{}, blog.Tags...)
+	}
+	if blog.TagKeys != nil {
+		c.TagKeys = append([]string{}, blog.TagKeys...)
+	}
+	if blog.DeletedAt != nil {
+		deletedAt := *blog.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	if blog.CategoryID != nil {
+		categoryID := *blog.CategoryID
+		c.CategoryID = &categoryID
+	}
+	if blog.PublishAt != nil {
+		publishAt := *blog.PublishAt
+		c.PublishAt = &publishAt
+	}
+	if blog.UnpublishAt != nil {
+		unpublishAt := *blog.UnpublishAt
+		c.UnpublishAt = &unpublishAt
+	}
+	if blog.OldSlugs != nil {
+		c.OldSlugs = append([]string{}, blog.OldSlugs...)
+	}
+	if blog.SearchTerms != nil {
+		c.SearchTerms = append([]string{}, blog.SearchTerms...)
+	}
+	c.TOC = slices.Clone(blog.TOC)
+	c.MediaRefs = slices.Clone(blog.MediaRefs)
+	c.Reactions = maps.Clone(blog.Reactions)
+	return &c
+}
@@ -0,0 +1,705 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoBlogStore 基于 MongoDB 的博客存储
+type MongoBlogStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoBlogStore 创建新的MongoBlogStore实例
+func NewMongoBlogStore(client *mongo.Client, dbName, collectionName string) *MongoBlogStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoBlogStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建查询所需的索引，并为旧文档补齐派生字段，重复调用是安全的
+func (s *MongoBlogStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// 游标分页按 (published_at, _id) 排序与比较
+		{Keys: bson.D{{Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "show", Value: 1}, {Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "publish_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "unpublish_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "tag_keys", Value: 1}}},
+		{Keys: bson.D{{Key: "category_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "search_terms", Value: 1}}},
+		{Keys: bson.D{{Key: "media_refs", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{
+			Keys: bson.D{{Key: "slug", Value: 1}},
+			// 旧文档可能没有 slug，仅对非空 slug 做唯一约束
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"slug": bson.M{"$gt": ""}}),
+		},
+		{Keys: bson.D{{Key: "old_slugs", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 补齐标签匹配键
+	_, err = s.collection.UpdateMany(ctx,
+		bson.M{"tags": bson.M{"$exists": true}, "tag_keys": bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{"tag_keys": bson.M{"$map": bson.M{
+			"input": "$tags",
+			"in":    bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$$this"}}},
+		}}}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// 补齐版本号，使旧文章也能参与乐观并发控制
+	_, err = s.collection.UpdateMany(ctx,
+		bson.M{"version": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"version": int64(1)}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// 补齐生效的发布时间
+	_, err = s.collection.UpdateMany(ctx,
+		bson.M{"published_at": bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{"published_at": bson.M{"$ifNull": bson.A{"$publish_at", "$created_at"}}}}},
+	)
+	return err
+}
+
+// CreateBlog 保存新文章
+func (s *MongoBlogStore) CreateBlog(ctx context.Context, blog *models.Blog) error {
+	_, err := s.collection.InsertOne(ctx, blog)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSlugTaken
+	}
+	return err
+}
+
+// GetBlogByID 根据ID获取文章
+func (s *MongoBlogStore) GetBlogByID(ctx context.Context, id primitive.ObjectID) (*models.Blog, error) {
+	var blog models.Blog
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&blog)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrBlogNotFound
+		}
+		return nil, err
+	}
+	return &blog, nil
+}
+
+// GetBlogBySlug 根据当前或历史 slug 获取文章
+func (s *MongoBlogStore) GetBlogBySlug(ctx context.Context, slug string) (*models.Blog, error) {
+	var blog models.Blog
+	err := s.collection.FindOne(ctx, bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"old_slugs": slug},
+	}}).Decode(&blog)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrBlogNotFound
+		}
+		return nil, err
+	}
+	return &blog, nil
+}
+
+// ListBlogs 分页获取文章，按生效的发布时间倒序
+func (s *MongoBlogStore) ListBlogs(ctx context.Context, query BlogQuery) ([]*models.Blog, error) {
+	filter := blogQueryFilter(query)
+
+	// 默认倒序；向前翻页时正序查询游标之前最近的文章，再翻转回倒序
+	order := -1
+	opts := options.Find()
+	if c := query.Cursor; c != nil {
+		op := "$lt"
+		if c.Before {
+			op, order = "$gt", 1
+		}
+		filter = andFilter(filter, bson.M{"$or": bson.A{
+			bson.M{"published_at": bson.M{op: c.PublishedAt}},
+			bson.M{"published_at": c.PublishedAt, "_id": bson.M{op: c.ID}},
+		}})
+		if query.Limit > 0 {
+			opts.SetLimit(query.Limit)
+		}
+	} else {
+		if query.Offset > 0 {
+			opts.SetSkip(query.Offset)
+		}
+		if query.Limit > 0 {
+			opts.SetLimit(query.Limit)
+		}
+	}
+	opts.SetSort(bson.D{{Key: "published_at", Value: order}, {Key: "_id", Value: order}})
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blogs := []*models.Blog{}
+	if err = cursor.All(ctx, &blogs); err != nil {
+		return nil, err
+	}
+	if order == 1 {
+		slices.Reverse(blogs)
+	}
+	return blogs, nil
+}
+
+// CountBlogs 统计符合条件的文章总数
+func (s *MongoBlogStore) CountBlogs(ctx context.Context, query BlogQuery) (int64, error) {
+	return s.collection.CountDocuments(ctx, blogQueryFilter(query))
+}
+
+// CountTags 统计每个标签的使用次数
+func (s *MongoBlogStore) CountTags(ctx context.Context, query BlogQuery) ([]*models.TagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: blogQueryFilter(query)}},
+		{{Key: "$project", Value: bson.M{"pairs": bson.M{"$zip": bson.M{"inputs": bson.A{"$tag_keys", "$tags"}}}}}},
+		{{Key: "$unwind", Value: "$pairs"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$arrayElemAt": bson.A{"$pairs", 0}},
+			"tag":   bson.M{"$first": bson.M{"$arrayElemAt": bson.A{"$pairs", 1}}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tags := []*models.TagCount{}
+	if err = cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CountCategories 统计直接属于每个分类的文章数
+func (s *MongoBlogStore) CountCategories(ctx context.Context, query BlogQuery) (map[primitive.ObjectID]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: andFilter(blogQueryFilter(query), bson.M{"category_id": bson.M{"$exists": true}})}},
+		{{Key: "$group", Value: bson.M{"_id": "$category_id", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	counts := make(map[primitive.ObjectID]int64, len(docs))
+	for _, doc := range docs {
+		counts[doc.ID] = doc.Count
+	}
+	return counts, nil
+}
+
+// ArchiveBlogs 按年月分组文章，分组与计数在数据库中完成，每篇文章只取列表所需的字段
+func (s *MongoBlogStore) ArchiveBlogs(ctx context.Context, query BlogQuery, loc *time.Location) ([]*models.ArchiveMonth, error) {
+	timezone := loc.String()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: blogQueryFilter(query)}},
+		{{Key: "$sort", Value: bson.D{{Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"year":  bson.M{"$year": bson.M{"date": "$published_at", "timezone": timezone}},
+				"month": bson.M{"$month": bson.M{"date": "$published_at", "timezone": timezone}},
+			},
+			"count": bson.M{"$sum": 1},
+			"posts": bson.M{"$push": bson.M{
+				"_id":          "$_id",
+				"slug":         "$slug",
+				"title":        "$title",
+				"published_at": "$published_at",
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.year", Value: -1}, {Key: "_id.month", Value: -1}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":   0,
+			"year":  "$_id.year",
+			"month": "$_id.month",
+			"count": 1,
+			"posts": 1,
+		}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	months := []*models.ArchiveMonth{}
+	if err = cursor.All(ctx, &months); err != nil {
+		return nil, err
+	}
+	return months, nil
+}
+
+// ListSitemapPosts 获取站点地图所需的文章字段，不读取正文
+func (s *MongoBlogStore) ListSitemapPosts(ctx context.Context, query BlogQuery) ([]*models.SitemapPost, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"slug": 1, "tags": 1, "published_at": 1, "updated_at": 1}).
+		SetSort(bson.D{{Key: "published_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := s.collection.Find(ctx, blogQueryFilter(query), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	posts := []*models.SitemapPost{}
+	if err = cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// ListSearchCandidates 获取搜索排序所需的文章字段，不读取正文，检索词在数据库中过滤后返回
+func (s *MongoBlogStore) ListSearchCandidates(ctx context.Context, query BlogQuery) ([]*models.SearchCandidate, error) {
+	terms := query.SearchTerms
+	if terms == nil {
+		terms = []string{}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: blogQueryFilter(query)}},
+		{{Key: "$sort", Value: bson.D{{Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}}},
+	}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.Limit}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.M{
+		"title":        1,
+		"tags":         1,
+		"published_at": 1,
+		"search_terms": bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$search_terms", bson.A{}}},
+			"cond":  bson.M{"$in": bson.A{"$$this", terms}},
+		}},
+	}}})
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	candidates := []*models.SearchCandidate{}
+	if err = cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// ListMediaReferences 获取引用了任一媒体文件的文章，不读取正文
+func (s *MongoBlogStore) ListMediaReferences(ctx context.Context, hashes []string) ([]*models.MediaReference, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "slug": 1, "media_refs": 1, "deleted_at": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"media_refs": bson.M{"$in": hashes}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	refs := []*models.MediaReference{}
+	if err = cursor.All(ctx, &refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// andFilter 将附加条件以 $and 方式合并进过滤器，避免与已有的同名键冲突
+func andFilter(filter bson.M, cond bson.M) bson.M {
+	if and, ok := filter["$and"].(bson.A); ok {
+		filter["$and"] = append(and, cond)
+	} else {
+		filter["$and"] = bson.A{cond}
+	}
+	return filter
+}
+
+// blogQueryFilter 将查询条件转换为 MongoDB 过滤器
+func blogQueryFilter(query BlogQuery) bson.M {
+	filter := bson.M{"deleted_at": bson.M{"$exists": query.Trashed}}
+	if len(query.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": query.IDs}
+	}
+	if query.Show != nil {
+		filter["show"] = *query.Show
+	}
+	if query.Author != "" {
+		filter["author"] = query.Author
+	}
+	if len(query.Tags) > 0 {
+		op := "$in"
+		if query.TagMatchAll {
+			op = "$all"
+		}
+		filter["tag_keys"] = bson.M{op: tagKeys(query.Tags)}
+	}
+	if len(query.CategoryIDs) > 0 {
+		filter["category_id"] = bson.M{"$in": query.CategoryIDs}
+	}
+	if len(query.SearchTerms) > 0 {
+		filter["search_terms"] = bson.M{"$in": query.SearchTerms}
+	}
+	if r := timeRangeFilter(query.CreatedFrom, query.CreatedTo); r != nil {
+		filter["created_at"] = r
+	}
+	if r := timeRangeFilter(query.UpdatedFrom, query.UpdatedTo); r != nil {
+		filter["updated_at"] = r
+	}
+	if r := timeRangeFilter(query.PublishedFrom, query.PublishedTo); r != nil {
+		filter["published_at"] = r
+	}
+	if !query.VisibleAt.IsZero() {
+		filter = andFilter(filter, bson.M{"published_at": bson.M{"$lte": query.VisibleAt}})
+		filter = andFilter(filter, bson.M{"$or": bson.A{
+			bson.M{"unpublish_at": bson.M{"$exists": false}},
+			bson.M{"unpublish_at": bson.M{"$gt": query.VisibleAt}},
+		}})
+	}
+	return filter
+}
+
+// timeRangeFilter 生成闭区间时间过滤条件，两端均为零值时返回 nil
+func timeRangeFilter(from, to time.Time) bson.M {
+	if from.IsZero() && to.IsZero() {
+		return nil
+	}
+	r := bson.M{}
+	if !from.IsZero() {
+		r["$gte"] = from
+	}
+	if !to.IsZero() {
+		r["$lte"] = to
+	}
+	return r
+}
+
+// UpdateBlog 更新文章并返回更新后的文档
+func (s *MongoBlogStore) UpdateBlog(ctx context.Context, id primitive.ObjectID, update BlogUpdate) (*models.Blog, error) {
+	setFields := bson.M{}
+	if !update.UpdatedAt.IsZero() {
+		setFields["updated_at"] = update.UpdatedAt
+	}
+
+	if update.Title != nil {
+		setFields["title"] = *update.Title
+	}
+	if update.Content != nil {
+		setFields["content"] = *update.Content
+	}
+	if update.Author != nil {
+		setFields["author"] = *update.Author
+	}
+
+	// tags == nil -> don't change tags; empty slice -> clear tags
+	if update.Tags != nil {
+		setFields["tags"] = update.Tags
+		setFields["tag_keys"] = tagKeys(update.Tags)
+	}
+
+	if update.Show != nil {
+		setFields["show"] = *update.Show
+	}
+
+	// views == nil -> don't change; non-nil -> set to provided value
+	if update.Views != nil {
+		setFields["views"] = *update.Views
+	}
+
+	if update.CommentsDisabled != nil {
+		setFields["comments_disabled"] = *update.CommentsDisabled
+	}
+
+	if update.Slug != nil {
+		setFields["slug"] = *update.Slug
+	}
+	if update.OldSlugs != nil {
+		setFields["old_slugs"] = update.OldSlugs
+	}
+
+	unsetFields := bson.M{}
+	if update.CategoryID != nil {
+		if update.CategoryID.IsZero() {
+			unsetFields["category_id"] = ""
+		} else {
+			setFields["category_id"] = *update.CategoryID
+		}
+	}
+	if update.PublishAt != nil {
+		if update.PublishAt.IsZero() {
+			unsetFields["publish_at"] = ""
+		} else {
+			setFields["publish_at"] = *update.PublishAt
+		}
+	}
+	if update.UnpublishAt != nil {
+		if update.UnpublishAt.IsZero() {
+			unsetFields["unpublish_at"] = ""
+		} else {
+			setFields["unpublish_at"] = *update.UnpublishAt
+		}
+	}
+	if !update.PublishedAt.IsZero() {
+		setFields["published_at"] = update.PublishedAt
+	}
+
+	if m := update.Metadata; m != nil {
+		setFields["excerpt"] = m.Excerpt
+		setFields["toc"] = m.TOC
+		setFields["word_count"] = m.WordCount
+		setFields["char_count"] = m.CharCount
+		setFields["reading_minutes"] = m.ReadingMinutes
+	}
+	if update.SearchTerms != nil {
+		setFields["search_terms"] = update.SearchTerms
+	}
+	if update.MediaRefs != nil {
+		if len(update.MediaRefs) == 0 {
+			unsetFields["media_refs"] = ""
+		} else {
+			setFields["media_refs"] = update.MediaRefs
+		}
+	}
+
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}
+	if update.Version != nil {
+		filter["version"] = *update.Version
+	}
+	change := bson.M{"$inc": bson.M{"version": 1}}
+	if len(setFields) > 0 {
+		change["$set"] = setFields
+	}
+	if len(unsetFields) > 0 {
+		change["$unset"] = unsetFields
+	}
+
+	// 更新与读取更新后的文档在同一次操作中完成
+	var blog models.Blog
+	err := s.collection.FindOneAndUpdate(ctx, filter, change,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&blog)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, s.missReason(ctx, id, update.Version)
+		}
+		return nil, err
+	}
+	return &blog, nil
+}
+
+// missReason 在带条件的写操作未命中时区分文章不存在与版本冲突
+func (s *MongoBlogStore) missReason(ctx context.Context, id primitive.ObjectID, version *int64) error {
+	if version == nil {
+		return ErrBlogNotFound
+	}
+	blog, err := s.GetBlogByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if blog.DeletedAt != nil {
+		return ErrBlogNotFound
+	}
+	return ErrVersionConflict
+}
+
+// ListScheduledBlogs 获取定时发布或下线时间落在 (from, to] 内的文章
+func (s *MongoBlogStore) ListScheduledBlogs(ctx context.Context, from, to time.Time) ([]*models.Blog, error) {
+	window := bson.M{"$gt": from, "$lte": to}
+	cursor, err := s.collection.Find(ctx, bson.M{
+		"deleted_at": bson.M{"$exists": false},
+		"$or":        bson.A{bson.M{"publish_at": window}, bson.M{"unpublish_at": window}},
+	}, options.Find().SetProjection(bson.M{"search_terms": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blogs := []*models.Blog{}
+	if err = cursor.All(ctx, &blogs); err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
+
+// ReassignCategory 批量修改文章所属分类
+func (s *MongoBlogStore) ReassignCategory(ctx context.Context, from []primitive.ObjectID, to *primitive.ObjectID) (int64, error) {
+	if len(from) == 0 {
+		return 0, nil
+	}
+	change := bson.M{"$inc": bson.M{"version": 1}}
+	if to == nil {
+		change["$unset"] = bson.M{"category_id": ""}
+	} else {
+		change["$set"] = bson.M{"category_id": *to}
+	}
+	result, err := s.collection.UpdateMany(ctx, bson.M{"category_id": bson.M{"$in": from}}, change)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// IncrementViews 批量累加浏览次数
+func (s *MongoBlogStore) IncrementViews(ctx context.Context, increments map[primitive.ObjectID]int64) error {
+	if len(increments) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, 0, len(increments))
+	writes := make([]mongo.WriteModel, 0, len(increments))
+	for id, n := range increments {
+		ids = append(ids, id)
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$inc": bson.M{"views": n}}))
+	}
+	_, err := s.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+
+	// 无序批量写入中个别操作失败时其余操作仍然生效，只报告失败的部分，避免重试时重复累加
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		failed := make(map[primitive.ObjectID]int64, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Index >= 0 && writeErr.Index < len(ids) {
+				failed[ids[writeErr.Index]] = increments[ids[writeErr.Index]]
+			}
+		}
+		return &IncrementViewsError{Failed: failed, Err: err}
+	}
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError != nil {
+		// 写入已在主节点生效，只是未达到要求的写关注级别，重试会重复累加
+		return &IncrementViewsError{Failed: map[primitive.ObjectID]int64{}, Err: err}
+	}
+	return err
+}
+
+// IncrementComments 调整评论数
+func (s *MongoBlogStore) IncrementComments(ctx context.Context, id primitive.ObjectID, delta int64) error {
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"comment_count": delta}})
+	return err
+}
+
+// IncrementReactions 调整表情回应的数量，kind 已由调用方校验，不含 . 与 $
+func (s *MongoBlogStore) IncrementReactions(ctx context.Context, id primitive.ObjectID, kind string, delta int64) error {
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"reactions." + kind: delta}})
+	return err
+}
+
+// SetReactions 替换表情回应的数量
+func (s *MongoBlogStore) SetReactions(ctx context.Context, id primitive.ObjectID, counts map[string]int64) error {
+	change := bson.M{"$unset": bson.M{"reactions": ""}}
+	if len(counts) > 0 {
+		change = bson.M{"$set": bson.M{"reactions": counts}}
+	}
+	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": id}, change)
+	return err
+}
+
+// TrashBlog 将文章移入回收站
+func (s *MongoBlogStore) TrashBlog(ctx context.Context, id primitive.ObjectID, at time.Time, version *int64) error {
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}
+	if version != nil {
+		filter["version"] = *version
+	}
+	result, err := s.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"deleted_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return s.missReason(ctx, id, version)
+	}
+	return nil
+}
+
+// RestoreBlog 将文章移出回收站
+func (s *MongoBlogStore) RestoreBlog(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"deleted_at": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrBlogNotFound
+	}
+	return nil
+}
+
+// DeleteBlog 永久删除回收站中的文章
+func (s *MongoBlogStore) DeleteBlog(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrBlogNotFound
+	}
+	return nil
+}
+
+// PurgeTrash 永久删除在 before 之前移入回收站的文章
+func (s *MongoBlogStore) PurgeTrash(ctx context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": before}}
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	// 逐篇删除并仍要求 deleted_at 满足条件，查询之后被恢复的文章不会被删除，也不会出现在返回的ID中
+	var ids []primitive.ObjectID
+	for _, doc := range docs {
+		result, err := s.collection.DeleteOne(ctx, bson.M{"_id": doc.ID, "deleted_at": bson.M{"$lt": before}})
+		if err != nil {
+			return ids, err
+		}
+		if result.DeletedCount == 1 {
+			ids = append(ids, doc.ID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,507 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMemoryBlogStore(t *testing.T) {
+	testBlogStore(t, func(t *testing.T) BlogStore { return NewMemoryBlogStore() })
+}
+
+// TestMongoBlogStore 设置 MONGO_TEST_URI 时对 MongoDB 运行同一组用例，每个用例使用独立的临时数据库
+func TestMongoBlogStore(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("未设置 MONGO_TEST_URI")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	testBlogStore(t, func(t *testing.T) BlogStore {
+		dbName := "blog_test_" + primitive.NewObjectID().Hex()
+		t.Cleanup(func() { client.Database(dbName).Drop(context.Background()) })
+		store := NewMongoBlogStore(client, dbName, "blogs")
+		if err := store.EnsureSchema(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
+
+// testBlogStore BlogStore 的行为约定，各存储实现须全部通过
+func testBlogStore(t *testing.T, newStore func(t *testing.T) BlogStore) {
+	t.Run("CreateAndGet", func(t *testing.T) { testBlogStoreCreateAndGet(t, newStore(t)) })
+	t.Run("Slugs", func(t *testing.T) { testBlogStoreSlugs(t, newStore(t)) })
+	t.Run("ListFilters", func(t *testing.T) { testBlogStoreListFilters(t, newStore(t)) })
+	t.Run("Pagination", func(t *testing.T) { testBlogStorePagination(t, newStore(t)) })
+	t.Run("CountTags", func(t *testing.T) { testBlogStoreCountTags(t, newStore(t)) })
+	t.Run("SearchCandidates", func(t *testing.T) { testBlogStoreSearchCandidates(t, newStore(t)) })
+	t.Run("Update", func(t *testing.T) { testBlogStoreUpdate(t, newStore(t)) })
+	t.Run("Counters", func(t *testing.T) { testBlogStoreCounters(t, newStore(t)) })
+	t.Run("Trash", func(t *testing.T) { testBlogStoreTrash(t, newStore(t)) })
+}
+
+// testEpoch 测试文章发布时间的基准，取整到毫秒以兼容 MongoDB 的时间精度
+var testEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestBlog 创建测试文章，第 n 篇的发布时间为基准时间之后 n 小时
+func newTestBlog(n int, modify func(*models.Blog)) *models.Blog {
+	at := testEpoch.Add(time.Duration(n) * time.Hour)
+	blog := &models.Blog{
+		ID:          primitive.NewObjectID(),
+		Title:       fmt.Sprintf("文章 %d", n),
+		Slug:        fmt.Sprintf("post-%d", n),
+		Content:     "正文",
+		Author:      "alice",
+		Show:        true,
+		PublishedAt: at,
+		Version:     1,
+		CreatedAt:   at,
+		UpdatedAt:   at,
+	}
+	if modify != nil {
+		modify(blog)
+	}
+	blog.TagKeys = tagKeys(blog.Tags)
+	return blog
+}
+
+// mustCreateBlogs 保存测试文章，失败时终止测试
+func mustCreateBlogs(t *testing.T, store BlogStore, blogs ...*models.Blog) {
+	t.Helper()
+	for _, blog := range blogs {
+		if err := store.CreateBlog(context.Background(), blog); err != nil {
+			t.Fatalf("CreateBlog(%q): %v", blog.Slug, err)
+		}
+	}
+}
+
+// blogSlugs 返回文章的 slug 列表，便于比较列表结果
+func blogSlugs(blogs []*models.Blog) []string {
+	slugs := make([]string, len(blogs))
+	for i, blog := range blogs {
+		slugs[i] = blog.Slug
+	}
+	return slugs
+}
+
+func testBlogStoreCreateAndGet(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	blog := newTestBlog(1, func(b *models.Blog) { b.Tags = []string{"Go"} })
+	mustCreateBlogs(t, store, blog)
+
+	got, err := store.GetBlogByID(ctx, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != blog.Title || got.Content != blog.Content || !got.PublishedAt.Equal(blog.PublishedAt) || !slices.Equal(got.Tags, blog.Tags) {
+		t.Errorf("GetBlogByID = %+v, want %+v", got, blog)
+	}
+
+	// 返回的文章是副本，修改它不影响存储
+	got.Tags[0] = "changed"
+	again, _ := store.GetBlogByID(ctx, blog.ID)
+	if again.Tags[0] != "Go" {
+		t.Errorf("修改返回值影响了存储: tags = %v", again.Tags)
+	}
+
+	if _, err := store.GetBlogByID(ctx, primitive.NewObjectID()); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("GetBlogByID(不存在) err = %v, want ErrBlogNotFound", err)
+	}
+}
+
+func testBlogStoreSlugs(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	a := newTestBlog(1, func(b *models.Blog) { b.Slug = "hello"; b.OldSlugs = []string{"hello-old"} })
+	b := newTestBlog(2, nil)
+	mustCreateBlogs(t, store, a, b)
+
+	if err := store.CreateBlog(ctx, newTestBlog(3, func(b *models.Blog) { b.Slug = "hello" })); !errors.Is(err, ErrSlugTaken) {
+		t.Errorf("CreateBlog(重复 slug) err = %v, want ErrSlugTaken", err)
+	}
+	taken := "hello"
+	if _, err := store.UpdateBlog(ctx, b.ID, BlogUpdate{Slug: &taken}); !errors.Is(err, ErrSlugTaken) {
+		t.Errorf("UpdateBlog(重复 slug) err = %v, want ErrSlugTaken", err)
+	}
+
+	tests := []struct {
+		slug string
+		want primitive.ObjectID
+		err  error
+	}{
+		{"hello", a.ID, nil},
+		{"hello-old", a.ID, nil},
+		{"post-2", b.ID, nil},
+		{"missing", primitive.NilObjectID, ErrBlogNotFound},
+	}
+	for _, tt := range tests {
+		got, err := store.GetBlogBySlug(ctx, tt.slug)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("GetBlogBySlug(%q) err = %v, want %v", tt.slug, err, tt.err)
+			continue
+		}
+		if err == nil && got.ID != tt.want {
+			t.Errorf("GetBlogBySlug(%q) = %s, want %s", tt.slug, got.ID.Hex(), tt.want.Hex())
+		}
+	}
+}
+
+func testBlogStoreListFilters(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	category := primitive.NewObjectID()
+	deletedAt := testEpoch
+	future := testEpoch.Add(100 * time.Hour)
+	past := testEpoch.Add(time.Hour + 30*time.Minute)
+	mustCreateBlogs(t, store,
+		newTestBlog(1, func(b *models.Blog) {
+			b.Tags = []string{"Go", "Web"}
+			b.SearchTerms = []string{"golang", "博客"}
+			b.CategoryID = &category
+			b.UnpublishAt = &past
+		}),
+		newTestBlog(2, func(b *models.Blog) { b.Tags = []string{"go"}; b.Author = "bob" }),
+		newTestBlog(3, func(b *models.Blog) { b.Show = false; b.SearchTerms = []string{"博客"} }),
+		newTestBlog(4, func(b *models.Blog) { b.DeletedAt = &deletedAt; b.Tags = []string{"Go"} }),
+		newTestBlog(5, func(b *models.Blog) { b.PublishAt = &future; b.PublishedAt = future }),
+	)
+
+	show := true
+	tests := []struct {
+		name  string
+		query BlogQuery
+		want  []string
+	}{
+		{"全部（不含回收站，按发布时间倒序）", BlogQuery{}, []string{"post-5", "post-3", "post-2", "post-1"}},
+		{"回收站", BlogQuery{Trashed: true}, []string{"post-4"}},
+		{"展示", BlogQuery{Show: &show}, []string{"post-5", "post-2", "post-1"}},
+		{"作者", BlogQuery{Author: "bob"}, []string{"post-2"}},
+		{"标签大小写不敏感", BlogQuery{Tags: []string{"GO"}}, []string{"post-2", "post-1"}},
+		{"任一标签", BlogQuery{Tags: []string{"web", "none"}}, []string{"post-1"}},
+		{"全部标签", BlogQuery{Tags: []string{"go", "web"}, TagMatchAll: true}, []string{"post-1"}},
+		{"分类", BlogQuery{CategoryIDs: []primitive.ObjectID{category}}, []string{"post-1"}},
+		{"检索词", BlogQuery{SearchTerms: []string{"博客"}}, []string{"post-3", "post-1"}},
+		{"检索词任一匹配", BlogQuery{SearchTerms: []string{"golang", "none"}}, []string{"post-1"}},
+		{"发布时间范围", BlogQuery{PublishedFrom: testEpoch.Add(2 * time.Hour), PublishedTo: testEpoch.Add(3 * time.Hour)}, []string{"post-3", "post-2"}},
+		{"发布窗口", BlogQuery{VisibleAt: testEpoch.Add(10 * time.Hour)}, []string{"post-3", "post-2"}},
+		{"发布窗口内尚未下线", BlogQuery{VisibleAt: testEpoch.Add(time.Hour)}, []string{"post-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogs, err := store.ListBlogs(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := blogSlugs(blogs); !slices.Equal(got, tt.want) {
+				t.Errorf("ListBlogs = %v, want %v", got, tt.want)
+			}
+			count, err := store.CountBlogs(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != int64(len(tt.want)) {
+				t.Errorf("CountBlogs = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func testBlogStorePagination(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	var blogs []*models.Blog
+	for n := 1; n <= 5; n++ {
+		blogs = append(blogs, newTestBlog(n, nil))
+	}
+	// 两篇文章发布时间相同，按ID倒序
+	same := newTestBlog(3, func(b *models.Blog) { b.Slug = "post-3b" })
+	blogs = append(blogs, same)
+	mustCreateBlogs(t, store, blogs...)
+
+	all, err := store.ListBlogs(ctx, BlogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := blogSlugs(all)
+	if want := []string{"post-5", "post-4", "post-3b", "post-3", "post-2", "post-1"}; !slices.Equal(order, want) {
+		t.Fatalf("ListBlogs = %v, want %v", order, want)
+	}
+
+	tests := []struct {
+		name  string
+		query BlogQuery
+		want  []string
+	}{
+		{"第一页", BlogQuery{Limit: 2}, order[:2]},
+		{"偏移", BlogQuery{Offset: 2, Limit: 2}, order[2:4]},
+		{"偏移超出", BlogQuery{Offset: 10, Limit: 2}, []string{}},
+		{"游标之后", BlogQuery{Cursor: newBlogCursor(all[2], false), Limit: 2}, order[3:5]},
+		{"游标之后到末尾", BlogQuery{Cursor: newBlogCursor(all[3], false), Limit: 10}, order[4:]},
+		{"游标之前", BlogQuery{Cursor: newBlogCursor(all[3], true), Limit: 2}, order[1:3]},
+		{"游标之前不足一页", BlogQuery{Cursor: newBlogCursor(all[1], true), Limit: 2}, order[:1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.ListBlogs(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := blogSlugs(page); !slices.Equal(got, tt.want) {
+				t.Errorf("ListBlogs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 统计总数时忽略分页参数
+	if count, _ := store.CountBlogs(ctx, BlogQuery{Offset: 2, Limit: 2}); count != 6 {
+		t.Errorf("CountBlogs = %d, want 6", count)
+	}
+}
+
+func testBlogStoreCountTags(t *testing.T, store BlogStore) {
+	mustCreateBlogs(t, store,
+		newTestBlog(1, func(b *models.Blog) { b.Tags = []string{"Go", "Web"} }),
+		newTestBlog(2, func(b *models.Blog) { b.Tags = []string{"go", "Rust"} }),
+		newTestBlog(3, func(b *models.Blog) { b.Tags = []string{"Web"}; b.Show = false }),
+	)
+	show := true
+	tests := []struct {
+		name  string
+		query BlogQuery
+		want  []string
+	}{
+		{"按次数倒序、名称正序", BlogQuery{}, []string{"go=2", "web=2", "rust=1"}},
+		{"过滤", BlogQuery{Show: &show}, []string{"go=2", "rust=1", "web=1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := store.CountTags(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(tags))
+			for i, tc := range tags {
+				got[i] = fmt.Sprintf("%s=%d", tagKey(tc.Tag), tc.Count)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CountTags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testBlogStoreSearchCandidates(t *testing.T, store BlogStore) {
+	withTerms := func(terms ...string) func(*models.Blog) {
+		return func(b *models.Blog) { b.SearchTerms = terms }
+	}
+	mustCreateBlogs(t, store,
+		newTestBlog(1, withTerms("go", "web")),
+		newTestBlog(2, withTerms("rust")),
+		newTestBlog(3, func(b *models.Blog) { b.SearchTerms = []string{"go", "rust", "web"}; b.Tags = []string{"Go"} }),
+		newTestBlog(4, withTerms("python")),
+		newTestBlog(5, func(b *models.Blog) { b.SearchTerms = []string{"go"}; b.Show = false }),
+	)
+	show := true
+	tests := []struct {
+		name  string
+		query BlogQuery
+		want  []string
+	}{
+		{"按发布时间倒序，只保留查询中的检索词", BlogQuery{SearchTerms: []string{"go", "rust"}},
+			[]string{"post-5:go", "post-3:go,rust", "post-2:rust", "post-1:go"}},
+		{"过滤", BlogQuery{Show: &show, SearchTerms: []string{"go"}}, []string{"post-3:go", "post-1:go"}},
+		{"数量上限", BlogQuery{SearchTerms: []string{"go", "rust"}, Limit: 2}, []string{"post-5:go", "post-3:go,rust"}},
+	}
+	slugs := map[primitive.ObjectID]string{}
+	all, err := store.ListBlogs(context.Background(), BlogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, blog := range all {
+		slugs[blog.ID] = blog.Slug
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, err := store.ListSearchCandidates(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(candidates))
+			for i, c := range candidates {
+				terms := slices.Clone(c.SearchTerms)
+				slices.Sort(terms)
+				got[i] = slugs[c.ID] + ":" + strings.Join(terms, ",")
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListSearchCandidates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	candidates, err := store.ListSearchCandidates(context.Background(), BlogQuery{SearchTerms: []string{"rust"}, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := candidates[0]; c.Title != "文章 3" || !slices.Equal(c.Tags, []string{"Go"}) || !c.PublishedAt.Equal(testEpoch.Add(3*time.Hour)) {
+		t.Errorf("候选文章字段 = %q %v %v", c.Title, c.Tags, c.PublishedAt)
+	}
+}
+
+func testBlogStoreUpdate(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	blog := newTestBlog(1, func(b *models.Blog) { b.Tags = []string{"Go"}; b.SearchTerms = []string{"old"} })
+	mustCreateBlogs(t, store, blog)
+
+	title := "新标题"
+	version := int64(1)
+	updated, err := store.UpdateBlog(ctx, blog.ID, BlogUpdate{
+		Title:       &title,
+		Tags:        []string{"Rust"},
+		SearchTerms: []string{"new"},
+		Version:     &version,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != title || updated.Version != 2 || !slices.Equal(updated.TagKeys, []string{"rust"}) {
+		t.Errorf("UpdateBlog = title %q version %d tag_keys %v", updated.Title, updated.Version, updated.TagKeys)
+	}
+	if updated.Content != blog.Content || !updated.UpdatedAt.Equal(blog.UpdatedAt) {
+		t.Errorf("UpdateBlog 修改了未指定的字段: %+v", updated)
+	}
+
+	// 检索词随更新重新索引
+	for term, want := range map[string]int64{"old": 0, "new": 1} {
+		if n, _ := store.CountBlogs(ctx, BlogQuery{SearchTerms: []string{term}}); n != want {
+			t.Errorf("CountBlogs(%q) = %d, want %d", term, n, want)
+		}
+	}
+
+	// 基于过期版本的更新被拒绝
+	if _, err := store.UpdateBlog(ctx, blog.ID, BlogUpdate{Title: &title, Version: &version}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("UpdateBlog(过期版本) err = %v, want ErrVersionConflict", err)
+	}
+	if _, err := store.UpdateBlog(ctx, primitive.NewObjectID(), BlogUpdate{Title: &title}); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("UpdateBlog(不存在) err = %v, want ErrBlogNotFound", err)
+	}
+
+	// 清除定时与分类
+	at := testEpoch.Add(time.Hour)
+	category := primitive.NewObjectID()
+	if _, err := store.UpdateBlog(ctx, blog.ID, BlogUpdate{PublishAt: &at, CategoryID: &category}); err != nil {
+		t.Fatal(err)
+	}
+	cleared, err := store.UpdateBlog(ctx, blog.ID, BlogUpdate{PublishAt: &time.Time{}, CategoryID: &primitive.NilObjectID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleared.PublishAt != nil || cleared.CategoryID != nil {
+		t.Errorf("UpdateBlog 未清除定时与分类: publish_at %v category_id %v", cleared.PublishAt, cleared.CategoryID)
+	}
+}
+
+func testBlogStoreCounters(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	a, b := newTestBlog(1, nil), newTestBlog(2, nil)
+	mustCreateBlogs(t, store, a, b)
+
+	err := store.IncrementViews(ctx, map[primitive.ObjectID]int64{a.ID: 3, b.ID: 1, primitive.NewObjectID(): 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.IncrementComments(ctx, a.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetReactions(ctx, a.ID, map[string]int64{"like": 1, "heart": 3}); err != nil {
+		t.Fatal(err)
+	}
+	for _, delta := range []int64{1, 1, -1} {
+		if err := store.IncrementReactions(ctx, a.ID, "like", delta); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, _ := store.GetBlogByID(ctx, a.ID)
+	if got.Views != 3 || got.CommentCount != 2 || got.Reactions["like"] != 2 || got.Reactions["heart"] != 3 {
+		t.Errorf("计数 = views %d comments %d reactions %v", got.Views, got.CommentCount, got.Reactions)
+	}
+	// SetReactions 整体替换，不保留未给出的种类
+	if err := store.SetReactions(ctx, a.ID, map[string]int64{"like": 5}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.GetBlogByID(ctx, a.ID); len(got.Reactions) != 1 || got.Reactions["like"] != 5 {
+		t.Errorf("SetReactions 后 reactions = %v", got.Reactions)
+	}
+	// 计数不修改版本号
+	if got.Version != 1 {
+		t.Errorf("Version = %d, want 1", got.Version)
+	}
+}
+
+func testBlogStoreTrash(t *testing.T, store BlogStore) {
+	ctx := context.Background()
+	a, b, c := newTestBlog(1, nil), newTestBlog(2, nil), newTestBlog(3, nil)
+	mustCreateBlogs(t, store, a, b, c)
+
+	stale := int64(0)
+	if err := store.TrashBlog(ctx, a.ID, testEpoch, &stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("TrashBlog(过期版本) err = %v, want ErrVersionConflict", err)
+	}
+	if err := store.DeleteBlog(ctx, a.ID); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("DeleteBlog(不在回收站) err = %v, want ErrBlogNotFound", err)
+	}
+	for _, blog := range []*models.Blog{a, b, c} {
+		if err := store.TrashBlog(ctx, blog.ID, blog.PublishedAt, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.TrashBlog(ctx, a.ID, testEpoch, nil); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("TrashBlog(已在回收站) err = %v, want ErrBlogNotFound", err)
+	}
+	if _, err := store.UpdateBlog(ctx, a.ID, BlogUpdate{}); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("UpdateBlog(回收站中) err = %v, want ErrBlogNotFound", err)
+	}
+
+	if err := store.RestoreBlog(ctx, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RestoreBlog(ctx, c.ID); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("RestoreBlog(不在回收站) err = %v, want ErrBlogNotFound", err)
+	}
+
+	// 只清理在 before 之前移入回收站的文章
+	purged, err := store.PurgeTrash(ctx, testEpoch.Add(90*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(purged, []primitive.ObjectID{a.ID}) {
+		t.Errorf("PurgeTrash = %v, want [%s]", purged, a.ID.Hex())
+	}
+	if err := store.DeleteBlog(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []primitive.ObjectID{a.ID, b.ID} {
+		if _, err := store.GetBlogByID(ctx, id); !errors.Is(err, ErrBlogNotFound) {
+			t.Errorf("GetBlogByID(已删除) err = %v, want ErrBlogNotFound", err)
+		}
+	}
+	if blogs, _ := store.ListBlogs(ctx, BlogQuery{}); !slices.Equal(blogSlugs(blogs), []string{"post-3"}) {
+		t.Errorf("ListBlogs = %v, want [post-3]", blogSlugs(blogs))
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrInvalidCategory 文章或父分类引用的分类不存在
+	ErrInvalidCategory = errors.New("指定的分类不存在")
+	// ErrCategoryCycle 不能将分类移动到自身或其子孙分类下
+	ErrCategoryCycle = errors.New("不能将分类移动到自身或其子孙分类下")
+	// ErrCategoryNotEmpty 按 reject 策略删除分类时，分类下仍有文章
+	ErrCategoryNotEmpty = errors.New("分类下仍有文章，请先移走文章或选择其他处理方式")
+	// ErrInvalidOrphanPolicy 未知的文章处理方式
+	ErrInvalidOrphanPolicy = errors.New("无效的 orphans 参数，可选值：parent、uncategorize、reject")
+)
+
+// OrphanPolicy 删除分类时对其下文章的处理方式，子分类总是移动到被删除分类的父分类下
+type OrphanPolicy string
+
+const (
+	// OrphanPolicyParent 文章改为属于父分类，删除的是顶级分类时变为未分类（默认）
+	OrphanPolicyParent OrphanPolicy = "parent"
+	// OrphanPolicyUncategorize 文章变为未分类
+	OrphanPolicyUncategorize OrphanPolicy = "uncategorize"
+	// OrphanPolicyReject 分类下有文章（包括回收站中的文章）时拒绝删除
+	OrphanPolicyReject OrphanPolicy = "reject"
+)
+
+// ParseOrphanPolicy 解析文章处理方式，空字符串表示默认的 OrphanPolicyParent
+func ParseOrphanPolicy(s string) (OrphanPolicy, error) {
+	switch policy := OrphanPolicy(s); policy {
+	case "":
+		return OrphanPolicyParent, nil
+	case OrphanPolicyParent, OrphanPolicyUncategorize, OrphanPolicyReject:
+		return policy, nil
+	default:
+		return "", ErrInvalidOrphanPolicy
+	}
+}
+
+// CategoryService 处理分类树的业务逻辑
+type CategoryService struct {
+	store       CategoryStore
+	blogService *BlogService
+}
+
+// NewCategoryService 创建新的CategoryService实例
+func NewCategoryService(store CategoryStore, blogService *BlogService) *CategoryService {
+	return &CategoryService{
+		store:       store,
+		blogService: blogService,
+	}
+}
+
+// Tree 获取分类树及每个分类的文章数，visibleOnly 为 true 时只统计前端可见的文章
+func (s *CategoryService) Tree(visibleOnly bool) ([]*models.CategoryNode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categories, err := s.store.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.blogService.categoryPostCounts(ctx, visibleOnly)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories, counts), nil
+}
+
+// ListVisibleCategoryBlogs 分页获取分类及其全部子孙分类下前端可见的文章
+func (s *CategoryService) ListVisibleCategoryBlogs(slug string, query BlogQuery, withTotal bool) (*models.Category, *BlogPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	category, err := s.store.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	categories, err := s.store.ListCategories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	query.CategoryIDs = descendantCategoryIDs(categories, category.ID)
+
+	page, err := s.blogService.ListVisibleBlogs(query, withTotal)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, page, nil
+}
+
+// CategoryInput 创建或更新分类的参数，更新时 nil 字段表示不修改
+type CategoryInput struct {
+	Name     *string
+	Slug     *string // 创建时为空则根据名称生成
+	ParentID *string // 父分类ID，空字符串表示顶级分类
+}
+
+// CreateCategory 创建新分类
+func (s *CategoryService) CreateCategory(input CategoryInput) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := primitive.NewObjectID()
+	category := &models.Category{ID: id}
+	if input.Name != nil {
+		category.Name = *input.Name
+	}
+
+	var err error
+	if input.Slug != nil && *input.Slug != "" {
+		if err = s.checkSlug(ctx, *input.Slug, id); err != nil {
+			return nil, err
+		}
+		category.Slug = *input.Slug
+	} else if category.Slug, err = s.uniqueSlug(ctx, Slugify(category.Name), id); err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != nil && *input.ParentID != "" {
+		parentID, err := s.blogService.categoryRef(ctx, *input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		category.ParentID = &parentID
+	}
+
+	now := time.Now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+	if err := s.store.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory 重命名或移动分类，移动时子孙分类与文章随之移动
+func (s *CategoryService) UpdateCategory(id string, input CategoryInput) (*models.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseCategoryID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	update := CategoryUpdate{Name: input.Name, UpdatedAt: time.Now()}
+	if input.Slug != nil {
+		if err := s.checkSlug(ctx, *input.Slug, objID); err != nil {
+			return nil, err
+		}
+		update.Slug = input.Slug
+	}
+	if input.ParentID != nil {
+		parentID := primitive.NilObjectID
+		if *input.ParentID != "" {
+			if parentID, err = s.blogService.categoryRef(ctx, *input.ParentID); err != nil {
+				return nil, err
+			}
+			categories, err := s.store.ListCategories(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, descendant := range descendantCategoryIDs(categories, objID) {
+				if descendant == parentID {
+					return nil, ErrCategoryCycle
+				}
+			}
+		}
+		update.ParentID = &parentID
+	}
+	return s.store.UpdateCategory(ctx, objID, update)
+}
+
+// DeleteCategory 删除分类：子分类移动到其父分类下，文章按 policy 处理
+func (s *CategoryService) DeleteCategory(id string, policy OrphanPolicy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseCategoryID(id)
+	if err != nil {
+		return err
+	}
+	category, err := s.store.GetCategoryByID(ctx, objID)
+	if err != nil {
+		return err
+	}
+
+	switch policy {
+	case OrphanPolicyReject:
+		hasPosts, err := s.blogService.categoryHasPosts(ctx, objID)
+		if err != nil {
+			return err
+		}
+		if hasPosts {
+			return ErrCategoryNotEmpty
+		}
+	case OrphanPolicyParent:
+		if err := s.blogService.reassignCategory(ctx, objID, category.ParentID); err != nil {
+			return err
+		}
+	case OrphanPolicyUncategorize:
+		if err := s.blogService.reassignCategory(ctx, objID, nil); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidOrphanPolicy
+	}
+
+	categories, err := s.store.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	newParent := primitive.NilObjectID
+	if category.ParentID != nil {
+		newParent = *category.ParentID
+	}
+	now := time.Now()
+	for _, child := range categories {
+		if child.ParentID == nil || *child.ParentID != objID {
+			continue
+		}
+		if _, err := s.store.UpdateCategory(ctx, child.ID, CategoryUpdate{ParentID: &newParent, UpdatedAt: now}); err != nil {
+			return err
+		}
+	}
+	return s.store.DeleteCategory(ctx, objID)
+}
+
+// uniqueSlug 基于 base 生成未被其他分类占用的 slug，冲突时追加 -2、-3…
+func (s *CategoryService) uniqueSlug(ctx context.Context, base string, self primitive.ObjectID) (string, error) {
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		existing, err := s.store.GetCategoryBySlug(ctx, candidate)
+		if errors.Is(err, ErrCategoryNotFound) || (err == nil && existing.ID == self) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// checkSlug 校验手动指定的 slug 格式，并确认未被其他分类占用
+func (s *CategoryService) checkSlug(ctx context.Context, slug string, self primitive.ObjectID) error {
+	if err := ValidateSlug(slug); err != nil {
+		return err
+	}
+	existing, err := s.store.GetCategoryBySlug(ctx, slug)
+	if errors.Is(err, ErrCategoryNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != self {
+		return ErrSlugTaken
+	}
+	return nil
+}
+
+// buildCategoryTree 组装分类树并汇总文章数，父分类不存在的分类作为顶级分类
+func buildCategoryTree(categories []*models.Category, counts map[primitive.ObjectID]int64) []*models.CategoryNode {
+	nodes := make(map[primitive.ObjectID]*models.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &models.CategoryNode{
+			Category:  *category,
+			PostCount: counts[category.ID],
+			Children:  []*models.CategoryNode{},
+		}
+	}
+
+	roots := []*models.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	var total func(node *models.CategoryNode) int64
+	total = func(node *models.CategoryNode) int64 {
+		node.TotalCount = node.PostCount
+		for _, child := range node.Children {
+			node.TotalCount += total(child)
+		}
+		return node.TotalCount
+	}
+	for _, root := range roots {
+		total(root)
+	}
+	return roots
+}
+
+// descendantCategoryIDs 返回分类自身及其全部子孙分类的ID
+func descendantCategoryIDs(categories []*models.Category, id primitive.ObjectID) []primitive.ObjectID {
+	children := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []primitive.ObjectID{id}
+	seen := map[primitive.ObjectID]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// categoryPostCounts 统计直接属于每个分类的文章数（不含回收站），visibleOnly 为 true 时只统计前端可见的文章
+func (s *BlogService) categoryPostCounts(ctx context.Context, visibleOnly bool) (map[primitive.ObjectID]int64, error) {
+	var query BlogQuery
+	if visibleOnly {
+		visible := true
+		query.Show = &visible
+		query.VisibleAt = time.Now()
+	}
+	return s.store.CountCategories(ctx, query)
+}
+
+// categoryHasPosts 判断是否有文章（包括回收站中的文章）直接属于该分类
+func (s *BlogService) categoryHasPosts(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	for _, trashed := range []bool{false, true} {
+		n, err := s.store.CountBlogs(ctx, BlogQuery{CategoryIDs: []primitive.ObjectID{id}, Trashed: trashed})
+		if err != nil || n > 0 {
+			return n > 0, err
+		}
+	}
+	return false, nil
+}
+
+// reassignCategory 将直接属于 from 的文章改为属于 to，to 为 nil 时变为未分类
+func (s *BlogService) reassignCategory(ctx context.Context, from primitive.ObjectID, to *primitive.ObjectID) error {
+	if _, err := s.store.ReassignCategory(ctx, []primitive.ObjectID{from}, to); err != nil {
+		return err
+	}
+	s.counts.invalidate()
+	s.related.recategorize(from, to)
+	return nil
+}
+
+// categoryRef 解析文章引用的分类ID并确认分类存在，空字符串返回 NilObjectID（未分类）
+func (s *BlogService) categoryRef(ctx context.Context, id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, nil
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidCategory
+	}
+	if _, err := s.categories.GetCategoryByID(ctx, objID); err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return primitive.NilObjectID, ErrInvalidCategory
+		}
+		return primitive.NilObjectID, err
+	}
+	return objID, nil
+}
+
+// parseCategoryID 解析分类ID，格式错误的ID视为分类不存在
+func parseCategoryID(id string) (primitive.ObjectID, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrCategoryNotFound
+	}
+	return objID, nil
+}
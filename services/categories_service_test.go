@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testCategorySite 分类树：技术 > Go > 并发，以及 生活
+// 文章：技术、Go、并发下各一篇已发布的文章，Go 下一篇草稿，生活下一篇回收站中的文章
+type testCategorySite struct {
+	blogService     *BlogService
+	categoryService *CategoryService
+	categories      map[string]string // slug → ID
+	posts           map[string]string // 标题 → ID
+}
+
+func newTestCategorySite(t *testing.T) *testCategorySite {
+	t.Helper()
+	blogService := newTestBlogService()
+	site := &testCategorySite{
+		blogService:     blogService,
+		categoryService: NewCategoryService(blogService.categories, blogService),
+		categories:      map[string]string{},
+		posts:           map[string]string{},
+	}
+	for _, c := range []struct{ name, slug, parent string }{
+		{"技术", "tech", ""},
+		{"Go", "go", "tech"},
+		{"并发", "concurrency", "go"},
+		{"生活", "life", ""},
+	} {
+		name, slug, parent := c.name, c.slug, site.categories[c.parent]
+		category, err := site.categoryService.CreateCategory(CategoryInput{Name: &name, Slug: &slug, ParentID: &parent})
+		if err != nil {
+			t.Fatal(err)
+		}
+		site.categories[slug] = category.ID.Hex()
+	}
+	for _, p := range []struct {
+		title, category string
+		show            bool
+	}{
+		{"技术文章", "tech", true},
+		{"Go 文章", "go", true},
+		{"并发文章", "concurrency", true},
+		{"Go 草稿", "go", false},
+		{"生活文章", "life", true},
+	} {
+		blog, err := blogService.CreateBlog(CreateBlogInput{
+			Title: p.title, Content: "正文", Author: "alice", Show: p.show, CategoryID: site.categories[p.category],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		site.posts[p.title] = blog.ID.Hex()
+	}
+	if err := blogService.DeleteBlog(site.posts["生活文章"], nil); err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+// tree 以 slug=直接文章数/总数 的缩进文本表示分类树
+func (s *testCategorySite) tree(t *testing.T, visibleOnly bool) string {
+	t.Helper()
+	nodes, err := s.categoryService.Tree(visibleOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	var walk func(nodes []*models.CategoryNode, depth int)
+	walk = func(nodes []*models.CategoryNode, depth int) {
+		for _, node := range nodes {
+			lines = append(lines, fmt.Sprintf("%s%s=%d/%d", strings.Repeat("  ", depth), node.Slug, node.PostCount, node.TotalCount))
+			walk(node.Children, depth+1)
+		}
+	}
+	walk(nodes, 0)
+	return strings.Join(lines, "\n")
+}
+
+// category 返回文章所属分类的 slug，未分类时为空
+func (s *testCategorySite) category(t *testing.T, title string) string {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(s.posts[title])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 直接读取存储，回收站中的文章同样可以检查
+	blog, err := s.blogService.store.GetBlogByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blog.CategoryID == nil {
+		return ""
+	}
+	for slug, id := range s.categories {
+		if id == blog.CategoryID.Hex() {
+			return slug
+		}
+	}
+	return blog.CategoryID.Hex()
+}
+
+func TestCategoryTreeCounts(t *testing.T) {
+	site := newTestCategorySite(t)
+
+	// 分类按名称排序；总数包含子孙分类，回收站中的文章不计入
+	tests := []struct {
+		name        string
+		visibleOnly bool
+		want        string
+	}{
+		{"只统计可见文章", true, "tech=1/3\n  go=1/2\n    concurrency=1/1\nlife=0/0"},
+		{"包含草稿", false, "tech=1/4\n  go=2/3\n    concurrency=1/1\nlife=0/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := site.tree(t, tt.visibleOnly); got != tt.want {
+				t.Errorf("Tree =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	_, page, err := site.categoryService.ListVisibleCategoryBlogs("tech", BlogQuery{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, blog := range page.Blogs {
+		titles = append(titles, blog.Title)
+	}
+	if want := []string{"并发文章", "Go 文章", "技术文章"}; !slices.Equal(titles, want) || *page.Total != 3 {
+		t.Errorf("技术分类下的文章 = %q, want %q", titles, want)
+	}
+	if _, _, err := site.categoryService.ListVisibleCategoryBlogs("missing", BlogQuery{}, false); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("不存在的分类 err = %v, want ErrCategoryNotFound", err)
+	}
+}
+
+func TestMoveCategory(t *testing.T) {
+	site := newTestCategorySite(t)
+
+	// 移动到自身或子孙分类下形成环
+	for _, parent := range []string{"tech", "go", "concurrency"} {
+		parentID := site.categories[parent]
+		if _, err := site.categoryService.UpdateCategory(site.categories["tech"], CategoryInput{ParentID: &parentID}); !errors.Is(err, ErrCategoryCycle) {
+			t.Errorf("移动到 %s 下 err = %v, want ErrCategoryCycle", parent, err)
+		}
+	}
+
+	// 移动后子孙分类与文章随之移动
+	lifeID := site.categories["life"]
+	if _, err := site.categoryService.UpdateCategory(site.categories["go"], CategoryInput{ParentID: &lifeID}); err != nil {
+		t.Fatal(err)
+	}
+	want := "tech=1/1\nlife=0/2\n  go=1/2\n    concurrency=1/1"
+	if got := site.tree(t, true); got != want {
+		t.Errorf("Tree =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDeleteCategoryOrphanPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		policy   OrphanPolicy
+		err      error
+		tree     string
+		post     string // 检查所属分类的文章
+		moved    string // 删除后该文章所属的分类，空字符串表示未分类
+	}{
+		{"文章移到父分类", "go", OrphanPolicyParent, nil,
+			"tech=3/4\n  concurrency=1/1\nlife=0/0", "Go 文章", "tech"},
+		{"文章变为未分类", "go", OrphanPolicyUncategorize, nil,
+			"tech=1/2\n  concurrency=1/1\nlife=0/0", "Go 文章", ""},
+		{"删除顶级分类时文章变为未分类", "tech", OrphanPolicyParent, nil,
+			"go=2/3\n  concurrency=1/1\nlife=0/0", "技术文章", ""},
+		{"有文章时拒绝删除", "go", OrphanPolicyReject, ErrCategoryNotEmpty,
+			"tech=1/4\n  go=2/3\n    concurrency=1/1\nlife=0/0", "Go 文章", "go"},
+		{"回收站中的文章同样阻止删除", "life", OrphanPolicyReject, ErrCategoryNotEmpty,
+			"tech=1/4\n  go=2/3\n    concurrency=1/1\nlife=0/0", "生活文章", "life"},
+		{"无效的处理方式", "go", OrphanPolicy("delete"), ErrInvalidOrphanPolicy,
+			"tech=1/4\n  go=2/3\n    concurrency=1/1\nlife=0/0", "Go 文章", "go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := newTestCategorySite(t)
+			err := site.categoryService.DeleteCategory(site.categories[tt.category], tt.policy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got := site.tree(t, false); got != tt.tree {
+				t.Errorf("Tree =\n%s\nwant\n%s", got, tt.tree)
+			}
+			if got := site.category(t, tt.post); got != tt.moved {
+				t.Errorf("%s 的分类 = %q, want %q", tt.post, got, tt.moved)
+			}
+		})
+	}
+}
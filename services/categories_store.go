@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCategoryNotFound 分类不存在
+var ErrCategoryNotFound = errors.New("分类不存在")
+
+// CategoryUpdate 分类的部分更新字段，nil 表示不修改
+type CategoryUpdate struct {
+	Name      *string
+	Slug      *string
+	ParentID  *primitive.ObjectID // 指向 NilObjectID 表示移动为顶级分类
+	UpdatedAt time.Time
+}
+
+// CategoryStore 分类的存储接口
+type CategoryStore interface {
+	// CreateCategory 保存新分类，category.ID 由调用方生成，slug 冲突时返回 ErrSlugTaken
+	CreateCategory(ctx context.Context, category *models.Category) error
+	// GetCategoryByID 根据ID获取分类，不存在时返回 ErrCategoryNotFound
+	GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*models.Category, error)
+	// GetCategoryBySlug 根据 slug 获取分类，不存在时返回 ErrCategoryNotFound
+	GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error)
+	// ListCategories 按名称排序获取全部分类
+	ListCategories(ctx context.Context) ([]*models.Category, error)
+	// UpdateCategory 更新分类并返回更新后的文档，不存在时返回 ErrCategoryNotFound，slug 冲突时返回 ErrSlugTaken
+	UpdateCategory(ctx context.Context, id primitive.ObjectID, update CategoryUpdate) (*models.Category, error)
+	// DeleteCategory 删除分类（不处理子分类与文章），不存在时返回 ErrCategoryNotFound
+	DeleteCategory(ctx context.Context, id primitive.ObjectID) error
+}
+
+// MongoCategoryStore 基于 MongoDB 的分类存储
+type MongoCategoryStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCategoryStore 创建新的MongoCategoryStore实例
+func NewMongoCategoryStore(client *mongo.Client, dbName, collectionName string) *MongoCategoryStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoCategoryStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建 slug 唯一索引与父分类索引
+func (s *MongoCategoryStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+	})
+	return err
+}
+
+// CreateCategory 保存新分类
+func (s *MongoCategoryStore) CreateCategory(ctx context.Context, category *models.Category) error {
+	_, err := s.collection.InsertOne(ctx, category)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSlugTaken
+	}
+	return err
+}
+
+// GetCategoryByID 根据ID获取分类
+func (s *MongoCategoryStore) GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*models.Category, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetCategoryBySlug 根据 slug 获取分类
+func (s *MongoCategoryStore) GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	return s.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (s *MongoCategoryStore) findOne(ctx context.Context, filter bson.M) (*models.Category, error) {
+	var category models.Category
+	err := s.collection.FindOne(ctx, filter).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListCategories 按名称排序获取全部分类
+func (s *MongoCategoryStore) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []*models.Category{}
+	if err = cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateCategory 更新分类
+func (s *MongoCategoryStore) UpdateCategory(ctx context.Context, id primitive.ObjectID, update CategoryUpdate) (*models.Category, error) {
+	setFields := bson.M{"updated_at": update.UpdatedAt}
+	if update.Name != nil {
+		setFields["name"] = *update.Name
+	}
+	if update.Slug != nil {
+		setFields["slug"] = *update.Slug
+	}
+	change := bson.M{"$set": setFields}
+	if update.ParentID != nil {
+		if update.ParentID.IsZero() {
+			change["$unset"] = bson.M{"parent_id": ""}
+		} else {
+			setFields["parent_id"] = *update.ParentID
+		}
+	}
+
+	var category models.Category
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, change,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&category)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// DeleteCategory 删除分类
+func (s *MongoCategoryStore) DeleteCategory(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// MemoryCategoryStore 基于内存的分类存储，用于本地运行与单元测试
+type MemoryCategoryStore struct {
+	mu         sync.RWMutex
+	categories map[primitive.ObjectID]*models.Category
+}
+
+// NewMemoryCategoryStore 创建新的MemoryCategoryStore实例
+func NewMemoryCategoryStore() *MemoryCategoryStore {
+	return &MemoryCategoryStore{
+		categories: make(map[primitive.ObjectID]*models.Category),
+	}
+}
+
+// CreateCategory 保存新分类
+func (s *MemoryCategoryStore) CreateCategory(_ context.Context, category *models.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.slugTaken(category.Slug, category.ID) {
+		return ErrSlugTaken
+	}
+	s.categories[category.ID] = cloneCategory(category)
+	return nil
+}
+
+// GetCategoryByID 根据ID获取分类
+func (s *MemoryCategoryStore) GetCategoryByID(_ context.Context, id primitive.ObjectID) (*models.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	category, ok := s.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	return cloneCategory(category), nil
+}
+
+// GetCategoryBySlug 根据 slug 获取分类
+func (s *MemoryCategoryStore) GetCategoryBySlug(_ context.Context, slug string) (*models.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, category := range s.categories {
+		if category.Slug == slug {
+			return cloneCategory(category), nil
+		}
+	}
+	return nil, ErrCategoryNotFound
+}
+
+// ListCategories 按名称排序获取全部分类
+func (s *MemoryCategoryStore) ListCategories(_ context.Context) ([]*models.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]*models.Category, 0, len(s.categories))
+	for _, category := range s.categories {
+		list = append(list, cloneCategory(category))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].ID.Hex() < list[j].ID.Hex()
+	})
+	return list, nil
+}
+
+// UpdateCategory 更新分类
+func (s *MemoryCategoryStore) UpdateCategory(_ context.Context, id primitive.ObjectID, update CategoryUpdate) (*models.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category, ok := s.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	if update.Slug != nil && s.slugTaken(*update.Slug, id) {
+		return nil, ErrSlugTaken
+	}
+
+	category.UpdatedAt = update.UpdatedAt
+	if update.Name != nil {
+		category.Name = *update.Name
+	}
+	if update.Slug != nil {
+		category.Slug = *update.Slug
+	}
+	if update.ParentID != nil {
+		category.ParentID = optionalID(*update.ParentID)
+	}
+	return cloneCategory(category), nil
+}
+
+// DeleteCategory 删除分类
+func (s *MemoryCategoryStore) DeleteCategory(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[id]; !ok {
+		return ErrCategoryNotFound
+	}
+	delete(s.categories, id)
+	return nil
+}
+
+// slugTaken 判断 slug 是否已被其他分类使用，调用方需持有锁
+func (s *MemoryCategoryStore) slugTaken(slug string, self primitive.ObjectID) bool {
+	for id, category := range s.categories {
+		if id != self && category.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneCategory 复制分类，避免调用方修改存储内部的数据
+func cloneCategory(category *models.Category) *models.Category {
+	c := *category
+	if category.ParentID != nil {
+		parentID := *category.ParentID
+		c.ParentID = &parentID
+	}
+	return &c
+}
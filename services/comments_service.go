@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxCommentLength 评论内容的最大字数
+	MaxCommentLength = 5000
+	// maxCommenterNameLength 匿名评论昵称的最大字数
+	maxCommenterNameLength = 50
+)
+
+var (
+	// ErrCommentsDisabled 文章已关闭评论
+	ErrCommentsDisabled = errors.New("该文章已关闭评论")
+	// ErrInvalidComment 评论内容为空或过长
+	ErrInvalidComment = errors.New("评论内容不能为空，且不能超过 5000 字")
+	// ErrInvalidCommenter 匿名评论的昵称、邮箱或网站无效
+	ErrInvalidCommenter = errors.New("昵称不能为空且不能超过 50 字，邮箱与网站须为有效地址")
+	// ErrInvalidReply 回复的评论不存在、不属于同一文章或未通过审核
+	ErrInvalidReply = errors.New("只能回复同一文章下已通过审核的评论")
+	// ErrInvalidCommentStatus 未知的评论状态
+	ErrInvalidCommentStatus = errors.New("无效的评论状态，可选值：pending、approved、spam、deleted")
+)
+
+// ParseCommentStatus 解析评论状态
+func ParseCommentStatus(value string) (models.CommentStatus, error) {
+	switch status := models.CommentStatus(value); status {
+	case models.CommentPending, models.CommentApproved, models.CommentSpam, models.CommentDeleted:
+		return status, nil
+	default:
+		return "", ErrInvalidCommentStatus
+	}
+}
+
+// CommentService 处理评论的发表、展示与审核
+type CommentService struct {
+	store       CommentStore
+	blogService *BlogService
+	moderate    bool
+}
+
+// NewCommentService 创建新的CommentService实例
+// moderate 为 true 时匿名评论需审核后才展示，登录用户的评论总是直接通过
+func NewCommentService(store CommentStore, blogService *BlogService, moderate bool) *CommentService {
+	return &CommentService{
+		store:       store,
+		blogService: blogService,
+		moderate:    moderate,
+	}
+}
+
+// CommentInput 发表评论的参数
+type CommentInput struct {
+	ParentID string // 回复的评论ID，为空表示直接评论文章
+
+	UserID   string // 登录用户发表时设置，此时以 Username 作为昵称，忽略 Name、Email 与 Website
+	Username string
+
+	Name    string
+	Email   string
+	Website string
+	Content string
+
+	IP        string
+	UserAgent string
+}
+
+// PostComment 对前端可见的文章发表评论
+func (s *CommentService) PostComment(postID string, input CommentInput) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blog, err := s.blogService.GetVisibleBlogByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if blog.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	content := strings.TrimSpace(input.Content)
+	if content == "" || utf8.RuneCountInString(content) > MaxCommentLength {
+		return nil, ErrInvalidComment
+	}
+
+	now := time.Now()
+	comment := &models.Comment{
+		ID:          primitive.NewObjectID(),
+		PostID:      blog.ID,
+		Content:     content,
+		ContentHTML: RenderCommentMarkdown(content),
+		Status:      models.CommentApproved,
+		IP:          input.IP,
+		UserAgent:   input.UserAgent,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if input.UserID != "" {
+		comment.UserID = input.UserID
+		comment.AuthorName = input.Username
+	} else {
+		if err := fillCommenter(comment, input); err != nil {
+			return nil, err
+		}
+		if s.moderate {
+			comment.Status = models.CommentPending
+		}
+	}
+
+	if input.ParentID != "" {
+		parentID, err := primitive.ObjectIDFromHex(input.ParentID)
+		if err != nil {
+			return nil, ErrInvalidReply
+		}
+		parent, err := s.store.GetCommentByID(ctx, parentID)
+		if errors.Is(err, ErrCommentNotFound) {
+			return nil, ErrInvalidReply
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.PostID != blog.ID || parent.Status != models.CommentApproved {
+			return nil, ErrInvalidReply
+		}
+		comment.ParentID = &parentID
+	}
+
+	if err := s.store.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	if comment.Status == models.CommentApproved {
+		if err := s.blogService.store.IncrementComments(ctx, blog.ID, 1); err != nil {
+			return nil, err
+		}
+	}
+	return comment, nil
+}
+
+// fillCommenter 校验并填写匿名评论者的信息
+func fillCommenter(comment *models.Comment, input CommentInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxCommenterNameLength {
+		return ErrInvalidCommenter
+	}
+	comment.AuthorName = name
+
+	if email := strings.TrimSpace(input.Email); email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return ErrInvalidCommenter
+		}
+		comment.AuthorEmail = email
+	}
+
+	if website := strings.TrimSpace(input.Website); website != "" {
+		u, err := url.Parse(website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidCommenter
+		}
+		comment.AuthorURL = u.String()
+	}
+	return nil
+}
+
+// VisibleComments 获取前端可见文章的评论树，只包含已通过的评论；
+// 未通过（待审核、垃圾或已删除）的评论下有已通过的回复时保留为占位节点，由调用方隐藏其内容
+func (s *CommentService) VisibleComments(postID string) ([]*models.CommentNode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blog, err := s.blogService.GetVisibleBlogByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.store.ListComments(ctx, CommentQuery{PostID: &blog.ID, Oldest: true})
+	if err != nil {
+		return nil, err
+	}
+	return pruneCommentTree(buildCommentTree(comments)), nil
+}
+
+// buildCommentTree 按 ParentID 组织回复树，comments 须按发表时间正序排列
+func buildCommentTree(comments []*models.Comment) []*models.CommentNode {
+	nodes := make(map[primitive.ObjectID]*models.CommentNode, len(comments))
+	for _, c := range comments {
+		nodes[c.ID] = &models.CommentNode{Comment: *c, Replies: []*models.CommentNode{}}
+	}
+
+	roots := []*models.CommentNode{}
+	for _, c := range comments {
+		node := nodes[c.ID]
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// pruneCommentTree 移除下面没有已通过回复的未通过评论
+func pruneCommentTree(nodes []*models.CommentNode) []*models.CommentNode {
+	kept := nodes[:0]
+	for _, node := range nodes {
+		node.Replies = pruneCommentTree(node.Replies)
+		if node.Status == models.CommentApproved || len(node.Replies) > 0 {
+			kept = append(kept, node)
+		}
+	}
+	return kept
+}
+
+// ListComments 后台按发表时间倒序分页获取评论，statuses 为空表示不限状态，postID 为空表示全部文章
+func (s *CommentService) ListComments(statuses []models.CommentStatus, postID string, page, limit int64) ([]*models.Comment, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := CommentQuery{Statuses: statuses, Offset: (page - 1) * limit, Limit: limit}
+	if postID != "" {
+		id, err := parseBlogID(postID)
+		if err != nil {
+			return nil, 0, err
+		}
+		query.PostID = &id
+	}
+
+	comments, err := s.store.ListComments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.store.CountComments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// CommentEdit 后台修改评论的参数，nil 表示不修改
+type CommentEdit struct {
+	Status  *models.CommentStatus
+	Content *string
+}
+
+// UpdateComment 审核或修改评论，并相应调整文章的评论数
+func (s *CommentService) UpdateComment(id string, edit CommentEdit) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrCommentNotFound
+	}
+
+	update := CommentUpdate{Status: edit.Status, UpdatedAt: time.Now()}
+	if edit.Content != nil {
+		content := strings.TrimSpace(*edit.Content)
+		if content == "" || utf8.RuneCountInString(content) > MaxCommentLength {
+			return nil, ErrInvalidComment
+		}
+		html := RenderCommentMarkdown(content)
+		update.Content = &content
+		update.ContentHTML = &html
+	}
+
+	before, err := s.store.UpdateComment(ctx, objID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	after := cloneComment(before)
+	after.UpdatedAt = update.UpdatedAt
+	if update.Status != nil {
+		after.Status = *update.Status
+	}
+	if update.Content != nil {
+		after.Content = *update.Content
+		after.ContentHTML = *update.ContentHTML
+	}
+
+	// 更新前的文档由同一次原子操作返回，并发审核同一条评论也不会重复计数
+	delta := approvedCount(after) - approvedCount(before)
+	if delta != 0 {
+		if err := s.blogService.store.IncrementComments(ctx, after.PostID, delta); err != nil {
+			return nil, err
+		}
+	}
+	return after, nil
+}
+
+// DeleteComment 将评论标记为已删除，其下已通过的回复仍然展示
+func (s *CommentService) DeleteComment(id string) error {
+	deleted := models.CommentDeleted
+	_, err := s.UpdateComment(id, CommentEdit{Status: &deleted})
+	return err
+}
+
+func approvedCount(comment *models.Comment) int64 {
+	if comment.Status == models.CommentApproved {
+		return 1
+	}
+	return 0
+}
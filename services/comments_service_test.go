@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"blog/models"
+)
+
+func newTestCommentService(moderate bool) (*CommentService, *BlogService) {
+	blogService := newTestBlogService()
+	return NewCommentService(blogService.comments, blogService, moderate), blogService
+}
+
+func TestCommentCount(t *testing.T) {
+	ctx := context.Background()
+	commentService, blogService := newTestCommentService(true)
+	blog, err := blogService.CreateBlog(CreateBlogInput{Title: "评论", Content: "正文", Author: "alice", Show: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID := blog.ID.Hex()
+
+	var anonymous, member *models.Comment
+	status := func(s models.CommentStatus) *models.CommentStatus { return &s }
+
+	tests := []struct {
+		name string
+		run  func() error
+		want int64
+	}{
+		{"匿名评论待审核", func() (err error) {
+			anonymous, err = commentService.PostComment(postID, CommentInput{Name: "bob", Content: "匿名评论"})
+			return err
+		}, 0},
+		{"登录用户评论直接通过", func() (err error) {
+			member, err = commentService.PostComment(postID, CommentInput{UserID: "u1", Username: "alice", Content: "作者回复"})
+			return err
+		}, 1},
+		{"审核通过", func() error {
+			_, err := commentService.UpdateComment(anonymous.ID.Hex(), CommentEdit{Status: status(models.CommentApproved)})
+			return err
+		}, 2},
+		{"重复审核通过不重复计数", func() error {
+			_, err := commentService.UpdateComment(anonymous.ID.Hex(), CommentEdit{Status: status(models.CommentApproved)})
+			return err
+		}, 2},
+		{"只修改内容", func() error {
+			content := "修改后的评论"
+			_, err := commentService.UpdateComment(anonymous.ID.Hex(), CommentEdit{Content: &content})
+			return err
+		}, 2},
+		{"标记为垃圾评论", func() error {
+			_, err := commentService.UpdateComment(anonymous.ID.Hex(), CommentEdit{Status: status(models.CommentSpam)})
+			return err
+		}, 1},
+		{"退回待审核", func() error {
+			_, err := commentService.UpdateComment(member.ID.Hex(), CommentEdit{Status: status(models.CommentPending)})
+			return err
+		}, 0},
+		{"再次通过", func() error {
+			_, err := commentService.UpdateComment(member.ID.Hex(), CommentEdit{Status: status(models.CommentApproved)})
+			return err
+		}, 1},
+		{"删除评论", func() error { return commentService.DeleteComment(member.ID.Hex()) }, 0},
+		{"删除待审核的评论", func() error { return commentService.DeleteComment(anonymous.ID.Hex()) }, 0},
+	}
+	for _, tt := range tests {
+		if err := tt.run(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got, err := blogService.store.GetBlogByID(ctx, blog.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.CommentCount != tt.want {
+			t.Errorf("%s: 评论数 = %d, want %d", tt.name, got.CommentCount, tt.want)
+		}
+	}
+}
+
+func TestPostCommentRejected(t *testing.T) {
+	ctx := context.Background()
+	commentService, blogService := newTestCommentService(false)
+	blog, err := blogService.CreateBlog(CreateBlogInput{Title: "评论", Content: "正文", Author: "alice", Show: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := blogService.CreateBlog(CreateBlogInput{Title: "其他文章", Content: "正文", Author: "alice", Show: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed, err := blogService.CreateBlog(CreateBlogInput{Title: "关闭评论", Content: "正文", Author: "alice", Show: true, CommentsDisabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID := blog.ID.Hex()
+
+	approved, err := commentService.PostComment(postID, CommentInput{Name: "bob", Content: "评论"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != models.CommentApproved {
+		t.Fatalf("不需审核时评论状态 = %s", approved.Status)
+	}
+
+	tests := []struct {
+		name   string
+		postID string
+		input  CommentInput
+		want   error
+	}{
+		{"内容为空", postID, CommentInput{Name: "bob", Content: "  "}, ErrInvalidComment},
+		{"没有昵称", postID, CommentInput{Content: "评论"}, ErrInvalidCommenter},
+		{"邮箱无效", postID, CommentInput{Name: "bob", Email: "bob", Content: "评论"}, ErrInvalidCommenter},
+		{"网站不是 http 地址", postID, CommentInput{Name: "bob", Website: "javascript:alert(1)", Content: "评论"}, ErrInvalidCommenter},
+		{"回复不存在的评论", postID, CommentInput{ParentID: "000000000000000000000000", Name: "bob", Content: "评论"}, ErrInvalidReply},
+		{"回复其他文章的评论", other.ID.Hex(), CommentInput{ParentID: approved.ID.Hex(), Name: "bob", Content: "评论"}, ErrInvalidReply},
+		{"文章已关闭评论", closed.ID.Hex(), CommentInput{Name: "bob", Content: "评论"}, ErrCommentsDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := commentService.PostComment(tt.postID, tt.input); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	got, err := blogService.store.GetBlogByID(ctx, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CommentCount != 1 {
+		t.Errorf("被拒绝的评论不应计数，评论数 = %d", got.CommentCount)
+	}
+}
+
+func TestVisibleCommentsPrunesUnapproved(t *testing.T) {
+	commentService, blogService := newTestCommentService(true)
+	blog, err := blogService.CreateBlog(CreateBlogInput{Title: "评论", Content: "正文", Author: "alice", Show: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID := blog.ID.Hex()
+	post := func(parent *models.Comment, content string, member bool) *models.Comment {
+		t.Helper()
+		input := CommentInput{Name: "bob", Content: content}
+		if member {
+			input = CommentInput{UserID: "u1", Username: "alice", Content: content}
+		}
+		if parent != nil {
+			input.ParentID = parent.ID.Hex()
+		}
+		comment, err := commentService.PostComment(postID, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return comment
+	}
+
+	// 已删除的评论下有已通过的回复，保留为占位节点
+	deleted := post(nil, "已删除", true)
+	post(deleted, "已删除评论的回复", true)
+	// 已通过的评论下只有待审核的回复
+	approved := post(nil, "已通过", true)
+	post(approved, "待审核的回复", false)
+	// 已删除的回复下的回复也已删除，整个分支被移除
+	thread := post(nil, "讨论", true)
+	branch := post(thread, "已删除的回复", true)
+	leaf := post(branch, "已删除的回复的回复", true)
+	// 待审核的评论没有回复
+	post(nil, "待审核", false)
+
+	for _, c := range []string{deleted.ID.Hex(), branch.ID.Hex(), leaf.ID.Hex()} {
+		if err := commentService.DeleteComment(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var render func(nodes []*models.CommentNode) []string
+	render = func(nodes []*models.CommentNode) []string {
+		var out []string
+		for _, node := range nodes {
+			out = append(out, string(node.Status)+":"+node.Content)
+			for _, reply := range render(node.Replies) {
+				out = append(out, "  "+reply)
+			}
+		}
+		return out
+	}
+	nodes, err := commentService.VisibleComments(postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(render(nodes), "\n")
+	want := strings.Join([]string{
+		"deleted:已删除",
+		"  approved:已删除评论的回复",
+		"approved:已通过",
+		"approved:讨论",
+	}, "\n")
+	if got != want {
+		t.Errorf("评论树 =\n%s\nwant\n%s", got, want)
+	}
+
+	stored, err := blogService.GetBlogByID(postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.CommentCount != 3 {
+		t.Errorf("评论数 = %d, want 3", stored.CommentCount)
+	}
+}
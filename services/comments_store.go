@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCommentNotFound 评论不存在
+var ErrCommentNotFound = errors.New("评论不存在")
+
+// CommentQuery 评论的查询条件
+type CommentQuery struct {
+	PostID   *primitive.ObjectID    // 只返回该文章的评论
+	Statuses []models.CommentStatus // 只返回处于这些状态的评论，为空表示不限
+	Oldest   bool                   // 为 true 时按发表时间正序，否则倒序
+	Offset   int64
+	Limit    int64 // <= 0 表示不分页
+}
+
+// CommentUpdate 评论的部分更新字段，nil 表示不修改
+type CommentUpdate struct {
+	Status      *models.CommentStatus
+	Content     *string
+	ContentHTML *string
+	UpdatedAt   time.Time
+}
+
+// CommentStore 评论的存储接口
+type CommentStore interface {
+	// CreateComment 保存新评论，comment.ID 由调用方生成
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	// GetCommentByID 根据ID获取评论，不存在时返回 ErrCommentNotFound
+	GetCommentByID(ctx context.Context, id primitive.ObjectID) (*models.Comment, error)
+	// ListComments 按发表时间排序分页获取评论
+	ListComments(ctx context.Context, query CommentQuery) ([]*models.Comment, error)
+	// CountComments 统计符合条件的评论数，忽略分页参数
+	CountComments(ctx context.Context, query CommentQuery) (int64, error)
+	// UpdateComment 以单次原子操作更新评论并返回更新前的文档，不存在时返回 ErrCommentNotFound
+	UpdateComment(ctx context.Context, id primitive.ObjectID, update CommentUpdate) (*models.Comment, error)
+	// DeletePostComments 永久删除文章的全部评论，文章被永久删除时调用
+	DeletePostComments(ctx context.Context, postID primitive.ObjectID) error
+}
+
+// MongoCommentStore 基于 MongoDB 的评论存储
+type MongoCommentStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCommentStore 创建新的MongoCommentStore实例
+func NewMongoCommentStore(client *mongo.Client, dbName, collectionName string) *MongoCommentStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoCommentStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建按文章读取评论与按状态读取审核队列的索引
+func (s *MongoCommentStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// CreateComment 保存新评论
+func (s *MongoCommentStore) CreateComment(ctx context.Context, comment *models.Comment) error {
+	_, err := s.collection.InsertOne(ctx, comment)
+	return err
+}
+
+// GetCommentByID 根据ID获取评论
+func (s *MongoCommentStore) GetCommentByID(ctx context.Context, id primitive.ObjectID) (*models.Comment, error) {
+	var comment models.Comment
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments 按发表时间排序分页获取评论
+func (s *MongoCommentStore) ListComments(ctx context.Context, query CommentQuery) ([]*models.Comment, error) {
+	order := -1
+	if query.Oldest {
+		order = 1
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: order}, {Key: "_id", Value: order}})
+	if query.Offset > 0 {
+		opts.SetSkip(query.Offset)
+	}
+	if query.Limit > 0 {
+		opts.SetLimit(query.Limit)
+	}
+
+	cursor, err := s.collection.Find(ctx, commentQueryFilter(query), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []*models.Comment{}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CountComments 统计符合条件的评论数
+func (s *MongoCommentStore) CountComments(ctx context.Context, query CommentQuery) (int64, error) {
+	return s.collection.CountDocuments(ctx, commentQueryFilter(query))
+}
+
+// commentQueryFilter 将查询条件转换为 MongoDB 过滤器
+func commentQueryFilter(query CommentQuery) bson.M {
+	filter := bson.M{}
+	if query.PostID != nil {
+		filter["post_id"] = *query.PostID
+	}
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	}
+	return filter
+}
+
+// UpdateComment 更新评论
+func (s *MongoCommentStore) UpdateComment(ctx context.Context, id primitive.ObjectID, update CommentUpdate) (*models.Comment, error) {
+	setFields := bson.M{"updated_at": update.UpdatedAt}
+	if update.Status != nil {
+		setFields["status"] = *update.Status
+	}
+	if update.Content != nil {
+		setFields["content"] = *update.Content
+	}
+	if update.ContentHTML != nil {
+		setFields["content_html"] = *update.ContentHTML
+	}
+
+	var comment models.Comment
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": setFields},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&comment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// DeletePostComments 永久删除文章的全部评论
+func (s *MongoCommentStore) DeletePostComments(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
+
+// MemoryCommentStore 基于内存的评论存储，用于本地运行与单元测试
+type MemoryCommentStore struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]*models.Comment
+}
+
+// NewMemoryCommentStore 创建新的MemoryCommentStore实例
+func NewMemoryCommentStore() *MemoryCommentStore {
+	return &MemoryCommentStore{
+		comments: make(map[primitive.ObjectID]*models.Comment),
+	}
+}
+
+// CreateComment 保存新评论
+func (s *MemoryCommentStore) CreateComment(_ context.Context, comment *models.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.comments[comment.ID] = cloneComment(comment)
+	return nil
+}
+
+// GetCommentByID 根据ID获取评论
+func (s *MemoryCommentStore) GetCommentByID(_ context.Context, id primitive.ObjectID) (*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+	return cloneComment(comment), nil
+}
+
+// ListComments 按发表时间排序分页获取评论
+func (s *MemoryCommentStore) ListComments(_ context.Context, query CommentQuery) ([]*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := s.filter(query)
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if query.Oldest {
+			a, b = b, a
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID.Hex() > b.ID.Hex()
+	})
+	matched = matched[min(query.Offset, int64(len(matched))):]
+	if query.Limit > 0 && int64(len(matched)) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+
+	comments := make([]*models.Comment, len(matched))
+	for i, comment := range matched {
+		comments[i] = cloneComment(comment)
+	}
+	return comments, nil
+}
+
+// CountComments 统计符合条件的评论数
+func (s *MemoryCommentStore) CountComments(_ context.Context, query CommentQuery) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.filter(query))), nil
+}
+
+// filter 返回符合条件的评论（未排序），调用方需持有读锁
+func (s *MemoryCommentStore) filter(query CommentQuery) []*models.Comment {
+	matched := []*models.Comment{}
+	for _, comment := range s.comments {
+		if query.PostID != nil && comment.PostID != *query.PostID {
+			continue
+		}
+		if len(query.Statuses) > 0 && !slices.Contains(query.Statuses, comment.Status) {
+			continue
+		}
+		matched = append(matched, comment)
+	}
+	return matched
+}
+
+// UpdateComment 更新评论
+func (s *MemoryCommentStore) UpdateComment(_ context.Context, id primitive.ObjectID, update CommentUpdate) (*models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+	before := cloneComment(comment)
+
+	comment.UpdatedAt = update.UpdatedAt
+	if update.Status != nil {
+		comment.Status = *update.Status
+	}
+	if update.Content != nil {
+		comment.Content = *update.Content
+	}
+	if update.ContentHTML != nil {
+		comment.ContentHTML = *update.ContentHTML
+	}
+	return before, nil
+}
+
+// DeletePostComments 永久删除文章的全部评论
+func (s *MemoryCommentStore) DeletePostComments(_ context.Context, postID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, comment := range s.comments {
+		if comment.PostID == postID {
+			delete(s.comments, id)
+		}
+	}
+	return nil
+}
+
+// cloneComment 复制评论，避免调用方修改存储内部的数据
+func cloneComment(comment *models.Comment) *models.Comment {
+	c := *comment
+	if comment.ParentID != nil {
+		parentID := *comment.ParentID
+		c.ParentID = &parentID
+	}
+	return &c
+}
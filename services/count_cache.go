@@ -0,0 +1,102 @@
+package services
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// countCacheTTL 文章总数缓存的有效期，写操作会立即清空缓存
+	countCacheTTL = time.Minute
+	// countCacheSize 缓存的查询条件数上限，标签、检索词等组合可由访客任意构造，超出时淘汰最久未使用的条目
+	countCacheSize = 1000
+)
+
+// countCache 缓存按查询条件统计的文章总数，避免每次翻页都重新计数
+type countCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List // 元素为 *countEntry，最近使用的在前
+}
+
+type countEntry struct {
+	key       string
+	total     int64
+	expiresAt time.Time
+}
+
+func newCountCache(ttl time.Duration, maxEntries int) *countCache {
+	return &countCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// get 获取未过期的缓存总数，已过期的条目顺带删除
+func (c *countCache) get(key string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	entry := elem.Value.(*countEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return 0, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.total, true
+}
+
+// set 写入缓存总数，超出容量时淘汰最久未使用的条目
+func (c *countCache) set(key string, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*countEntry)
+		entry.total, entry.expiresAt = total, expiresAt
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&countEntry{key: key, total: total, expiresAt: expiresAt})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*countEntry).key)
+	}
+}
+
+// invalidate 清空全部缓存，文章发生写操作时调用
+func (c *countCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.lru.Init()
+}
+
+// countKey 生成查询条件的缓存键，分页相关字段不参与计数
+func countKey(query BlogQuery) string {
+	query.Cursor = nil
+	query.Page = 0
+	query.Offset = 0
+	query.Limit = 0
+	// 发布窗口随时间推移变化，由 PublishScheduler 在文章上下线时清空缓存，
+	// 因此只区分是否按发布窗口过滤，不区分具体时刻
+	if !query.VisibleAt.IsZero() {
+		query.VisibleAt = time.Unix(0, 0)
+	}
+	data, _ := json.Marshal(query)
+	return string(data)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCountCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCountCache(time.Minute, 3)
+	for i := 1; i <= 3; i++ {
+		c.set(fmt.Sprint(i), int64(i))
+	}
+	// 访问 1 后写入 4，淘汰最久未使用的 2
+	c.get("1")
+	c.set("4", 4)
+
+	tests := []struct {
+		key   string
+		total int64
+		ok    bool
+	}{
+		{"1", 1, true},
+		{"2", 0, false},
+		{"3", 3, true},
+		{"4", 4, true},
+	}
+	for _, tt := range tests {
+		if total, ok := c.get(tt.key); total != tt.total || ok != tt.ok {
+			t.Errorf("get(%q) = %d, %v, want %d, %v", tt.key, total, ok, tt.total, tt.ok)
+		}
+	}
+	if n := len(c.entries); n != 3 {
+		t.Errorf("缓存条目数 = %d, want 3", n)
+	}
+}
+
+func TestCountCacheExpires(t *testing.T) {
+	c := newCountCache(-time.Second, 10)
+	c.set("a", 1)
+	if _, ok := c.get("a"); ok {
+		t.Error("get 返回了过期的条目")
+	}
+	if n := c.lru.Len(); n != 0 {
+		t.Errorf("过期条目未删除，剩余 %d 个", n)
+	}
+}
+
+func TestCountKeyIgnoresPagination(t *testing.T) {
+	base := BlogQuery{Tags: []string{"go"}, VisibleAt: time.Now()}
+	paged := base
+	paged.Page, paged.Offset, paged.Limit = 3, 20, 10
+	paged.Cursor = &BlogCursor{}
+	paged.VisibleAt = time.Now().Add(time.Hour)
+
+	if countKey(base) != countKey(paged) {
+		t.Error("分页参数与发布窗口时刻不应影响缓存键")
+	}
+	other := base
+	other.Tags = []string{"rust"}
+	if countKey(base) == countKey(other) {
+		t.Error("不同的过滤条件应得到不同的缓存键")
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"strings"
+)
+
+// 行级差异的操作类型
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffEdits 差异计算的最大编辑距离，超过时退化为整段删除加整段插入，避免超大改动耗尽内存
+const maxDiffEdits = 1000
+
+// DiffLine 行级差异中的一行
+type DiffLine struct {
+	Op      string // DiffEqual、DiffInsert 或 DiffDelete
+	Text    string
+	OldLine int // 在旧文本中的行号（从 1 开始），插入行为 0
+	NewLine int // 在新文本中的行号（从 1 开始），删除行为 0
+}
+
+// DiffText 按行比较两段文本，返回完整的行级差异
+func DiffText(oldText, newText string) []DiffLine {
+	return diffLines(splitLines(oldText), splitLines(newText))
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// diffLines 使用 Myers 差分算法计算最短编辑脚本，公共前后缀直接视为相同行
+func diffLines(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []DiffLine
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+	for _, l := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if l.OldLine > 0 {
+			l.OldLine += prefix
+		}
+		if l.NewLine > 0 {
+			l.NewLine += prefix
+		}
+		lines = append(lines, l)
+	}
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: a[len(a)-i], OldLine: len(a) - i + 1, NewLine: len(b) - i + 1})
+	}
+	return lines
+}
+
+func myers(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		// 第 d 步只会读取 k ∈ [-d-1, d+1] 的值，只保存这一段以节省内存
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// 从终点回溯编辑路径，得到的行是倒序的
+	var rev []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v, base := trace[d], d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[base+k-1] < v[base+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[base+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			rev = append(rev, DiffLine{Op: DiffEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				rev = append(rev, DiffLine{Op: DiffInsert, Text: b[y-1], NewLine: y})
+			} else {
+				rev = append(rev, DiffLine{Op: DiffDelete, Text: a[x-1], OldLine: x})
+			}
+			x, y = prevX, prevY
+		}
+	}
+
+	lines := make([]DiffLine, len(rev))
+	for i, l := range rev {
+		lines[len(rev)-1-i] = l
+	}
+	return lines
+}
+
+// replaceAll 将差异表示为删除全部旧行再插入全部新行
+func replaceAll(a, b []string) []DiffLine {
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	for i, text := range a {
+		lines = append(lines, DiffLine{Op: DiffDelete, Text: text, OldLine: i + 1})
+	}
+	for i, text := range b {
+		lines = append(lines, DiffLine{Op: DiffInsert, Text: text, NewLine: i + 1})
+	}
+	return lines
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// diffString 将差异压缩为便于比较的形式，如 " a 1 1"、"+b 0 2"
+func diffString(lines []DiffLine) []string {
+	var s []string
+	for _, l := range lines {
+		op := map[string]string{DiffEqual: " ", DiffInsert: "+", DiffDelete: "-"}[l.Op]
+		s = append(s, fmt.Sprintf("%s%s %d %d", op, l.Text, l.OldLine, l.NewLine))
+	}
+	return s
+}
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{"都为空", "", "", nil},
+		{"新增全部内容", "", "a\nb", []string{"+a 0 1", "+b 0 2"}},
+		{"删除全部内容", "a\nb", "", []string{"-a 1 0", "-b 2 0"}},
+		{"内容相同", "a\nb", "a\nb", []string{" a 1 1", " b 2 2"}},
+		{"中间插入", "a\nc", "a\nb\nc", []string{" a 1 1", "+b 0 2", " c 2 3"}},
+		{"中间删除", "a\nb\nc", "a\nc", []string{" a 1 1", "-b 2 0", " c 3 2"}},
+		{"替换一行", "a\nb\nc", "a\nx\nc", []string{" a 1 1", "-b 2 0", "+x 0 2", " c 3 3"}},
+		{"CRLF 与 LF 视为相同", "a\r\nb", "a\nb", []string{" a 1 1", " b 2 2"}},
+		{
+			"公共前后缀之间的多处修改",
+			"head\na\nb\nc\nd\ntail", "head\nb\nc\nx\nd\ntail",
+			[]string{" head 1 1", "-a 2 0", " b 3 2", " c 4 3", "+x 0 4", " d 5 5", " tail 6 6"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffString(DiffText(tt.old, tt.new)); !slices.Equal(got, tt.want) {
+				t.Errorf("DiffText(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffTextReconstructsBothSides(t *testing.T) {
+	oldText := strings.Join([]string{"a", "b", "c", "a", "b", "b", "a"}, "\n")
+	newText := strings.Join([]string{"c", "b", "a", "b", "a", "c"}, "\n")
+
+	lines := DiffText(oldText, newText)
+	var oldLines, newLines []string
+	edits := 0
+	for _, l := range lines {
+		if l.Op != DiffInsert {
+			oldLines = append(oldLines, l.Text)
+			if l.OldLine != len(oldLines) {
+				t.Errorf("%q 的旧行号为 %d, want %d", l.Text, l.OldLine, len(oldLines))
+			}
+		}
+		if l.Op != DiffDelete {
+			newLines = append(newLines, l.Text)
+			if l.NewLine != len(newLines) {
+				t.Errorf("%q 的新行号为 %d, want %d", l.Text, l.NewLine, len(newLines))
+			}
+		}
+		if l.Op != DiffEqual {
+			edits++
+		}
+	}
+	if got := strings.Join(oldLines, "\n"); got != oldText {
+		t.Errorf("还原的旧文本 = %q, want %q", got, oldText)
+	}
+	if got := strings.Join(newLines, "\n"); got != newText {
+		t.Errorf("还原的新文本 = %q, want %q", got, newText)
+	}
+	// Myers 论文中的示例，最短编辑脚本长度为 5
+	if edits != 5 {
+		t.Errorf("编辑行数 = %d, want 5", edits)
+	}
+}
+
+func TestDiffTextFallsBackBeyondMaxEdits(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxDiffEdits; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+	}
+	// 公共前后缀不计入编辑距离
+	oldText := "head\n" + strings.Join(a, "\n") + "\ntail"
+	newText := "head\n" + strings.Join(b, "\n") + "\ntail"
+
+	lines := DiffText(oldText, newText)
+	if len(lines) != 2*maxDiffEdits+2 {
+		t.Fatalf("差异行数 = %d, want %d", len(lines), 2*maxDiffEdits+2)
+	}
+	if got := diffString(lines[:2]); !slices.Equal(got, []string{" head 1 1", "-old 0 2 0"}) {
+		t.Errorf("开头的差异 = %q", got)
+	}
+	// 整段删除后整段插入
+	for i, l := range lines[1 : len(lines)-1] {
+		want := DiffDelete
+		if i >= maxDiffEdits {
+			want = DiffInsert
+		}
+		if l.Op != want {
+			t.Fatalf("第 %d 行的操作 = %s, want %s", i+2, l.Op, want)
+		}
+	}
+	if got := diffString(lines[len(lines)-1:]); !slices.Equal(got, []string{fmt.Sprintf(" tail %d %d", maxDiffEdits+2, maxDiffEdits+2)}) {
+		t.Errorf("结尾的差异 = %q", got)
+	}
+}
+
+func TestDiffAndRollbackRevisions(t *testing.T) {
+	blogService := newTestBlogService()
+	blog, err := blogService.CreateBlog(CreateBlogInput{
+		Title: "初稿", Content: "第一行\n第二行", Author: "alice", Tags: []string{"go"}, Show: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := blog.ID.Hex()
+
+	title, content := "修订稿", "第一行\n第二行（已修改）\n第三行"
+	if _, err := blogService.UpdateBlog(id, UpdateBlogInput{Title: &title, Content: &content, Tags: []string{}, Editor: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := blogService.DiffRevisions(id, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{RevisionFieldTitle, RevisionFieldContent, RevisionFieldTags}; !slices.Equal(diff.ChangedFields, want) {
+		t.Errorf("ChangedFields = %q, want %q", diff.ChangedFields, want)
+	}
+	if diff.Added != 2 || diff.Removed != 1 {
+		t.Errorf("Added/Removed = %d/%d, want 2/1", diff.Added, diff.Removed)
+	}
+	if diff.From.Content != "" || diff.To.Content != "" {
+		t.Error("差异中的版本不应包含正文")
+	}
+
+	rolledBack, err := blogService.RollbackBlog(id, 1, "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack.Title != "初稿" || rolledBack.Content != "第一行\n第二行" || !slices.Equal(rolledBack.Tags, []string{"go"}) {
+		t.Errorf("回滚后的文章 = %q %q %q", rolledBack.Title, rolledBack.Content, rolledBack.Tags)
+	}
+
+	// 回滚记录为新版本，与版本 1 内容相同
+	rev, err := blogService.GetRevision(id, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Editor != "carol" || rev.Note != "回滚到版本 1" {
+		t.Errorf("版本 3 的 Editor/Note = %q/%q", rev.Editor, rev.Note)
+	}
+	diff, err = blogService.DiffRevisions(id, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.ChangedFields) != 0 || diff.Added != 0 || diff.Removed != 0 {
+		t.Errorf("版本 1 与版本 3 不应有差异: %+v", diff)
+	}
+
+	if _, err := blogService.DiffRevisions(id, 1, 9); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("DiffRevisions 不存在的版本 err = %v, want %v", err, ErrRevisionNotFound)
+	}
+	if _, err := blogService.RollbackBlog(id, 9, "carol"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("RollbackBlog 不存在的版本 err = %v, want %v", err, ErrRevisionNotFound)
+	}
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidExportFormat 不支持的导出格式
+var ErrInvalidExportFormat = errors.New("不支持的导出格式，可选值：zip、tar.gz")
+
+// ExportFormat 导出文件的归档格式
+type ExportFormat string
+
+const (
+	ExportZip   ExportFormat = "zip"
+	ExportTarGz ExportFormat = "tar.gz"
+)
+
+// ParseExportFormat 解析导出格式，为空时使用 zip
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch format := ExportFormat(s); format {
+	case "":
+		return ExportZip, nil
+	case ExportZip, ExportTarGz:
+		return format, nil
+	}
+	return "", ErrInvalidExportFormat
+}
+
+// ContentType 归档文件的 MIME 类型
+func (f ExportFormat) ContentType() string {
+	if f == ExportTarGz {
+		return "application/gzip"
+	}
+	return "application/zip"
+}
+
+// FileName 按导出时间生成归档文件名，如 blog-export-20240102-150405.zip
+func (f ExportFormat) FileName(t time.Time) string {
+	return "blog-export-" + t.UTC().Format("20060102-150405") + "." + string(f)
+}
+
+// ExportService 导出全部数据：每篇文章（不含回收站）一个带 front matter 的 Markdown 文件，
+// 用户（不含密码哈希）与其他集合各一个 JSON 文件，以及 media/ 下的全部媒体文件；
+// 归档可由 RestoreService 完整恢复，其中的文章也可由 ImportService 单独导入
+type ExportService struct {
+	blogService *BlogService
+	series      SeriesStore
+	users       UserStore
+	media       MediaMetaStore
+	files       MediaStore
+}
+
+// NewExportService 创建新的ExportService实例
+func NewExportService(blogService *BlogService, series SeriesStore, users UserStore, media MediaMetaStore, files MediaStore) *ExportService {
+	return &ExportService{
+		blogService: blogService,
+		series:      series,
+		users:       users,
+		media:       media,
+		files:       files,
+	}
+}
+
+// ExportData 导出的全部文件；文章与集合在写出前完整读取，媒体文件在写出时逐个读取
+type ExportData struct {
+	CreatedAt time.Time
+	Posts     int // 导出的文章数
+	Media     int // 导出的媒体文件数
+	files     []exportFile
+}
+
+// exportFile 归档中的一个文件，open 不为 nil 时内容在写出时读取，size 为其字节数
+type exportFile struct {
+	name    string
+	data    []byte
+	open    func(ctx context.Context) (io.ReadCloser, error)
+	size    int64
+	modTime time.Time
+}
+
+// exportReaction 导出的回应，包含用于去重的读者标识
+type exportReaction struct {
+	*models.Reaction
+	Actor string `json:"actor"`
+}
+
+// exportMedia 导出的媒体文件元数据，包含文件在归档 media/ 目录与 MediaStore 中的路径
+type exportMedia struct {
+	*models.Media
+	Key string `json:"key"`
+}
+
+// exportFrontMatter 导出文章的 front matter，字段顺序即输出顺序，与 parseMarkdownPost 读取的字段对应
+type exportFrontMatter struct {
+	ID          string     `yaml:"id"`
+	Title       string     `yaml:"title"`
+	Slug        string     `yaml:"slug"`
+	Author      string     `yaml:"author"`
+	Tags        []string   `yaml:"tags,omitempty"`
+	Categories  []string   `yaml:"categories,omitempty"` // 分类路径，由上级到下级
+	Show        bool       `yaml:"show"`
+	Comments    *bool      `yaml:"comments,omitempty"` // 只在关闭评论时输出 false
+	Views       int64      `yaml:"views"`
+	Created     time.Time  `yaml:"created"`
+	Updated     time.Time  `yaml:"updated"`
+	PublishAt   *time.Time `yaml:"publish_at,omitempty"`
+	UnpublishAt *time.Time `yaml:"unpublish_at,omitempty"`
+}
+
+// Export 读取全部文章与其他集合，生成待写出的文件
+func (s *ExportService) Export() (*ExportData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	export := &ExportData{CreatedAt: time.Now()}
+
+	blogs, err := s.blogService.store.ListBlogs(ctx, BlogQuery{})
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.blogService.categories.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, blog := range blogs {
+		data, err := exportPost(blog, categoryPath(categories, blog.CategoryID))
+		if err != nil {
+			return nil, err
+		}
+		name := blog.Slug
+		if name == "" {
+			name = blog.ID.Hex()
+		}
+		export.files = append(export.files, exportFile{name: "posts/" + name + ".md", data: data, modTime: blog.UpdatedAt})
+	}
+	export.Posts = len(blogs)
+
+	users, err := s.users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	series, err := s.series.ListSeries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.blogService.comments.ListComments(ctx, CommentQuery{Oldest: true})
+	if err != nil {
+		return nil, err
+	}
+	reactions, err := s.blogService.reactions.ListReactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.blogService.revisions.ListAllRevisions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	media, err := s.media.ListMedia(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	exportedReactions := make([]exportReaction, len(reactions))
+	for i, reaction := range reactions {
+		exportedReactions[i] = exportReaction{Reaction: reaction, Actor: reaction.Actor}
+	}
+	exportedMedia := make([]exportMedia, len(media))
+	for i, m := range media {
+		exportedMedia[i] = exportMedia{Media: m, Key: m.Key}
+	}
+	collections := []struct {
+		name string
+		list any
+	}{
+		{"users.json", users},
+		{"categories.json", categories},
+		{"series.json", series},
+		{"comments.json", comments},
+		{"reactions.json", exportedReactions},
+		{"revisions.json", revisions},
+		{"media.json", exportedMedia},
+	}
+	for _, c := range collections {
+		data, err := json.MarshalIndent(c.list, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		export.files = append(export.files, exportFile{name: c.name, data: append(data, '\n'), modTime: export.CreatedAt})
+	}
+
+	for _, m := range media {
+		key := m.Key
+		export.files = append(export.files, exportFile{
+			name:    "media/" + key,
+			open:    func(ctx context.Context) (io.ReadCloser, error) { return s.files.Open(ctx, key) },
+			size:    m.Size,
+			modTime: m.CreatedAt,
+		})
+	}
+	export.Media = len(media)
+	return export, nil
+}
+
+// WriteArchive 将导出的文件写出为 zip 或 tar.gz 归档，媒体文件读取失败时返回错误，已写出的归档不完整
+func (e *ExportData) WriteArchive(w io.Writer, format ExportFormat) error {
+	if format == ExportTarGz {
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		for _, f := range e.files {
+			size := int64(len(f.data))
+			if f.open != nil {
+				size = f.size
+			}
+			header := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     f.name,
+				Mode:     0o644,
+				Size:     size,
+				ModTime:  f.modTime,
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if err := f.writeTo(tw); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range e.files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: f.modTime})
+		if err != nil {
+			return err
+		}
+		if err := f.writeTo(fw); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeTo 将文件内容写入 w；tar 归档中实际大小与元数据不符时由 tar.Writer 报错
+func (f *exportFile) writeTo(w io.Writer) error {
+	if f.open == nil {
+		_, err := w.Write(f.data)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	r, err := f.open(ctx)
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", f.name, err)
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// exportPost 生成文章的 Markdown 文件：YAML front matter、一个空行与原样的正文
+func exportPost(blog *models.Blog, categories []string) ([]byte, error) {
+	front := exportFrontMatter{
+		ID:          blog.ID.Hex(),
+		Title:       blog.Title,
+		Slug:        blog.Slug,
+		Author:      blog.Author,
+		Tags:        blog.Tags,
+		Categories:  categories,
+		Show:        blog.Show,
+		Views:       blog.Views,
+		Created:     blog.CreatedAt,
+		Updated:     blog.UpdatedAt,
+		PublishAt:   blog.PublishAt,
+		UnpublishAt: blog.UnpublishAt,
+	}
+	if blog.CommentsDisabled {
+		front.Comments = new(bool)
+	}
+	data, err := yaml.Marshal(front)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(data)
+	buf.WriteString("---\n\n")
+	buf.WriteString(blog.Content)
+	return buf.Bytes(), nil
+}
+
+// categoryPath 返回分类由顶级到自身的名称路径，未分类或分类不存在时返回 nil
+func categoryPath(categories []*models.Category, id *primitive.ObjectID) []string {
+	var path []string
+	for id != nil && len(path) <= len(categories) {
+		i := slices.IndexFunc(categories, func(c *models.Category) bool { return c.ID == *id })
+		if i < 0 {
+			break
+		}
+		path = append(path, categories[i].Name)
+		id = categories[i].ParentID
+	}
+	slices.Reverse(path)
+	return path
+}
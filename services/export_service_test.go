@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExportPostRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 123456789, time.FixedZone("Asia/Shanghai", 8*3600))
+	updated := created.Add(48 * time.Hour).UTC()
+	publishAt := created.Add(time.Hour)
+	unpublishAt := created.Add(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		blog       *models.Blog
+		categories []string
+	}{
+		{"普通文章", &models.Blog{Title: "你好", Slug: "hello", Author: "alice", Tags: []string{"Go", "博客"}, Show: true, Views: 42, Content: "# 标题\n\n正文\n"}, []string{"技术", "Go"}},
+		{"草稿与关闭评论", &models.Blog{Title: "草稿", Slug: "draft", Author: "bob", CommentsDisabled: true, Content: "草稿"}, nil},
+		{"定时发布与下线", &models.Blog{Title: "定时", Slug: "scheduled", Author: "alice", Show: true, PublishAt: &publishAt, UnpublishAt: &unpublishAt, Content: "定时"}, nil},
+		{"正文原样保留", &models.Blog{Title: "---", Slug: "raw", Author: "alice", Show: true, Content: "\n\n开头的空行\r\n---\ntitle: 不是 front matter\n---\n结尾的空白  \n\n"}, nil},
+		{"空正文", &models.Blog{Title: "空", Slug: "empty", Author: "alice", Show: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blog := tt.blog
+			blog.ID = primitive.NewObjectID()
+			blog.CreatedAt = created
+			blog.UpdatedAt = updated
+
+			data, err := exportPost(blog, tt.categories)
+			if err != nil {
+				t.Fatal(err)
+			}
+			post, err := parseMarkdownPost(data, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if post.ID != blog.ID || post.Title != blog.Title || post.Slug != blog.Slug || post.Author != blog.Author {
+				t.Errorf("id/title/slug/author = %s/%q/%q/%q", post.ID.Hex(), post.Title, post.Slug, post.Author)
+			}
+			if post.Content != blog.Content {
+				t.Errorf("content = %q, want %q", post.Content, blog.Content)
+			}
+			if !slices.Equal(post.Tags, blog.Tags) || !slices.Equal(post.Categories, tt.categories) {
+				t.Errorf("tags/categories = %q/%q", post.Tags, post.Categories)
+			}
+			if post.Draft != !blog.Show || post.CommentsDisabled != blog.CommentsDisabled {
+				t.Errorf("draft/commentsDisabled = %v/%v", post.Draft, post.CommentsDisabled)
+			}
+			if post.Views == nil || *post.Views != blog.Views {
+				t.Errorf("views = %v, want %d", post.Views, blog.Views)
+			}
+			if !post.Date.Equal(blog.CreatedAt) || !post.Updated.Equal(blog.UpdatedAt) {
+				t.Errorf("date/updated = %v/%v", post.Date, post.Updated)
+			}
+			if !equalTime(post.PublishAt, blog.PublishAt) || !equalTime(post.UnpublishAt, blog.UnpublishAt) {
+				t.Errorf("publish_at/unpublish_at = %v/%v", post.PublishAt, post.UnpublishAt)
+			}
+		})
+	}
+}
+
+// testSite 一套基于内存的存储及其上的服务，媒体文件保存在临时目录
+type testSite struct {
+	blogService *BlogService
+	categories  *CategoryService
+	series      SeriesStore
+	users       UserStore
+	mediaMeta   MediaMetaStore
+	media       MediaStore
+}
+
+func newTestSite(t *testing.T) *testSite {
+	t.Helper()
+	blogService := newTestBlogService()
+	return &testSite{
+		blogService: blogService,
+		categories:  NewCategoryService(blogService.categories, blogService),
+		series:      NewMemorySeriesStore(),
+		users:       NewMemoryUserStore(),
+		mediaMeta:   NewMemoryMediaMetaStore(),
+		media:       NewLocalMediaStore(t.TempDir()),
+	}
+}
+
+func (s *testSite) restoreService() *RestoreService {
+	return NewRestoreService(NewImportService(s.blogService, s.categories), s.series, s.users, s.mediaMeta, s.media)
+}
+
+// exportArchive 导出 site 的全部数据并写出为归档文件
+func exportArchive(t *testing.T, site *testSite, format ExportFormat) string {
+	t.Helper()
+	export, err := NewExportService(site.blogService, site.series, site.users, site.mediaMeta, site.media).Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), format.FileName(export.CreatedAt))
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := export.WriteArchive(f, format); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestExportRestoreArchive(t *testing.T) {
+	ctx := context.Background()
+	src := newTestSite(t)
+	bs := src.blogService
+
+	user := &models.User{ID: primitive.NewObjectID(), Username: "alice", Password: "hash", Email: "alice@example.com"}
+	if err := src.users.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	name, empty := "技术", ""
+	category, err := src.categories.CreateCategory(CategoryInput{Name: &name, ParentID: &empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	png := testPNG(t, "")
+	sum := sha256.Sum256(png)
+	hash := hex.EncodeToString(sum[:])
+	media := &models.Media{ID: primitive.NewObjectID(), Hash: hash, Key: mediaKey(hash, ".png"), ContentType: "image/png", Size: int64(len(png)), Uploader: "alice"}
+	if err := src.media.Put(ctx, media.Key, bytes.NewReader(png), media.Size, media.ContentType); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.mediaMeta.CreateMedia(ctx, media); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := bs.CreateBlog(CreateBlogInput{Title: "第一篇", Content: "![图](/media/" + hash + ")", Author: "alice", Tags: []string{"go"}, Show: true, CategoryID: category.ID.Hex()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "修改后的正文"
+	if _, err := bs.UpdateBlog(post.ID.Hex(), UpdateBlogInput{Content: &content, Editor: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	draft, err := bs.CreateBlog(CreateBlogInput{Title: "草稿", Content: "草稿", Author: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashed, err := bs.CreateBlog(CreateBlogInput{Title: "回收站", Content: "回收站", Author: "alice", Show: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series := &models.Series{ID: primitive.NewObjectID(), Title: "系列", Slug: "series", PostIDs: []primitive.ObjectID{post.ID, trashed.ID, draft.ID}}
+	if err := src.series.CreateSeries(ctx, series); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*models.Comment{
+		{ID: primitive.NewObjectID(), PostID: post.ID, AuthorName: "bob", Content: "赞", Status: models.CommentApproved},
+		{ID: primitive.NewObjectID(), PostID: post.ID, AuthorName: "spam", Content: "广告", Status: models.CommentPending},
+		{ID: primitive.NewObjectID(), PostID: trashed.ID, AuthorName: "bob", Content: "回收站", Status: models.CommentApproved},
+	} {
+		if err := bs.comments.CreateComment(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bs.store.IncrementComments(ctx, post.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	reactionService := NewReactionService(bs.reactions, bs, []models.ReactionKind{{Name: "like", Emoji: "👍"}})
+	for _, actor := range []string{"user:" + user.ID.Hex(), "anon:1"} {
+		if _, err := reactionService.React(post.ID.Hex(), "like", actor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bs.DeleteBlog(trashed.ID.Hex(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []ExportFormat{ExportZip, ExportTarGz} {
+		t.Run(string(format), func(t *testing.T) {
+			file := exportArchive(t, src, format)
+			dst := newTestSite(t)
+			restoreService := dst.restoreService()
+
+			// 试运行不写入数据
+			dry, err := restoreService.RestoreArchive(file, ImportOptions{DryRun: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if users, _ := dst.users.ListUsers(ctx); len(users) != 0 {
+				t.Errorf("试运行后用户数 = %d", len(users))
+			}
+
+			result, err := restoreService.RestoreArchive(file, ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string][2]int{ // 恢复数与跳过数
+				"users.json":      {1, 0},
+				"categories.json": {1, 0},
+				"media.json":      {1, 0},
+				"revisions.json":  {3, 1}, // 回收站中的文章没有导出
+				"series.json":     {1, 0},
+				"comments.json":   {2, 1},
+				"reactions.json":  {2, 0},
+			}
+			for i, c := range result.Collections {
+				if got := [2]int{c.Restored, c.Skipped}; got != want[c.Name] || len(c.Failed) > 0 {
+					t.Errorf("%s: 恢复 %d 跳过 %d 失败 %q, want %v", c.Name, c.Restored, c.Skipped, c.Failed, want[c.Name])
+				}
+				if d := dry.Collections[i]; d.Restored != c.Restored || d.Skipped != c.Skipped {
+					t.Errorf("%s: 试运行恢复 %d 跳过 %d, 实际 %d %d", c.Name, d.Restored, d.Skipped, c.Restored, c.Skipped)
+				}
+			}
+			if len(result.Posts) != 2 {
+				t.Fatalf("导入了 %d 篇文章, want 2", len(result.Posts))
+			}
+
+			// 文章
+			got, err := dst.blogService.store.GetBlogByID(ctx, post.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original, _ := bs.store.GetBlogByID(ctx, post.ID)
+			if got.Title != original.Title || got.Slug != original.Slug || got.Content != original.Content ||
+				!slices.Equal(got.Tags, original.Tags) || got.Show != original.Show || got.Source != "" ||
+				got.CategoryID == nil || *got.CategoryID != category.ID ||
+				!got.CreatedAt.Equal(original.CreatedAt) || !got.UpdatedAt.Equal(original.UpdatedAt) {
+				t.Errorf("文章 = %+v, want %+v", got, original)
+			}
+			if got.CommentCount != 1 || got.Reactions["like"] != 2 {
+				t.Errorf("评论数/回应数 = %d/%v", got.CommentCount, got.Reactions)
+			}
+			if d, err := dst.blogService.store.GetBlogByID(ctx, draft.ID); err != nil || d.Show {
+				t.Errorf("草稿 = %+v, %v", d, err)
+			}
+			if _, err := dst.blogService.store.GetBlogByID(ctx, trashed.ID); err != ErrBlogNotFound {
+				t.Errorf("回收站中的文章 err = %v", err)
+			}
+
+			// 版本保留原有的版本号与内容
+			revs, total, err := dst.blogService.revisions.ListRevisions(ctx, post.ID, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 2 || revs[0].Number != 2 || revs[0].Editor != "alice" {
+				t.Errorf("版本 = %d 个, 最新 %+v", total, revs[0])
+			}
+			if rev, err := dst.blogService.revisions.GetRevision(ctx, post.ID, 1); err != nil || rev.Content != "![图](/media/"+hash+")" {
+				t.Errorf("版本 1 = %+v, %v", rev, err)
+			}
+
+			// 用户不含密码哈希
+			u, err := dst.users.GetUserByID(ctx, user.ID)
+			if err != nil || u.Username != "alice" || u.Password != "" {
+				t.Errorf("用户 = %+v, %v", u, err)
+			}
+
+			// 系列去掉没有导出的文章
+			s, err := dst.series.GetSeriesByID(ctx, series.ID)
+			if err != nil || !slices.Equal(s.PostIDs, []primitive.ObjectID{post.ID, draft.ID}) {
+				t.Errorf("系列 = %+v, %v", s, err)
+			}
+
+			// 回应保留读者标识，同一读者不能重复回应
+			added, err := dst.blogService.reactions.AddReaction(ctx, &models.Reaction{ID: primitive.NewObjectID(), PostID: post.ID, Kind: "like", Actor: "anon:1"})
+			if err != nil || added {
+				t.Errorf("重复回应 added = %v, %v", added, err)
+			}
+
+			// 媒体文件
+			m, err := dst.mediaMeta.GetMediaByHash(ctx, hash)
+			if err != nil || m.Key != media.Key || m.ID != media.ID {
+				t.Fatalf("媒体文件元数据 = %+v, %v", m, err)
+			}
+			r, err := dst.media.Open(ctx, m.Key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || !bytes.Equal(data, png) {
+				t.Errorf("媒体文件内容不一致: %v", err)
+			}
+
+			// 再次恢复时全部跳过
+			again, err := restoreService.RestoreArchive(file, ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range again.Collections {
+				if c.Restored != 0 || len(c.Failed) > 0 {
+					t.Errorf("再次恢复 %s: 恢复 %d 失败 %q", c.Name, c.Restored, c.Failed)
+				}
+			}
+			for _, p := range again.Posts {
+				if p.Action != ImportSkipped {
+					t.Errorf("再次恢复 %s: %s（%s）", p.Path, p.Action, p.Reason)
+				}
+			}
+			if got, _ := dst.blogService.store.GetBlogByID(ctx, post.ID); got.CommentCount != 1 || got.Reactions["like"] != 2 {
+				t.Errorf("再次恢复后评论数/回应数 = %d/%v", got.CommentCount, got.Reactions)
+			}
+		})
+	}
+}
+
+func TestRestoreArchiveRejectsInvalidArchives(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	restoreService := newTestSite(t).restoreService()
+
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"不是归档", write("text.zip", []byte("hello"))},
+		{"空文件", write("empty.zip", nil)},
+		{"缺少集合文件", exportFiles(t, dir, "posts.zip", exportFile{name: "posts/a.md", data: []byte("---\ntitle: a\n---\n")})},
+		{"路径越界", exportFiles(t, dir, "escape.tar.gz", exportFile{name: "../escape.json", data: []byte("[]")})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := restoreService.RestoreArchive(tt.file, ImportOptions{}); err == nil {
+				t.Error("应返回错误")
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.json")); err == nil {
+		t.Error("不应写出归档目录之外的文件")
+	}
+}
+
+// exportFiles 将 files 写出为归档文件，格式由扩展名决定
+func exportFiles(t *testing.T, dir, name string, files ...exportFile) string {
+	t.Helper()
+	format := ExportZip
+	if filepath.Ext(name) == ".gz" {
+		format = ExportTarGz
+	}
+	var buf bytes.Buffer
+	if err := (&ExportData{files: files}).WriteArchive(&buf, format); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
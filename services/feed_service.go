@@ -0,0 +1,130 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"blog/models"
+)
+
+// FeedFilter 订阅源的过滤条件，零值表示全站订阅
+type FeedFilter struct {
+	Tag    string
+	Author string
+}
+
+// Feed 与格式无关的订阅源内容，由处理器编码为 RSS、Atom 或 JSON Feed
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // 站点首页
+	Updated     time.Time
+	Digest      string // 由条目及其版本计算的摘要，内容不变时保持不变，用作 ETag
+	Items       []*FeedItem
+}
+
+// FeedItem 订阅源中的一篇文章
+type FeedItem struct {
+	ID          string // 稳定的唯一标识（tag URI），不随 slug 变化
+	Title       string
+	Link        string
+	Author      string
+	Tags        []string
+	Summary     string // 纯文本摘要
+	ContentHTML string // 渲染后的完整正文，仅在输出全文时设置
+	Published   time.Time
+	Updated     time.Time
+}
+
+// FeedService 根据前端可见的文章生成订阅源
+type FeedService struct {
+	blogService *BlogService
+	site        SiteConfig
+	fullContent bool
+	limit       int64
+}
+
+// NewFeedService 创建新的FeedService实例
+// fullContent 为 true 时输出渲染后的全文，否则只输出摘要；limit 为每个订阅源包含的文章数
+func NewFeedService(blogService *BlogService, site SiteConfig, fullContent bool, limit int64) *FeedService {
+	return &FeedService{
+		blogService: blogService,
+		site:        site,
+		fullContent: fullContent,
+		limit:       limit,
+	}
+}
+
+// Site 返回站点信息
+func (s *FeedService) Site() SiteConfig {
+	return s.site
+}
+
+// VisibleFeed 生成最近发布的前端可见文章的订阅源
+func (s *FeedService) VisibleFeed(filter FeedFilter) (*Feed, error) {
+	query := BlogQuery{Author: filter.Author, Page: 1, Limit: s.limit}
+	title := s.site.Title
+	if filter.Tag != "" {
+		query.Tags = NormalizeTags([]string{filter.Tag})
+		title = fmt.Sprintf("%s - 标签：%s", s.site.Title, filter.Tag)
+	}
+	if filter.Author != "" {
+		title = fmt.Sprintf("%s - 作者：%s", s.site.Title, filter.Author)
+	}
+
+	page, err := s.blogService.ListVisibleBlogs(query, false)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       title,
+		Description: s.site.Description,
+		Link:        s.site.AbsURL("/"),
+		Items:       make([]*FeedItem, 0, len(page.Blogs)),
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%t|%s|%s|%s\n", s.fullContent, title, s.site.URL, s.site.PostPath)
+	for _, blog := range page.Blogs {
+		feed.Items = append(feed.Items, s.feedItem(blog))
+		// 定时发布的文章上线时 UpdatedAt 不变，以两者中较晚者作为订阅源的更新时间
+		feed.Updated = latest(feed.Updated, blog.UpdatedAt, blog.PublishedAt)
+		fmt.Fprintf(hash, "%s:%d\n", blog.ID.Hex(), blog.Version)
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Unix(0, 0).UTC()
+	}
+	feed.Digest = hex.EncodeToString(hash.Sum(nil))[:32]
+	return feed, nil
+}
+
+// feedItem 将文章转换为订阅源条目
+func (s *FeedService) feedItem(blog *models.Blog) *FeedItem {
+	item := &FeedItem{
+		ID:        fmt.Sprintf("tag:%s,%s:%s", s.site.Host(), blog.CreatedAt.UTC().Format("2006-01-02"), blog.ID.Hex()),
+		Title:     blog.Title,
+		Link:      s.site.PostURL(blog.ID, blog.Slug),
+		Author:    blog.Author,
+		Tags:      blog.Tags,
+		Summary:   blog.Excerpt,
+		Published: blog.PublishedAt,
+		Updated:   latest(blog.UpdatedAt, blog.PublishedAt),
+	}
+	if s.fullContent {
+		item.ContentHTML = s.blogService.RenderContentHTML(blog)
+	}
+	return item
+}
+
+// latest 返回最晚的时间
+func latest(times ...time.Time) time.Time {
+	var t time.Time
+	for _, c := range times {
+		if c.After(t) {
+			t = c
+		}
+	}
+	return t
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTestFeedService(blogService *BlogService) *FeedService {
+	return NewFeedService(blogService, SiteConfig{Title: "博客", URL: "https://example.com", PostPath: "/blog/{slug}"}, false, 10)
+}
+
+func TestVisibleFeed(t *testing.T) {
+	blogService := newTestBlogService()
+	feedService := newTestFeedService(blogService)
+	var ids []string
+	for _, input := range []CreateBlogInput{
+		{Title: "Go 入门", Content: "正文", Author: "alice", Show: true, Tags: []string{"Go"}},
+		{Title: "Rust 入门", Content: "正文", Author: "bob", Show: true, Tags: []string{"Rust"}},
+		{Title: "草稿", Content: "正文", Author: "alice", Show: false, Tags: []string{"Go"}},
+	} {
+		blog, err := blogService.CreateBlog(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, blog.ID.Hex())
+	}
+
+	feed := func(filter FeedFilter) *Feed {
+		t.Helper()
+		feed, err := feedService.VisibleFeed(filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return feed
+	}
+	tests := []struct {
+		name   string
+		filter FeedFilter
+		title  string
+		want   []string
+	}{
+		{"全站", FeedFilter{}, "博客", []string{"Rust 入门", "Go 入门"}},
+		{"按标签（大小写不敏感）", FeedFilter{Tag: "go"}, "博客 - 标签：go", []string{"Go 入门"}},
+		{"按作者", FeedFilter{Author: "bob"}, "博客 - 作者：bob", []string{"Rust 入门"}},
+	}
+	digests := map[string]bool{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := feed(tt.filter)
+			var got []string
+			for _, item := range f.Items {
+				got = append(got, item.Title)
+			}
+			if f.Title != tt.title || !slices.Equal(got, tt.want) {
+				t.Errorf("订阅源 %q = %q, want %q %q", f.Title, got, tt.title, tt.want)
+			}
+			if digests[f.Digest] {
+				t.Errorf("不同订阅源的摘要相同: %s", f.Digest)
+			}
+			digests[f.Digest] = true
+		})
+	}
+
+	// 内容不变时摘要不变，文章更新后摘要与更新时间随之变化
+	before := feed(FeedFilter{})
+	if again := feed(FeedFilter{}); again.Digest != before.Digest || !again.Updated.Equal(before.Updated) {
+		t.Errorf("内容未变化时摘要 %s → %s", before.Digest, again.Digest)
+	}
+	title := "Go 进阶"
+	if _, err := blogService.UpdateBlog(ids[0], UpdateBlogInput{Title: &title}); err != nil {
+		t.Fatal(err)
+	}
+	after := feed(FeedFilter{})
+	if after.Digest == before.Digest || after.Updated.Before(before.Updated) {
+		t.Errorf("更新后摘要 %s → %s, 更新时间 %v → %v", before.Digest, after.Digest, before.Updated, after.Updated)
+	}
+	// 草稿的修改不影响订阅源
+	if _, err := blogService.UpdateBlog(ids[2], UpdateBlogInput{Title: &title}); err != nil {
+		t.Fatal(err)
+	}
+	if again := feed(FeedFilter{}); again.Digest != after.Digest {
+		t.Errorf("修改草稿后摘要 %s → %s", after.Digest, again.Digest)
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/yaml.v3"
+)
+
+// errNoFrontMatter 文件开头没有 front matter
+var errNoFrontMatter = errors.New("缺少 front matter")
+
+// markdownPost 从 Markdown 文件解析出的文章
+type markdownPost struct {
+	ID               primitive.ObjectID // front matter 中指定的文章ID（导出的文件），未指定时为零值
+	Title            string
+	Slug             string // front matter 中指定的 slug，未指定时为空
+	Author           string // front matter 中指定的作者，未指定时为空
+	Date             time.Time
+	Updated          time.Time
+	Tags             []string
+	Categories       []string // 分类路径，由上级到下级（Hexo 的约定）
+	Draft            bool
+	CommentsDisabled bool
+	Views            *int64     // 浏览次数，未指定时为 nil
+	PublishAt        *time.Time // 定时发布时间，未指定时为 nil
+	UnpublishAt      *time.Time // 定时下线时间，未指定时为 nil
+	Content          string
+}
+
+// frontMatterTimeLayouts front matter 中可用的时间格式，带时区的格式须排在前面
+var frontMatterTimeLayouts = []struct {
+	layout string
+	zoned  bool
+}{
+	{time.RFC3339, true},
+	{"2006-01-02 15:04:05Z07:00", true},
+	{"2006-01-02 15:04:05 -0700", true},
+	{"2006-01-02 15:04:05 Z07:00", true},
+	{"2006-01-02T15:04:05", false},
+	{"2006-01-02 15:04:05", false},
+	{"2006-01-02 15:04", false},
+	{"2006/01/02 15:04:05", false},
+	{"2006/01/02 15:04", false},
+	{"2006-01-02", false},
+	{"2006/01/02", false},
+}
+
+// parseMarkdownPost 解析 Markdown 文件，支持 --- 包围的 YAML 与 +++ 包围的 TOML front matter
+// 未带时区的时间按 loc 解释
+func parseMarkdownPost(data []byte, loc *time.Location) (*markdownPost, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	delim := string(bytes.TrimSuffix(line, []byte("\r")))
+	if delim != "---" && delim != "+++" {
+		return nil, errNoFrontMatter
+	}
+	front, body, ok := cutFrontMatter(rest, delim)
+	if !ok {
+		return nil, fmt.Errorf("front matter 缺少结束的 %s", delim)
+	}
+
+	fields := map[string]any{}
+	var err error
+	if delim == "---" {
+		if fields, err = decodeYAMLFrontMatter(front); err != nil {
+			return nil, fmt.Errorf("解析 YAML front matter 失败: %w", err)
+		}
+	} else if _, err = toml.Decode(string(front), &fields); err != nil {
+		return nil, fmt.Errorf("解析 TOML front matter 失败: %w", err)
+	}
+	// 正文保持原样，只去掉 front matter 之后的一个空行
+	if b, ok := bytes.CutPrefix(body, []byte("\n")); ok {
+		body = b
+	} else if b, ok := bytes.CutPrefix(body, []byte("\r\n")); ok {
+		body = b
+	}
+
+	post := &markdownPost{
+		Title:      strings.TrimSpace(frontMatterString(fields["title"])),
+		Slug:       strings.TrimSpace(frontMatterString(fields["slug"])),
+		Author:     strings.TrimSpace(frontMatterString(fields["author"])),
+		Tags:       frontMatterStrings(fields["tags"]),
+		Categories: frontMatterCategories(firstField(fields, "categories", "category")),
+		Content:    string(body),
+	}
+
+	if id, err := primitive.ObjectIDFromHex(frontMatterString(fields["id"])); err == nil {
+		post.ID = id
+	}
+
+	if post.Date, err = frontMatterTime(firstField(fields, "date", "created"), loc); err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+	if post.Updated, err = frontMatterTime(firstField(fields, "updated", "lastmod"), loc); err != nil {
+		return nil, fmt.Errorf("updated: %w", err)
+	}
+	var publishAt, unpublishAt time.Time
+	if publishAt, err = frontMatterTime(fields["publish_at"], loc); err != nil {
+		return nil, fmt.Errorf("publish_at: %w", err)
+	}
+	if unpublishAt, err = frontMatterTime(fields["unpublish_at"], loc); err != nil {
+		return nil, fmt.Errorf("unpublish_at: %w", err)
+	}
+	post.PublishAt, post.UnpublishAt = optionalTime(publishAt), optionalTime(unpublishAt)
+	// Hugo 使用 draft: true，Hexo 使用 published: false，导出的文件使用 show
+	if draft, ok := fields["draft"].(bool); ok {
+		post.Draft = draft
+	}
+	if published, ok := fields["published"].(bool); ok && !published {
+		post.Draft = true
+	}
+	if show, ok := fields["show"].(bool); ok {
+		post.Draft = !show
+	}
+	if comments, ok := fields["comments"].(bool); ok {
+		post.CommentsDisabled = !comments
+	}
+	switch views := fields["views"].(type) {
+	case int:
+		n := int64(views)
+		post.Views = &n
+	case int64:
+		post.Views = &views
+	}
+	return post, nil
+}
+
+// cutFrontMatter 在 data 中查找单独成行的结束分隔符（允许 \r\n 换行），返回其前的 front matter 与其后的正文
+func cutFrontMatter(data []byte, delim string) (front, body []byte, ok bool) {
+	for start := 0; start < len(data); {
+		end := len(data)
+		next := end
+		if i := bytes.IndexByte(data[start:], '\n'); i >= 0 {
+			end = start + i
+			next = end + 1
+		}
+		if string(bytes.TrimSuffix(data[start:end], []byte("\r"))) == delim {
+			return data[:start], data[next:], true
+		}
+		start = next
+	}
+	return nil, nil, false
+}
+
+// decodeYAMLFrontMatter 解析 YAML front matter，时间保留为原始字符串，
+// 以便不带时区的时间按指定时区解释（yaml.v3 会将其视为 UTC）
+func decodeYAMLFrontMatter(front []byte) (map[string]any, error) {
+	fields := map[string]any{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(front, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return fields, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("front matter 不是键值对")
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.ShortTag() == "!!timestamp" {
+			fields[key] = value.Value
+			continue
+		}
+		var v any
+		if err := value.Decode(&v); err != nil {
+			return nil, err
+		}
+		fields[key] = v
+	}
+	return fields, nil
+}
+
+// firstField 返回 keys 中第一个存在的字段值
+func firstField(fields map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// frontMatterString 将标量字段转换为字符串，其他类型返回空字符串
+func frontMatterString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int, int64, float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// frontMatterStrings 读取字符串列表，单个标量视为只有一项的列表
+func frontMatterStrings(v any) []string {
+	var items []any
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+	list := []string{}
+	for _, item := range items {
+		if s := strings.TrimSpace(frontMatterString(item)); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// frontMatterCategories 读取分类路径；Hexo 中 [A, B] 表示 A 下的子分类 B，
+// [[A, B], [C]] 表示多个分类，文章只能属于一个分类，取第一个
+func frontMatterCategories(v any) []string {
+	if list, ok := v.([]any); ok && len(list) > 0 {
+		if first, ok := list[0].([]any); ok {
+			return frontMatterStrings(first)
+		}
+	}
+	return frontMatterStrings(v)
+}
+
+// frontMatterTime 读取时间字段，字段不存在时返回零值
+func frontMatterTime(v any, loc *time.Location) (time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		// TOML 中不带时区的日期时间以这两个时区名称标记
+		if name := v.Location().String(); name == "datetime-local" || name == "date-local" {
+			return time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), loc), nil
+		}
+		return v, nil
+	case int:
+		// Unix 时间戳
+		return time.Unix(int64(v), 0), nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return time.Time{}, nil
+		}
+		for _, f := range frontMatterTimeLayouts {
+			var t time.Time
+			var err error
+			if f.zoned {
+				t, err = time.Parse(f.layout, s)
+			} else {
+				t, err = time.ParseInLocation(f.layout, s, loc)
+			}
+			if err == nil {
+				return t, nil
+			}
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Unix(n, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %v", v)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseMarkdownPost(t *testing.T) {
+	shanghai := time.FixedZone("Asia/Shanghai", 8*3600)
+	at := func(s string) time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		check func(t *testing.T, post *markdownPost)
+	}{
+		{
+			name:  "YAML",
+			input: "---\ntitle: \" 你好 \"\nslug: hello\nauthor: alice\ndate: 2024-03-01 10:00:00\ntags: [Go, 博客]\n---\n\n正文\n",
+			check: func(t *testing.T, post *markdownPost) {
+				if post.Title != "你好" || post.Slug != "hello" || post.Author != "alice" {
+					t.Errorf("title/slug/author = %q/%q/%q", post.Title, post.Slug, post.Author)
+				}
+				if !slices.Equal(post.Tags, []string{"Go", "博客"}) {
+					t.Errorf("tags = %q", post.Tags)
+				}
+				// 不带时区的时间按指定时区解释
+				if !post.Date.Equal(at("2024-03-01T10:00:00+08:00")) {
+					t.Errorf("date = %v", post.Date)
+				}
+				if post.Content != "正文\n" {
+					t.Errorf("content = %q", post.Content)
+				}
+			},
+		},
+		{
+			name:  "TOML",
+			input: "+++\ntitle = \"Hugo\"\ndate = 2024-03-01T10:00:00\nlastmod = 2024-03-02T10:00:00Z\ntags = [\"go\"]\ndraft = true\n+++\n正文",
+			check: func(t *testing.T, post *markdownPost) {
+				if post.Title != "Hugo" || !post.Draft || !slices.Equal(post.Tags, []string{"go"}) {
+					t.Errorf("post = %+v", post)
+				}
+				if !post.Date.Equal(at("2024-03-01T10:00:00+08:00")) {
+					t.Errorf("date = %v", post.Date)
+				}
+				if !post.Updated.Equal(at("2024-03-02T10:00:00Z")) {
+					t.Errorf("updated = %v", post.Updated)
+				}
+				if post.Content != "正文" {
+					t.Errorf("content = %q", post.Content)
+				}
+			},
+		},
+		{
+			name:  "CRLF 换行与 BOM",
+			input: "\ufeff---\r\ntitle: Windows\r\n---\r\n\r\n第一行\r\n第二行",
+			check: func(t *testing.T, post *markdownPost) {
+				if post.Title != "Windows" {
+					t.Errorf("title = %q", post.Title)
+				}
+				// 正文保持原样
+				if post.Content != "第一行\r\n第二行" {
+					t.Errorf("content = %q", post.Content)
+				}
+			},
+		},
+		{
+			name:  "带时区的时间与时间戳",
+			input: "---\ndate: 2024-03-01T10:00:00Z\nupdated: 1709290800\npublish_at: 2024-03-01 10:00:00 +0900\nunpublish_at: \"2025/01/02\"\n---\n",
+			check: func(t *testing.T, post *markdownPost) {
+				if !post.Date.Equal(at("2024-03-01T10:00:00Z")) {
+					t.Errorf("date = %v", post.Date)
+				}
+				if !post.Updated.Equal(time.Unix(1709290800, 0)) {
+					t.Errorf("updated = %v", post.Updated)
+				}
+				if post.PublishAt == nil || !post.PublishAt.Equal(at("2024-03-01T10:00:00+09:00")) {
+					t.Errorf("publish_at = %v", post.PublishAt)
+				}
+				if post.UnpublishAt == nil || !post.UnpublishAt.Equal(at("2025-01-02T00:00:00+08:00")) {
+					t.Errorf("unpublish_at = %v", post.UnpublishAt)
+				}
+			},
+		},
+		{
+			name:  "Hexo 的 published 与分类路径",
+			input: "---\ntitle: Hexo\npublished: false\ncategories:\n  - [技术, Go]\n  - [生活]\ncomments: false\n---\n",
+			check: func(t *testing.T, post *markdownPost) {
+				if !post.Draft || !post.CommentsDisabled {
+					t.Errorf("draft/commentsDisabled = %v/%v", post.Draft, post.CommentsDisabled)
+				}
+				if !slices.Equal(post.Categories, []string{"技术", "Go"}) {
+					t.Errorf("categories = %q", post.Categories)
+				}
+			},
+		},
+		{
+			name:  "单个分类与单个标签",
+			input: "---\ncategory: 随笔\ntags: go\n---\n",
+			check: func(t *testing.T, post *markdownPost) {
+				if !slices.Equal(post.Categories, []string{"随笔"}) || !slices.Equal(post.Tags, []string{"go"}) {
+					t.Errorf("categories/tags = %q/%q", post.Categories, post.Tags)
+				}
+			},
+		},
+		{
+			name:  "导出文件的 show、id 与 views",
+			input: "---\nid: 65e1a0000000000000000001\nshow: true\ndraft: true\nviews: 42\n---\n",
+			check: func(t *testing.T, post *markdownPost) {
+				// show 优先于 draft
+				if post.Draft {
+					t.Error("show: true 时不应为草稿")
+				}
+				if post.ID.Hex() != "65e1a0000000000000000001" {
+					t.Errorf("id = %s", post.ID.Hex())
+				}
+				if post.Views == nil || *post.Views != 42 {
+					t.Errorf("views = %v", post.Views)
+				}
+			},
+		},
+		{
+			name:  "正文中的分隔线不结束 front matter",
+			input: "---\ntitle: 分隔线\n---\n上文\n\n---\n\n下文",
+			check: func(t *testing.T, post *markdownPost) {
+				if post.Content != "上文\n\n---\n\n下文" {
+					t.Errorf("content = %q", post.Content)
+				}
+			},
+		},
+		{
+			name:  "空 front matter",
+			input: "---\n---\n正文",
+			check: func(t *testing.T, post *markdownPost) {
+				if post.Title != "" || post.Views != nil || post.PublishAt != nil || !post.Date.IsZero() {
+					t.Errorf("post = %+v", post)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, err := parseMarkdownPost([]byte(tt.input), shanghai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, post)
+		})
+	}
+}
+
+func TestParseMarkdownPostErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		noMeta bool // 期望 errNoFrontMatter
+	}{
+		{"没有 front matter", "# 标题\n正文", true},
+		{"空文件", "", true},
+		{"缺少结束分隔符", "---\ntitle: x\n正文", false},
+		{"分隔符不匹配", "---\ntitle: x\n+++\n", false},
+		{"YAML 语法错误", "---\ntitle: [x\n---\n", false},
+		{"YAML 不是键值对", "---\n- a\n- b\n---\n", false},
+		{"TOML 语法错误", "+++\ntitle = \n+++\n", false},
+		{"无效的时间", "---\ndate: 昨天\n---\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseMarkdownPost([]byte(tt.input), time.UTC)
+			if err == nil {
+				t.Fatal("应返回错误")
+			}
+			if errors.Is(err, errNoFrontMatter) != tt.noMeta {
+				t.Errorf("err = %v, errNoFrontMatter = %v", err, tt.noMeta)
+			}
+		})
+	}
+}
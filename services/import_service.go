@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"blog/models"
+)
+
+// ImportOptions 导入 Markdown 文章的选项
+type ImportOptions struct {
+	DryRun   bool           // 只报告将要执行的操作，不写入任何数据
+	Author   string         // front matter 未指定作者时使用的作者
+	Location *time.Location // front matter 中不带时区的时间所在的时区
+	NoSource bool           // 不按源文件路径匹配，也不记录源文件路径，从导出归档恢复时使用
+}
+
+// ImportAction 对单个文件执行的操作
+type ImportAction string
+
+const (
+	ImportCreated ImportAction = "created" // 创建了新文章
+	ImportUpdated ImportAction = "updated" // 更新了已导入的文章
+	ImportSkipped ImportAction = "skipped" // 文章没有变化或文件不是文章
+	ImportFailed  ImportAction = "failed"  // 文件无法解析或写入失败
+)
+
+// ImportResult 单个文件的导入结果
+type ImportResult struct {
+	Path   string // 相对导入目录的路径，以 / 分隔
+	Action ImportAction
+	Slug   string // 对应文章的 slug
+	Reason string // 跳过或失败的原因
+}
+
+// ImportService 从 Hexo、Hugo 等静态博客的 Markdown 文件导入文章
+type ImportService struct {
+	blogService     *BlogService
+	categoryService *CategoryService
+}
+
+// NewImportService 创建新的ImportService实例
+func NewImportService(blogService *BlogService, categoryService *CategoryService) *ImportService {
+	return &ImportService{
+		blogService:     blogService,
+		categoryService: categoryService,
+	}
+}
+
+// markdownImport 一次导入过程中的状态
+type markdownImport struct {
+	*ImportService
+	options    ImportOptions
+	bySource   map[string]*models.Blog // 已导入的文章，键为源文件路径
+	slugs      map[string]string       // 本次导入已使用的 slug 及其源文件，用于试运行时发现冲突
+	categories []*models.Category
+}
+
+// ImportMarkdown 递归导入 dir 下的 .md 文件（忽略以 . 开头的文件与目录），按路径顺序返回每个文件的结果
+// 文章依次按源文件路径、front matter 中的 id 与 slug 匹配已有文章：匹配到时只在内容有变化时更新，否则创建新文章；
+// 创建与更新时间取自 front matter 的 date 与 updated（缺失时取文件修改时间），
+// _drafts 目录下的文件及标记为草稿的文章导入后不展示
+func (s *ImportService) ImportMarkdown(dir string, options ImportOptions) ([]*ImportResult, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && isMarkdownFile(d.Name()) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imp, err := s.newMarkdownImport(options)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*ImportResult, 0, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		result := &ImportResult{Path: filepath.ToSlash(rel)}
+		if err := imp.importFile(file, result); err != nil {
+			result.Action = ImportFailed
+			result.Reason = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// newMarkdownImport 读取已有文章（包括回收站中的文章）与分类
+func (s *ImportService) newMarkdownImport(options ImportOptions) (*markdownImport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	store := s.blogService.store
+	blogs, err := store.ListBlogs(ctx, BlogQuery{})
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := store.ListBlogs(ctx, BlogQuery{Trashed: true})
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.blogService.categories.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &markdownImport{
+		ImportService: s,
+		options:       options,
+		bySource:      make(map[string]*models.Blog),
+		slugs:         make(map[string]string),
+		categories:    categories,
+	}
+	for _, blog := range append(blogs, trashed...) {
+		if blog.Source != "" && !options.NoSource {
+			imp.bySource[blog.Source] = blog
+		}
+	}
+	return imp, nil
+}
+
+// importFile 导入单个文件，将执行的操作写入 result
+func (imp *markdownImport) importFile(file string, result *ImportResult) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	post, err := parseMarkdownPost(data, imp.options.Location)
+	if errors.Is(err, errNoFrontMatter) {
+		result.Action = ImportSkipped
+		result.Reason = err.Error()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if post.Title == "" {
+		return errors.New("缺少标题")
+	}
+	if post.Date.IsZero() {
+		post.Date = info.ModTime()
+	}
+	if post.Updated.IsZero() {
+		post.Updated = info.ModTime()
+	}
+	if slices.Contains(strings.Split(result.Path, "/"), "_drafts") {
+		post.Draft = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 先按源文件路径匹配；front matter 指定的 id 与 slug 也可匹配未记录来源的文章（如从导出文件恢复）
+	explicitSlug := importSlug(post.Slug)
+	existing, bySource := imp.bySource[result.Path], true
+	if existing == nil && !post.ID.IsZero() {
+		bySource = false
+		existing, err = imp.blogService.store.GetBlogByID(ctx, post.ID)
+		if errors.Is(err, ErrBlogNotFound) {
+			existing = nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if existing == nil && explicitSlug != "" {
+		bySource = false
+		existing, err = imp.blogService.store.GetBlogBySlug(ctx, explicitSlug)
+		if errors.Is(err, ErrBlogNotFound) {
+			existing = nil
+		} else if err != nil {
+			return err
+		} else if existing.Source != "" && existing.Source != result.Path {
+			return errors.New("slug " + explicitSlug + " 已被 " + existing.Source + " 导入的文章使用")
+		}
+	}
+	if existing != nil {
+		result.Slug = existing.Slug
+		if existing.DeletedAt != nil {
+			result.Action = ImportSkipped
+			result.Reason = "文章在回收站中"
+			return nil
+		}
+		return imp.updatePost(ctx, existing, post, bySource, explicitSlug, result)
+	}
+	return imp.createPost(ctx, post, explicitSlug, result)
+}
+
+// createPost 创建新文章，front matter 未指定 slug 时由文件名生成
+func (imp *markdownImport) createPost(ctx context.Context, post *markdownPost, slug string, result *ImportResult) error {
+	if slug == "" {
+		name := path.Base(result.Path)
+		base := Slugify(strings.TrimSuffix(name, path.Ext(name)))
+		if base == "" {
+			base = Slugify(post.Title)
+		}
+		var err error
+		if slug, err = imp.uniqueSlug(ctx, base); err != nil {
+			return err
+		}
+	} else if source := imp.slugs[slug]; source != "" {
+		return errors.New("slug " + slug + " 已被 " + source + " 使用")
+	}
+	result.Slug = slug
+
+	categoryID, err := imp.categoryID(post.Categories)
+	if err != nil {
+		return err
+	}
+	author := post.Author
+	if author == "" {
+		author = imp.options.Author
+	}
+
+	result.Action = ImportCreated
+	imp.slugs[slug] = result.Path
+	if imp.options.DryRun {
+		return nil
+	}
+	input := CreateBlogInput{
+		Title:            post.Title,
+		Content:          post.Content,
+		Author:           author,
+		Tags:             post.Tags,
+		Show:             !post.Draft,
+		Slug:             slug,
+		CategoryID:       categoryID,
+		CommentsDisabled: post.CommentsDisabled,
+		PublishAt:        post.PublishAt,
+		UnpublishAt:      post.UnpublishAt,
+		ID:               post.ID,
+		CreatedAt:        post.Date,
+		UpdatedAt:        post.Updated,
+	}
+	if !imp.options.NoSource {
+		input.Source = result.Path
+	}
+	if post.Views != nil {
+		input.Views = *post.Views
+	}
+	_, err = imp.blogService.CreateBlog(input)
+	return err
+}
+
+// updatePost 将文件内容与已有文章比较，只修改有变化的字段，没有变化时跳过
+// 只有按源文件路径匹配时才会修改 slug，避免通过历史 slug 匹配时改回旧 slug
+func (imp *markdownImport) updatePost(ctx context.Context, blog *models.Blog, post *markdownPost, bySource bool, slug string, result *ImportResult) error {
+	imp.slugs[blog.Slug] = result.Path
+
+	input := UpdateBlogInput{Editor: imp.options.Author, UpdatedAt: post.Updated}
+	changed := false
+	if post.Title != blog.Title {
+		input.Title = &post.Title
+		changed = true
+	}
+	if post.Content != blog.Content {
+		input.Content = &post.Content
+		changed = true
+	}
+	if post.Author != "" && post.Author != blog.Author {
+		input.Author = &post.Author
+		input.Editor = post.Author
+		changed = true
+	}
+	if tags := NormalizeTags(post.Tags); !slices.Equal(tags, blog.Tags) {
+		input.Tags = append([]string{}, tags...)
+		changed = true
+	}
+	if show := !post.Draft; show != blog.Show {
+		input.Show = &show
+		changed = true
+	}
+	if post.CommentsDisabled != blog.CommentsDisabled {
+		input.CommentsDisabled = &post.CommentsDisabled
+		changed = true
+	}
+	if post.Views != nil && *post.Views != blog.Views {
+		input.Views = post.Views
+		changed = true
+	}
+	// 文件中没有定时发布/下线时间时清除文章已有的设置
+	if !equalTime(post.PublishAt, blog.PublishAt) {
+		input.PublishAt = &time.Time{}
+		if post.PublishAt != nil {
+			input.PublishAt = post.PublishAt
+		}
+		changed = true
+	}
+	if !equalTime(post.UnpublishAt, blog.UnpublishAt) {
+		input.UnpublishAt = &time.Time{}
+		if post.UnpublishAt != nil {
+			input.UnpublishAt = post.UnpublishAt
+		}
+		changed = true
+	}
+	if bySource && slug != "" && slug != blog.Slug {
+		if source := imp.slugs[slug]; source != "" {
+			return errors.New("slug " + slug + " 已被 " + source + " 使用")
+		}
+		input.Slug = &slug
+		imp.slugs[slug] = result.Path
+		result.Slug = slug
+		changed = true
+	}
+	categoryID, err := imp.categoryID(post.Categories)
+	if err != nil {
+		return err
+	}
+	current := ""
+	if blog.CategoryID != nil {
+		current = blog.CategoryID.Hex()
+	}
+	if categoryID != current {
+		input.CategoryID = &categoryID
+		changed = true
+	}
+
+	if !changed {
+		result.Action = ImportSkipped
+		result.Reason = "内容没有变化"
+		return nil
+	}
+	result.Action = ImportUpdated
+	if imp.options.DryRun {
+		return nil
+	}
+	_, err = imp.blogService.UpdateBlog(blog.ID.Hex(), input)
+	return err
+}
+
+// uniqueSlug 基于 base 生成未被已有文章与本次导入占用的 slug，冲突时追加 -2、-3…
+// 试运行时文章不会写入，因此须同时检查本次导入已使用的 slug
+func (imp *markdownImport) uniqueSlug(ctx context.Context, base string) (string, error) {
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		if imp.slugs[candidate] != "" {
+			continue
+		}
+		_, err := imp.blogService.store.GetBlogBySlug(ctx, candidate)
+		if errors.Is(err, ErrBlogNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// categoryID 按名称逐级查找分类路径对应的分类，不存在的分类自动创建，路径为空时返回空字符串（未分类）
+// 试运行时不创建分类，不存在的分类返回占位ID
+func (imp *markdownImport) categoryID(names []string) (string, error) {
+	parentID := ""
+	for _, name := range names {
+		var found *models.Category
+		for _, category := range imp.categories {
+			if categoryParentHex(category) == parentID && strings.EqualFold(category.Name, name) {
+				found = category
+				break
+			}
+		}
+		if found == nil {
+			if imp.options.DryRun {
+				return "new:" + strings.Join(names, "/"), nil
+			}
+			category, err := imp.categoryService.CreateCategory(CategoryInput{Name: &name, ParentID: &parentID})
+			if err != nil {
+				return "", err
+			}
+			imp.categories = append(imp.categories, category)
+			found = category
+		}
+		parentID = found.ID.Hex()
+	}
+	return parentID, nil
+}
+
+// importSlug 规范化 front matter 中的 slug，不符合格式时按 Slugify 转换
+func importSlug(slug string) string {
+	if slug == "" || ValidateSlug(slug) == nil {
+		return slug
+	}
+	return Slugify(slug)
+}
+
+// isMarkdownFile 判断文件名是否为 Markdown 文件
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// equalTime 判断两个可为空的时间是否为同一时刻
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// categoryParentHex 返回父分类ID，顶级分类返回空字符串
+func categoryParentHex(category *models.Category) string {
+	if category.ParentID == nil {
+		return ""
+	}
+	return category.ParentID.Hex()
+}
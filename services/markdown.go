@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"blog/models"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// markdown 将 Markdown 转换为 HTML：CommonMark 加 GFM（表格、任务列表、删除线、自动链接）与脚注
+// 允许原始 HTML 通过，统一交由 sanitizer 按白名单清理
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(util.Prioritized(imageSrcset{}, 100)),
+	),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var (
+	tableAlignStyle = regexp.MustCompile(`^text-align:(left|right|center);?$`)
+	codeLanguage    = regexp.MustCompile(`^language-[\w+#.-]+$`)
+	inputType       = regexp.MustCompile(`^checkbox$`)
+	// imageSrcsetValue 只允许相对地址或 http(s) 地址加宽度描述符，与 MediaService.Srcset 的输出一致
+	imageSrcsetValue = regexp.MustCompile(`^(?:(?:https?://|/)[^\s,]+ \d+w(?:, |$))+$`)
+)
+
+// sanitizer 白名单清理渲染结果：移除脚本、事件属性与 javascript: 等不安全链接
+var sanitizer = newSanitizer()
+
+func newSanitizer() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// 任务列表的复选框，只允许 checkbox，避免正文中伪造文本框、按钮等表单控件
+	p.AllowElements("input")
+	p.AllowAttrs("type").Matching(inputType).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	// 脚注与表格对齐
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a", "div", "sup", "li")
+	p.AllowAttrs("role").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a", "div")
+	p.AllowAttrs("style").Matching(tableAlignStyle).OnElements("th", "td")
+	// 代码块语言，供前端语法高亮使用
+	p.AllowAttrs("class").Matching(codeLanguage).OnElements("code")
+	// 上传图片的缩略图
+	p.AllowAttrs("srcset").Matching(imageSrcsetValue).OnElements("img")
+	return p
+}
+
+// SrcsetFunc 根据图片地址生成 srcset，不需要时返回空字符串
+type SrcsetFunc func(src string) string
+
+// RenderMarkdown 将 Markdown 渲染为经过清理的 HTML
+func RenderMarkdown(source string) string {
+	return renderMarkdown(source, nil)
+}
+
+// renderMarkdown 渲染 Markdown，srcset 不为 nil 时为图片添加 srcset 属性
+func renderMarkdown(source string, srcset SrcsetFunc) string {
+	var buf bytes.Buffer
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	if srcset != nil {
+		ctx.Set(imageSrcsetKey, srcset)
+	}
+	if err := markdown.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+		// goldmark 只会在写入失败时返回错误，写入内存缓冲区不会失败
+		panic(err)
+	}
+	return sanitizer.Sanitize(buf.String())
+}
+
+// imageSrcsetKey 在解析上下文中保存本次渲染使用的 SrcsetFunc
+var imageSrcsetKey = parser.NewContextKey()
+
+// imageSrcset 为图片节点设置 srcset 属性，解析上下文中没有 SrcsetFunc 时不做处理
+type imageSrcset struct{}
+
+// Transform 实现 parser.ASTTransformer
+func (imageSrcset) Transform(doc *ast.Document, _ text.Reader, pc parser.Context) {
+	srcset, ok := pc.Get(imageSrcsetKey).(SrcsetFunc)
+	if !ok {
+		return
+	}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			if value := srcset(string(img.Destination)); value != "" {
+				img.SetAttributeString("srcset", []byte(value))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// headingIDs 为标题生成稳定的锚点：与 slug 规则一致（汉字转拼音），重复时追加 -2、-3…
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: make(map[string]bool)}
+}
+
+// Generate 实现 parser.IDs
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := Slugify(string(value))
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	for i := 2; h.used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	h.used[id] = true
+	return []byte(id)
+}
+
+// Put 实现 parser.IDs，记录手动指定的 ID
+func (h *headingIDs) Put(value []byte) {
+	h.used[string(value)] = true
+}
+
+// renderCache 按文章缓存渲染结果，文章版本变化后自动失效
+type renderCache struct {
+	mu      sync.RWMutex
+	entries map[primitive.ObjectID]renderEntry
+	srcset  SrcsetFunc // 为正文中的图片生成 srcset，为 nil 时不生成
+}
+
+type renderEntry struct {
+	version int64
+	html    string
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{entries: make(map[primitive.ObjectID]renderEntry)}
+}
+
+// render 返回文章当前版本的 HTML，未命中缓存时渲染并写入
+func (c *renderCache) render(blog *models.Blog) string {
+	c.mu.RLock()
+	entry, ok := c.entries[blog.ID]
+	c.mu.RUnlock()
+	if ok && entry.version == blog.Version {
+		return entry.html
+	}
+
+	html := renderMarkdown(blog.Content, c.srcset)
+	c.mu.Lock()
+	c.entries[blog.ID] = renderEntry{version: blog.Version, html: html}
+	c.mu.Unlock()
+	return html
+}
+
+// remove 删除文章的缓存，文章被永久删除时调用
+func (c *renderCache) remove(id primitive.ObjectID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+}
+
+// commentMarkdown 评论使用的简化 Markdown：强调、行内代码、代码块、引用、列表与自动链接，
+// 单个换行即换行，原始 HTML 不输出
+var commentMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.Linkify, extension.Strikethrough),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+// commentSanitizer 评论的白名单比正文严格：不允许标题、图片与表格，链接加 rel="nofollow"
+var commentSanitizer = newCommentSanitizer()
+
+func newCommentSanitizer() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "em", "strong", "del", "code", "pre", "blockquote", "ul", "ol", "li")
+	p.AllowStandardURLs()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// RenderCommentMarkdown 将评论内容渲染为经过清理的 HTML
+func RenderCommentMarkdown(source string) string {
+	var buf bytes.Buffer
+	if err := commentMarkdown.Convert([]byte(source), &buf); err != nil {
+		// goldmark 只会在写入失败时返回错误，写入内存缓冲区不会失败
+		panic(err)
+	}
+	return commentSanitizer.Sanitize(buf.String())
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string // 渲染结果须包含的片段
+		notWant []string // 渲染结果不能包含的片段
+	}{
+		{
+			name:   "任务列表保留复选框",
+			source: "- [x] 完成\n- [ ] 待办",
+			want:   []string{`<input checked="" disabled="" type="checkbox">`, `<input disabled="" type="checkbox">`},
+		},
+		{name: "文本框被移除", source: `<input type="text" value="账号">`, notWant: []string{"<input", "账号"}},
+		{name: "密码框被移除", source: `<input type="password">`, notWant: []string{"<input"}},
+		{name: "隐藏字段被移除", source: `<input type="hidden" name="token">`, notWant: []string{"<input", "hidden"}},
+		{name: "多个类型不被接受", source: `<input type="checkbox text">`, notWant: []string{"type="}},
+		{name: "按钮类型被移除", source: `<input type="submit" disabled>`, notWant: []string{"submit"}},
+		{name: "脚本被移除", source: "<script>alert(1)</script>", notWant: []string{"script", "alert"}},
+		{name: "javascript 链接被移除", source: "[x](javascript:alert(1))", notWant: []string{"javascript"}},
+		{name: "事件属性被移除", source: `<img src="/media/a" onerror="alert(1)">`, want: []string{`<img src="/media/a">`}, notWant: []string{"onerror"}},
+		{name: "代码块语言", source: "```go\nx\n```", want: []string{`<code class="language-go">`}},
+		{name: "表格对齐", source: "| a |\n|:-:|\n| b |", want: []string{`<th style="text-align:center">`}},
+		{name: "表格中的其他样式被移除", source: `<table><tr><td style="color:red">x</td></tr></table>`, notWant: []string{"color"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderMarkdown(tt.source)
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("RenderMarkdown(%q) = %q, 缺少 %q", tt.source, got, s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("RenderMarkdown(%q) = %q, 不应包含 %q", tt.source, got, s)
+				}
+			}
+		})
+	}
+}
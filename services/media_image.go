@@ -0,0 +1,366 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+// errMalformedImage 图片结构无法解析
+var errMalformedImage = errors.New("图片格式错误")
+
+const (
+	// maxMediaPixels 单张图片（GIF 为画布）允许的最大像素数，避免解码时占用过多内存
+	maxMediaPixels = 40_000_000
+	// mediaJPEGQuality 旋转后重新编码与生成 JPEG 缩略图使用的质量
+	mediaJPEGQuality = 85
+)
+
+// cleanImage 移除图片中的 EXIF、XMP、IPTC、注释等元数据（包括 GPS 位置与拍摄设备信息）
+// 方向标记不为正常时按其旋转或翻转图片后重新编码（WebP 编码为 PNG），否则无损地删除元数据，不改变像素
+// 返回处理后的内容与 MIME 类型
+func cleanImage(data []byte, contentType string) ([]byte, string, error) {
+	var (
+		cleaned     []byte
+		orientation int
+		err         error
+	)
+	switch contentType {
+	case "image/jpeg":
+		cleaned, orientation, err = cleanJPEG(data)
+	case "image/png":
+		cleaned, orientation, err = cleanPNG(data)
+	case "image/webp":
+		cleaned, orientation, err = cleanWebP(data)
+	case "image/gif":
+		cleaned, err = cleanGIF(data)
+	default:
+		return nil, "", ErrUnsupportedMedia
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if orientation <= 1 {
+		return cleaned, contentType, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(cleaned))
+	if err != nil {
+		return nil, "", err
+	}
+	img = orient(img, orientation)
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: mediaJPEGQuality})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), contentType, nil
+}
+
+// cleanJPEG 删除 EXIF/XMP（APP1）、IPTC（APP13）、注释及其他应用段，只保留 JFIF、ICC 色彩配置与 Adobe 段，
+// 并丢弃结束标记之后附加的数据（如动态照片中的视频）
+func cleanJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, errMalformedImage
+	}
+	out := []byte{0xFF, 0xD8}
+	orientation := 1
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, 0, errMalformedImage
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// 标记前的填充字节
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			// 扫描数据中的 0xFF 均经过转义，第一个 EOI 即为图片结束
+			end := bytes.Index(data[i:], []byte{0xFF, 0xD9})
+			if end < 0 {
+				return nil, 0, errMalformedImage
+			}
+			return append(out, data[i:i+end+2]...), orientation, nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		segment := data[i : i+2+length]
+		payload := segment[4:]
+		keep := true
+		switch {
+		case marker == 0xE1:
+			if exif, ok := bytes.CutPrefix(payload, []byte("Exif\x00\x00")); ok {
+				orientation = tiffOrientation(exif)
+			}
+			keep = false
+		case marker == 0xE0:
+			keep = bytes.HasPrefix(payload, []byte("JFIF\x00"))
+		case marker == 0xE2:
+			keep = bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+		case marker == 0xEE:
+			keep = bytes.HasPrefix(payload, []byte("Adobe"))
+		case marker >= 0xE3 && marker <= 0xEF, marker == 0xFE:
+			keep = false
+		}
+		if keep {
+			out = append(out, segment...)
+		}
+		i += len(segment)
+	}
+}
+
+// pngKeepChunks 除关键块外保留的 PNG 辅助块：透明度、色彩空间与 APNG 动画
+var pngKeepChunks = map[string]bool{
+	"tRNS": true, "gAMA": true, "cHRM": true, "sRGB": true, "iCCP": true, "sBIT": true, "bKGD": true, "pHYs": true,
+	"acTL": true, "fcTL": true, "fdAT": true,
+}
+
+// cleanPNG 删除文本（tEXt/zTXt/iTXt）、eXIf、tIME 等辅助块及 IEND 之后的数据
+func cleanPNG(data []byte) ([]byte, int, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, 0, errMalformedImage
+	}
+	out := []byte(signature)
+	orientation := 1
+	for i := len(signature); ; {
+		if i+12 > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		if length < 0 || i+12+length > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		chunk := data[i : i+12+length]
+		kind := string(chunk[4:8])
+		switch {
+		case kind == "eXIf":
+			orientation = tiffOrientation(chunk[8 : 8+length])
+		case kind[0] >= 'A' && kind[0] <= 'Z', pngKeepChunks[kind]:
+			out = append(out, chunk...)
+		}
+		if kind == "IEND" {
+			return out, orientation, nil
+		}
+		i += len(chunk)
+	}
+}
+
+// cleanWebP 删除 EXIF 与 XMP 块，并清除 VP8X 中对应的标志位
+func cleanWebP(data []byte) ([]byte, int, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, 0, errMalformedImage
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if size < 4 || 8+size > len(data) {
+		return nil, 0, errMalformedImage
+	}
+	data = data[:8+size]
+
+	out := append([]byte{}, data[:12]...)
+	orientation := 1
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		length := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + length + length%2
+		if length < 0 || end > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		chunk := data[i:end]
+		switch string(chunk[:4]) {
+		case "EXIF":
+			exif := chunk[8 : 8+length]
+			exif, _ = bytes.CutPrefix(exif, []byte("Exif\x00\x00"))
+			orientation = tiffOrientation(exif)
+		case "XMP ":
+			// 删除
+		case "VP8X":
+			chunk = append([]byte{}, chunk...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x04 | 0x08
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, chunk...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, orientation, nil
+}
+
+// cleanGIF 删除注释扩展与除循环设置外的应用扩展（如 XMP），不解码图像数据
+func cleanGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, errMalformedImage
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+	if i > len(data) {
+		return nil, errMalformedImage
+	}
+	out := append([]byte{}, data[:i]...)
+
+	// skipSubBlocks 返回从 j 开始的数据子块序列（以长度 0 结束）之后的位置
+	skipSubBlocks := func(j int) (int, error) {
+		for j < len(data) {
+			n := int(data[j])
+			j += 1 + n
+			if n == 0 {
+				return j, nil
+			}
+		}
+		return 0, errMalformedImage
+	}
+	for i < len(data) {
+		switch data[i] {
+		case 0x3B: // 文件结束
+			return append(out, 0x3B), nil
+		case 0x21: // 扩展
+			if i+2 > len(data) {
+				return nil, errMalformedImage
+			}
+			label := data[i+1]
+			end, err := skipSubBlocks(i + 2)
+			if err != nil {
+				return nil, err
+			}
+			keep := label != 0xFE
+			if label == 0xFF {
+				app := data[i+2 : end]
+				keep = bytes.HasPrefix(app, []byte("\x0bNETSCAPE2.0")) || bytes.HasPrefix(app, []byte("\x0bANIMEXTS1.0"))
+			}
+			if keep {
+				out = append(out, data[i:end]...)
+			}
+			i = end
+		case 0x2C: // 图像
+			j := i + 10
+			if j > len(data) {
+				return nil, errMalformedImage
+			}
+			if data[i+9]&0x80 != 0 {
+				j += 3 << (data[i+9]&0x07 + 1)
+			}
+			end, err := skipSubBlocks(j + 1) // 跳过 LZW 最小码长
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, data[i:end]...)
+			i = end
+		default:
+			return nil, errMalformedImage
+		}
+	}
+	// 缺少结束标记的文件可以正常显示，补上结束标记
+	return append(out, 0x3B), nil
+}
+
+// tiffOrientation 从 TIFF 格式的 EXIF 数据中读取方向标记（1-8），缺失或无法解析时返回 1
+func tiffOrientation(b []byte) int {
+	if len(b) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(b[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(b[4:]))
+	if ifd < 8 || ifd+2 > len(b) {
+		return 1
+	}
+	n := int(order.Uint16(b[ifd:]))
+	for k := 0; k < n; k++ {
+		entry := ifd + 2 + 12*k
+		if entry+12 > len(b) {
+			break
+		}
+		// 0x0112 Orientation，类型为 SHORT(3)
+		if order.Uint16(b[entry:]) == 0x0112 && order.Uint16(b[entry+2:]) == 3 {
+			if v := int(order.Uint16(b[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// orient 按 EXIF 方向标记旋转或翻转图片，使其以正常方向显示
+func orient(img image.Image, orientation int) image.Image {
+	src := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	dst := image.NewNRGBA(src.Rect)
+	if orientation >= 5 {
+		dst = image.NewNRGBA(image.Rect(0, 0, h, w))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90°
+				dx, dy = h-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90°
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
+
+// resizeImage 将图片等比缩放到指定宽度并按 format（jpeg 或 png）编码，JPEG 不支持透明，透明部分填充为白色
+func resizeImage(w io.Writer, img image.Image, width int, format string) error {
+	b := img.Bounds()
+	height := max(1, (b.Dy()*width+b.Dx()/2)/b.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	op := draw.Src
+	if format == "jpeg" {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		op = draw.Over
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, op, nil)
+
+	if format == "jpeg" {
+		return jpeg.Encode(w, dst, &jpeg.Options{Quality: mediaJPEGQuality})
+	}
+	return png.Encode(w, dst)
+}
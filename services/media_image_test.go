@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testExif 构造只含方向标记的 TIFF 格式 EXIF 数据（大端序）
+func testExif(orientation int) []byte {
+	b := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, 0x0112)
+	b = binary.BigEndian.AppendUint16(b, 3)
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = binary.BigEndian.AppendUint16(b, uint16(orientation))
+	b = append(b, 0, 0)
+	b = append(b, "GPS secret"...)
+	return binary.BigEndian.AppendUint32(b, 0)
+}
+
+// testImage 生成宽 2 高 1 的图片：左红右蓝
+func testImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	img.Set(1, 0, color.NRGBA{B: 255, A: 255})
+	return img
+}
+
+// jpegSegment 构造 JPEG 标记段
+func jpegSegment(marker byte, payload string) []byte {
+	b := []byte{0xFF, marker}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(payload)+2))
+	return append(b, payload...)
+}
+
+// testJPEG 在编码后的 JPEG 的 SOI 之后插入 segments，并在 EOI 之后追加 trailer
+func testJPEG(t *testing.T, trailer string, segments ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	data := append([]byte{}, encoded[:2]...)
+	for _, s := range segments {
+		data = append(data, s...)
+	}
+	data = append(data, encoded[2:]...)
+	return append(data, trailer...)
+}
+
+func TestCleanJPEG(t *testing.T) {
+	data := testJPEG(t, "motion photo video",
+		jpegSegment(0xE0, "JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"),
+		jpegSegment(0xE1, "Exif\x00\x00"+string(testExif(6))),
+		jpegSegment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>xmp secret</x:xmpmeta>"),
+		jpegSegment(0xE2, "ICC_PROFILE\x00\x01\x01profile"),
+		jpegSegment(0xED, "Photoshop 3.0\x00iptc secret"),
+		jpegSegment(0xFE, "comment secret"),
+		[]byte{0xFF}, // 标记前的填充字节
+		jpegSegment(0xEE, "Adobe\x00\x64\x00\x00\x00\x00\x01"),
+	)
+
+	cleaned, orientation, err := cleanJPEG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orientation != 6 {
+		t.Errorf("orientation = %d, want 6", orientation)
+	}
+	for _, s := range []string{"JFIF", "ICC_PROFILE", "Adobe"} {
+		if !bytes.Contains(cleaned, []byte(s)) {
+			t.Errorf("应保留 %s 段", s)
+		}
+	}
+	for _, s := range []string{"secret", "Exif", "motion photo video"} {
+		if bytes.Contains(cleaned, []byte(s)) {
+			t.Errorf("不应包含 %q", s)
+		}
+	}
+	if !bytes.HasSuffix(cleaned, []byte{0xFF, 0xD9}) {
+		t.Error("应以 EOI 结束")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(cleaned)); err != nil {
+		t.Errorf("清理后无法解码: %v", err)
+	}
+}
+
+func TestCleanJPEGMalformed(t *testing.T) {
+	valid := testJPEG(t, "")
+	sos := bytes.Index(valid, []byte{0xFF, 0xDA})
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空数据", nil},
+		{"不是 JPEG", []byte("GIF89a....")},
+		{"段长度越界", append([]byte{0xFF, 0xD8}, jpegSegment(0xE1, "Exif")[:6]...)},
+		{"段长度小于 2", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01}},
+		{"缺少标记", []byte{0xFF, 0xD8, 0x00, 0x00, 0x00, 0x00}},
+		{"扫描数据没有结束", valid[:len(valid)-2]},
+		{"截断在扫描之前", valid[:sos]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := cleanJPEG(tt.data); err != errMalformedImage {
+				t.Errorf("err = %v, want %v", err, errMalformedImage)
+			}
+		})
+	}
+}
+
+// pngChunk 构造 PNG 块
+func pngChunk(kind, payload string) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	b = append(b, kind...)
+	b = append(b, payload...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE([]byte(kind+payload)))
+}
+
+// testPNG 在编码后的 PNG 的 IHDR 之后插入 chunks，并在 IEND 之后追加 trailer
+func testPNG(t *testing.T, trailer string, chunks ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	ihdrEnd := 8 + 12 + 13
+	data := append([]byte{}, encoded[:ihdrEnd]...)
+	for _, c := range chunks {
+		data = append(data, c...)
+	}
+	data = append(data, encoded[ihdrEnd:]...)
+	return append(data, trailer...)
+}
+
+func TestCleanPNG(t *testing.T) {
+	data := testPNG(t, "appended secret",
+		pngChunk("gAMA", "\x00\x00\xb1\x8f"),
+		pngChunk("tEXt", "Author\x00text secret"),
+		pngChunk("zTXt", "Comment\x00\x00ztxt secret"),
+		pngChunk("iTXt", "XML:com.adobe.xmp\x00\x00\x00\x00\x00itxt secret"),
+		pngChunk("eXIf", string(testExif(3))),
+		pngChunk("tIME", "\x07\xe8\x01\x01\x00\x00\x00"),
+		pngChunk("prVt", "private secret"),
+	)
+
+	cleaned, orientation, err := cleanPNG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orientation != 3 {
+		t.Errorf("orientation = %d, want 3", orientation)
+	}
+	for _, s := range []string{"IHDR", "gAMA", "IDAT", "IEND"} {
+		if !bytes.Contains(cleaned, []byte(s)) {
+			t.Errorf("应保留 %s 块", s)
+		}
+	}
+	for _, s := range []string{"secret", "eXIf", "tIME", "tEXt"} {
+		if bytes.Contains(cleaned, []byte(s)) {
+			t.Errorf("不应包含 %q", s)
+		}
+	}
+	img, err := png.Decode(bytes.NewReader(cleaned))
+	if err != nil {
+		t.Fatalf("清理后无法解码: %v", err)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0xFFFF {
+		t.Error("像素不应改变")
+	}
+}
+
+func TestCleanPNGMalformed(t *testing.T) {
+	valid := testPNG(t, "")
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"空数据", nil},
+		{"签名错误", []byte("\x89PNX\r\n\x1a\n")},
+		{"缺少 IEND", valid[:len(valid)-12]},
+		{"块长度越界", append(append([]byte{}, valid[:33]...), pngChunk("tEXt", "abc")[:10]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := cleanPNG(tt.data); err != errMalformedImage {
+				t.Errorf("err = %v, want %v", err, errMalformedImage)
+			}
+		})
+	}
+}
+
+func TestCleanImageAppliesOrientation(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	tests := []struct {
+		orientation int
+		width       int
+		height      int
+		topLeft     color.NRGBA
+	}{
+		{1, 2, 1, red},
+		{2, 2, 1, blue},
+		{3, 2, 1, blue},
+		{6, 1, 2, red},
+		{8, 1, 2, blue},
+	}
+	for _, tt := range tests {
+		data := testPNG(t, "", pngChunk("eXIf", string(testExif(tt.orientation))))
+		cleaned, contentType, err := cleanImage(data, "image/png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "image/png" {
+			t.Errorf("方向 %d: contentType = %q", tt.orientation, contentType)
+		}
+		img, err := png.Decode(bytes.NewReader(cleaned))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("方向 %d: 尺寸 %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.width, tt.height)
+		}
+		if got := color.NRGBAModel.Convert(img.At(0, 0)); got != tt.topLeft {
+			t.Errorf("方向 %d: 左上角像素 = %v, want %v", tt.orientation, got, tt.topLeft)
+		}
+	}
+
+	if _, _, err := cleanImage([]byte("text"), "text/plain"); err != ErrUnsupportedMedia {
+		t.Errorf("不支持的类型 err = %v, want %v", err, ErrUnsupportedMedia)
+	}
+}
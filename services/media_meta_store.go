@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errMediaExists 相同内容的媒体文件已存在，并发上传同一文件时由存储层返回
+var errMediaExists = errors.New("媒体文件已存在")
+
+// MediaMetaStore 媒体文件元数据的存储接口
+type MediaMetaStore interface {
+	// CreateMedia 保存新媒体文件的元数据，media.ID 由调用方生成，哈希已存在时返回 errMediaExists
+	CreateMedia(ctx context.Context, media *models.Media) error
+	// GetMediaByID 根据ID获取元数据，不存在时返回 ErrMediaNotFound
+	GetMediaByID(ctx context.Context, id primitive.ObjectID) (*models.Media, error)
+	// GetMediaByHash 根据内容哈希获取元数据，不存在时返回 ErrMediaNotFound
+	GetMediaByHash(ctx context.Context, hash string) (*models.Media, error)
+	// ListMedia 按上传时间倒序分页获取元数据，limit <= 0 表示不分页
+	ListMedia(ctx context.Context, offset, limit int64) ([]*models.Media, error)
+	// CountMedia 统计媒体文件总数
+	CountMedia(ctx context.Context) (int64, error)
+	// DeleteMedia 删除元数据，不存在时返回 ErrMediaNotFound
+	DeleteMedia(ctx context.Context, id primitive.ObjectID) error
+}
+
+// MongoMediaMetaStore 基于 MongoDB 的媒体文件元数据存储
+type MongoMediaMetaStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoMediaMetaStore 创建新的MongoMediaMetaStore实例
+func NewMongoMediaMetaStore(client *mongo.Client, dbName, collectionName string) *MongoMediaMetaStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoMediaMetaStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建内容哈希唯一索引
+func (s *MongoMediaMetaStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// CreateMedia 保存新媒体文件的元数据
+func (s *MongoMediaMetaStore) CreateMedia(ctx context.Context, media *models.Media) error {
+	_, err := s.collection.InsertOne(ctx, media)
+	if mongo.IsDuplicateKeyError(err) {
+		return errMediaExists
+	}
+	return err
+}
+
+// GetMediaByID 根据ID获取元数据
+func (s *MongoMediaMetaStore) GetMediaByID(ctx context.Context, id primitive.ObjectID) (*models.Media, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetMediaByHash 根据内容哈希获取元数据
+func (s *MongoMediaMetaStore) GetMediaByHash(ctx context.Context, hash string) (*models.Media, error) {
+	return s.findOne(ctx, bson.M{"hash": hash})
+}
+
+func (s *MongoMediaMetaStore) findOne(ctx context.Context, filter bson.M) (*models.Media, error) {
+	var media models.Media
+	err := s.collection.FindOne(ctx, filter).Decode(&media)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	return &media, nil
+}
+
+// ListMedia 按上传时间倒序分页获取元数据
+func (s *MongoMediaMetaStore) ListMedia(ctx context.Context, offset, limit int64) ([]*models.Media, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetSkip(offset).SetLimit(limit)
+	}
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []*models.Media{}
+	if err = cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountMedia 统计媒体文件总数
+func (s *MongoMediaMetaStore) CountMedia(ctx context.Context) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{})
+}
+
+// DeleteMedia 删除元数据
+func (s *MongoMediaMetaStore) DeleteMedia(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrMediaNotFound
+	}
+	return nil
+}
+
+// MemoryMediaMetaStore 基于内存的媒体文件元数据存储，用于本地运行与单元测试
+type MemoryMediaMetaStore struct {
+	mu    sync.RWMutex
+	media map[primitive.ObjectID]*models.Media
+}
+
+// NewMemoryMediaMetaStore 创建新的MemoryMediaMetaStore实例
+func NewMemoryMediaMetaStore() *MemoryMediaMetaStore {
+	return &MemoryMediaMetaStore{
+		media: make(map[primitive.ObjectID]*models.Media),
+	}
+}
+
+// CreateMedia 保存新媒体文件的元数据
+func (s *MemoryMediaMetaStore) CreateMedia(_ context.Context, media *models.Media) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.media {
+		if m.Hash == media.Hash {
+			return errMediaExists
+		}
+	}
+	c := *media
+	s.media[media.ID] = &c
+	return nil
+}
+
+// GetMediaByID 根据ID获取元数据
+func (s *MemoryMediaMetaStore) GetMediaByID(_ context.Context, id primitive.ObjectID) (*models.Media, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	media, ok := s.media[id]
+	if !ok {
+		return nil, ErrMediaNotFound
+	}
+	c := *media
+	return &c, nil
+}
+
+// GetMediaByHash 根据内容哈希获取元数据
+func (s *MemoryMediaMetaStore) GetMediaByHash(_ context.Context, hash string) (*models.Media, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, media := range s.media {
+		if media.Hash == hash {
+			c := *media
+			return &c, nil
+		}
+	}
+	return nil, ErrMediaNotFound
+}
+
+// ListMedia 按上传时间倒序分页获取元数据
+func (s *MemoryMediaMetaStore) ListMedia(_ context.Context, offset, limit int64) ([]*models.Media, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]*models.Media, 0, len(s.media))
+	for _, media := range s.media {
+		c := *media
+		list = append(list, &c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID.Hex() > list[j].ID.Hex()
+	})
+	if limit > 0 {
+		list = list[min(offset, int64(len(list))):min(offset+limit, int64(len(list)))]
+	}
+	return list, nil
+}
+
+// CountMedia 统计媒体文件总数
+func (s *MemoryMediaMetaStore) CountMedia(_ context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.media)), nil
+}
+
+// DeleteMedia 删除元数据
+func (s *MemoryMediaMetaStore) DeleteMedia(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.media[id]; !ok {
+		return ErrMediaNotFound
+	}
+	delete(s.media, id)
+	return nil
+}
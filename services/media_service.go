@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif" // 注册图片解码器，用于读取图片尺寸
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	_ "golang.org/x/image/webp"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMediaTooLarge 上传的文件超过大小限制
+var ErrMediaTooLarge = errors.New("文件过大")
+
+// ErrUnsupportedMedia 上传的文件不是支持的图片格式
+var ErrUnsupportedMedia = errors.New("不支持的文件类型，仅支持 JPEG、PNG、GIF 与 WebP 图片")
+
+// ErrMediaInUse 媒体文件仍被文章引用
+var ErrMediaInUse = errors.New("媒体文件仍被文章引用，不能删除")
+
+// mediaTypes 允许上传的 MIME 类型（由文件内容识别，不信任客户端声明的类型）及保存时使用的扩展名
+var mediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// MediaURLPrefix 媒体文件的访问路径前缀
+const MediaURLPrefix = "/media/"
+
+// mediaRefPattern 匹配正文中指向媒体文件的链接（相对或绝对地址均可）
+var mediaRefPattern = regexp.MustCompile(`/media/([0-9a-f]{64})\b`)
+
+// MediaService 处理媒体文件的上传、查询与删除，以及缩略图的生成
+type MediaService struct {
+	meta        MediaMetaStore
+	files       MediaStore
+	blogService *BlogService
+	maxBytes    int64
+
+	widths     []int              // 可用的缩略图宽度，升序
+	cache      *LocalMediaStore   // 缩略图的磁盘缓存，可随时清空
+	generating singleflight.Group // 按缓存路径合并并发的缩略图生成
+}
+
+// NewMediaService 创建新的MediaService实例，maxBytes 为单个文件的大小上限，
+// widths 为可用的缩略图宽度（升序），生成的缩略图缓存在 cacheDir 目录下
+func NewMediaService(meta MediaMetaStore, files MediaStore, blogService *BlogService, maxBytes int64, widths []int, cacheDir string) *MediaService {
+	return &MediaService{
+		meta:        meta,
+		files:       files,
+		blogService: blogService,
+		maxBytes:    maxBytes,
+		widths:      widths,
+		cache:       NewLocalMediaStore(cacheDir),
+	}
+}
+
+// MaxBytes 单个文件的大小上限
+func (s *MediaService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// MediaUpload 上传媒体文件的参数
+type MediaUpload struct {
+	Data     []byte
+	Filename string // 客户端提供的原始文件名，仅作记录
+	Uploader string
+}
+
+// Upload 校验并保存上传的图片，内容相同的文件已存在时直接返回已有记录，created 为 false
+// 保存前移除图片中的元数据并按方向标记旋转，保存的内容与哈希均基于处理后的图片
+func (s *MediaService) Upload(input MediaUpload) (*models.MediaItem, bool, error) {
+	if int64(len(input.Data)) > s.maxBytes {
+		return nil, false, ErrMediaTooLarge
+	}
+	contentType := http.DetectContentType(input.Data)
+	if _, ok := mediaTypes[contentType]; !ok {
+		return nil, false, ErrUnsupportedMedia
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(input.Data))
+	if err != nil {
+		return nil, false, ErrUnsupportedMedia
+	}
+	if config.Width*config.Height > maxMediaPixels {
+		return nil, false, ErrMediaTooLarge
+	}
+
+	data, contentType, err := cleanImage(input.Data, contentType)
+	if err != nil {
+		return nil, false, ErrUnsupportedMedia
+	}
+	if config, _, err = image.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return nil, false, ErrUnsupportedMedia
+	}
+	ext := mediaTypes[contentType]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if _, err := s.meta.GetMediaByHash(ctx, hash); err == nil {
+		return s.existingMedia(ctx, hash)
+	} else if !errors.Is(err, ErrMediaNotFound) {
+		return nil, false, err
+	}
+
+	media := &models.Media{
+		ID:          primitive.NewObjectID(),
+		Hash:        hash,
+		Key:         mediaKey(hash, ext),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       config.Width,
+		Height:      config.Height,
+		Filename:    input.Filename,
+		Uploader:    input.Uploader,
+		CreatedAt:   time.Now(),
+	}
+	// 先保存文件再保存元数据，元数据存在时文件一定可读
+	if err := s.files.Put(ctx, media.Key, bytes.NewReader(data), media.Size, contentType); err != nil {
+		return nil, false, err
+	}
+	if err := s.meta.CreateMedia(ctx, media); errors.Is(err, errMediaExists) {
+		// 同一文件被并发上传，返回先完成的记录
+		return s.existingMedia(ctx, hash)
+	} else if err != nil {
+		return nil, false, err
+	}
+	return s.mediaItem(media, []*models.MediaReference{}), true, nil
+}
+
+// existingMedia 返回内容哈希已存在的媒体文件及引用它的文章
+func (s *MediaService) existingMedia(ctx context.Context, hash string) (*models.MediaItem, bool, error) {
+	media, err := s.meta.GetMediaByHash(ctx, hash)
+	if err != nil {
+		return nil, false, err
+	}
+	items, err := s.mediaItems(ctx, []*models.Media{media})
+	if err != nil {
+		return nil, false, err
+	}
+	return items[0], false, nil
+}
+
+// ListMedia 按上传时间倒序分页获取媒体文件及引用它们的文章
+func (s *MediaService) ListMedia(page, limit int64) ([]*models.MediaItem, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := s.meta.ListMedia(ctx, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.meta.CountMedia(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := s.mediaItems(ctx, list)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// DeleteMedia 删除媒体文件，仍被文章（包括回收站中的文章）引用时返回 ErrMediaInUse
+func (s *MediaService) DeleteMedia(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrMediaNotFound
+	}
+	media, err := s.meta.GetMediaByID(ctx, objID)
+	if err != nil {
+		return err
+	}
+	refs, err := s.blogService.store.ListMediaReferences(ctx, []string{media.Hash})
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		return ErrMediaInUse
+	}
+
+	if err := s.meta.DeleteMedia(ctx, objID); err != nil {
+		return err
+	}
+	// 元数据已删除，文件删除失败只会留下无法访问的文件，不影响结果
+	if err := s.files.Delete(ctx, media.Key); err != nil {
+		log.Printf("删除媒体文件 %s 失败: %v", media.Key, err)
+	}
+	s.removeVariants(ctx, media)
+	return nil
+}
+
+// mediaItems 为媒体文件附加访问路径与引用它们的文章
+func (s *MediaService) mediaItems(ctx context.Context, list []*models.Media) ([]*models.MediaItem, error) {
+	hashes := make([]string, len(list))
+	for i, media := range list {
+		hashes[i] = media.Hash
+	}
+	refs, err := s.blogService.store.ListMediaReferences(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.MediaItem, len(list))
+	for i, media := range list {
+		posts := []*models.MediaReference{}
+		for _, ref := range refs {
+			if slices.Contains(ref.MediaRefs, media.Hash) {
+				posts = append(posts, ref)
+			}
+		}
+		items[i] = s.mediaItem(media, posts)
+	}
+	return items, nil
+}
+
+// mediaItem 组装媒体文件的访问路径、srcset 与引用它的文章
+func (s *MediaService) mediaItem(media *models.Media, posts []*models.MediaReference) *models.MediaItem {
+	url := MediaURL(media.Hash)
+	return &models.MediaItem{Media: *media, URL: url, Srcset: mediaSrcset(url, media, s.widths), Posts: posts}
+}
+
+// MediaURL 返回媒体文件的访问路径
+func MediaURL(hash string) string {
+	return MediaURLPrefix + hash
+}
+
+// mediaKey 按内容哈希生成文件路径，前两级目录取哈希的前 4 位，避免单个目录下文件过多
+func mediaKey(hash, ext string) string {
+	return hash[:2] + "/" + hash[2:4] + "/" + hash + ext
+}
+
+// contentMediaRefs 提取正文引用的媒体文件哈希，去重并排序，没有引用时返回空切片
+func contentMediaRefs(content string) []string {
+	refs := []string{}
+	for _, m := range mediaRefPattern.FindAllStringSubmatch(content, -1) {
+		refs = append(refs, m[1])
+	}
+	slices.Sort(refs)
+	return slices.Compact(refs)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeleteMediaInUse(t *testing.T) {
+	ctx := context.Background()
+	blogService := newTestBlogService()
+	files := NewLocalMediaStore(t.TempDir())
+	mediaService := NewMediaService(NewMemoryMediaMetaStore(), files, blogService, 1<<20, []int{1}, t.TempDir())
+
+	item, created, err := mediaService.Upload(MediaUpload{Data: testPNG(t, ""), Filename: "a.png", Uploader: "alice"})
+	if err != nil || !created {
+		t.Fatalf("上传 = %v, %v", created, err)
+	}
+	// 生成缩略图，删除时应一并清理
+	variant, err := mediaService.OpenMedia(item.Hash, MediaVariant{Width: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	variant.Close()
+
+	blog, err := blogService.CreateBlog(CreateBlogInput{
+		Title:   "引用图片",
+		Content: "![图](https://example.com" + item.URL + ")",
+		Author:  "alice",
+		Show:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 上传相同内容时返回已有记录及引用它的文章
+	again, created, err := mediaService.Upload(MediaUpload{Data: testPNG(t, ""), Filename: "b.png", Uploader: "bob"})
+	if err != nil || created || again.ID != item.ID {
+		t.Fatalf("重复上传 = %v, %v, %v", again, created, err)
+	}
+	if len(again.Posts) != 1 || again.Posts[0].ID != blog.ID {
+		t.Errorf("引用的文章 = %v", again.Posts)
+	}
+
+	if err := mediaService.DeleteMedia(item.ID.Hex()); !errors.Is(err, ErrMediaInUse) {
+		t.Fatalf("删除被引用的文件 err = %v, want ErrMediaInUse", err)
+	}
+	// 回收站中的文章仍然算作引用
+	if err := blogService.DeleteBlog(blog.ID.Hex(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := mediaService.DeleteMedia(item.ID.Hex()); !errors.Is(err, ErrMediaInUse) {
+		t.Fatalf("删除回收站文章引用的文件 err = %v, want ErrMediaInUse", err)
+	}
+	if f, err := files.Open(ctx, item.Key); err != nil {
+		t.Fatalf("被拒绝删除后文件应保留: %v", err)
+	} else {
+		f.Close()
+	}
+
+	// 文章不再引用后可以删除
+	if _, err := blogService.RestoreBlog(blog.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	content := "不再引用图片"
+	if _, err := blogService.UpdateBlog(blog.ID.Hex(), UpdateBlogInput{Content: &content}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mediaService.DeleteMedia(item.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := files.Open(ctx, item.Key); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("删除后打开文件 err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := mediaService.OpenMedia(item.Hash, MediaVariant{}); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("删除后打开原图 err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := mediaService.cache.Open(ctx, variantKey(item.Hash, 1, "png")); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("删除后缩略图缓存 err = %v, want ErrMediaNotFound", err)
+	}
+	if err := mediaService.DeleteMedia(item.ID.Hex()); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("重复删除 err = %v, want ErrMediaNotFound", err)
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrMediaNotFound 媒体文件不存在
+var ErrMediaNotFound = errors.New("媒体文件不存在")
+
+// MediaStore 媒体文件内容的存储接口，key 为按内容哈希生成的相对路径（如 ab/cd/abcd….png）
+type MediaStore interface {
+	// Put 保存文件，key 已存在时覆盖（相同 key 的内容总是相同的）
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open 打开文件供读取，调用方负责关闭，不存在时返回 ErrMediaNotFound
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete 删除文件，不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalMediaStore 基于本地文件系统的媒体文件存储
+type LocalMediaStore struct {
+	root string
+}
+
+// NewLocalMediaStore 创建新的LocalMediaStore实例，文件保存在 root 目录下
+func NewLocalMediaStore(root string) *LocalMediaStore {
+	return &LocalMediaStore{
+		root: root,
+	}
+}
+
+// Put 先写入同目录下的临时文件再重命名，读取方不会看到写了一半的文件
+func (s *LocalMediaStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open 打开文件供读取
+func (s *LocalMediaStore) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrMediaNotFound
+	}
+	return f, err
+}
+
+// Delete 删除文件
+func (s *LocalMediaStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path 将 key 转换为 root 下的文件路径，key 由 mediaKey 生成，不包含 ..
+func (s *LocalMediaStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config S3 兼容对象存储（AWS S3、MinIO 等）的连接配置
+type S3Config struct {
+	Endpoint  string // 服务地址，不含协议，如 s3.amazonaws.com、localhost:9000
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Region    string // 为空时由服务端决定
+	UseSSL    bool
+}
+
+// S3MediaStore 基于 S3 兼容对象存储的媒体文件存储
+type S3MediaStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3MediaStore 创建新的S3MediaStore实例，不会发起网络请求
+func NewS3MediaStore(config S3Config) (*S3MediaStore, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3MediaStore{
+		client: client,
+		bucket: config.Bucket,
+	}, nil
+}
+
+// EnsureBucket 存储桶不存在时创建，重复调用是安全的
+func (s *S3MediaStore) EnsureBucket(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil || exists {
+		return err
+	}
+	return s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{})
+}
+
+// Put 上传文件
+func (s *S3MediaStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Open 打开文件供读取，Seek 后按需发起范围请求
+func (s *S3MediaStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject 不会立即发起请求，先获取对象信息以确认对象存在
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Delete 删除文件
+func (s *S3MediaStore) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
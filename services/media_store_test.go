@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLocalMediaStore(t *testing.T) {
+	root := t.TempDir()
+	testMediaStore(t, NewLocalMediaStore(root))
+
+	// 写入使用的临时文件不应残留
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && strings.HasPrefix(d.Name(), ".upload-") {
+			t.Errorf("残留临时文件 %s", path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestS3MediaStore 设置 MINIO_TEST_ENDPOINT 时对 MinIO（或其他 S3 兼容服务）运行同一组用例，使用独立的临时存储桶
+// 访问密钥取自 MINIO_TEST_ACCESS_KEY 与 MINIO_TEST_SECRET_KEY，默认为 MinIO 的 minioadmin
+func TestS3MediaStore(t *testing.T) {
+	endpoint := os.Getenv("MINIO_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("未设置 MINIO_TEST_ENDPOINT")
+	}
+	accessKey, secretKey := os.Getenv("MINIO_TEST_ACCESS_KEY"), os.Getenv("MINIO_TEST_SECRET_KEY")
+	if accessKey == "" {
+		accessKey, secretKey = "minioadmin", "minioadmin"
+	}
+	store, err := NewS3MediaStore(S3Config{
+		Endpoint:  endpoint,
+		Bucket:    "blog-test-" + primitive.NewObjectID().Hex(),
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := store.EnsureBucket(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// 重复调用是安全的
+	if err := store.EnsureBucket(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		for obj := range store.client.ListObjects(ctx, store.bucket, minio.ListObjectsOptions{Recursive: true}) {
+			store.client.RemoveObject(ctx, store.bucket, obj.Key, minio.RemoveObjectOptions{})
+		}
+		store.client.RemoveBucket(ctx, store.bucket)
+	})
+
+	testMediaStore(t, store)
+}
+
+// testMediaStore 对 MediaStore 的实现运行同一组用例
+func testMediaStore(t *testing.T, store MediaStore) {
+	ctx := context.Background()
+	const key = "ab/cd/abcd.png"
+
+	put := func(t *testing.T, content string) {
+		t.Helper()
+		if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(t *testing.T) (string, error) {
+		t.Helper()
+		f, err := store.Open(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data), nil
+	}
+
+	t.Run("不存在的文件", func(t *testing.T) {
+		if _, err := store.Open(ctx, "00/00/missing.png"); !errors.Is(err, ErrMediaNotFound) {
+			t.Errorf("err = %v, want ErrMediaNotFound", err)
+		}
+		if err := store.Delete(ctx, "00/00/missing.png"); err != nil {
+			t.Errorf("删除不存在的文件 err = %v", err)
+		}
+	})
+
+	t.Run("保存与读取", func(t *testing.T) {
+		put(t, "第一版内容")
+		if got, err := read(t); err != nil || got != "第一版内容" {
+			t.Errorf("内容 = %q, %v", got, err)
+		}
+		put(t, "覆盖后的内容")
+		if got, err := read(t); err != nil || got != "覆盖后的内容" {
+			t.Errorf("覆盖后内容 = %q, %v", got, err)
+		}
+	})
+
+	t.Run("按偏移读取", func(t *testing.T) {
+		put(t, "0123456789")
+		f, err := store.Open(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.Seek(6, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(f)
+		if err != nil || string(data) != "6789" {
+			t.Errorf("Seek 后内容 = %q, %v", data, err)
+		}
+	})
+
+	t.Run("删除", func(t *testing.T) {
+		put(t, "待删除")
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := read(t); !errors.Is(err, ErrMediaNotFound) {
+			t.Errorf("删除后 err = %v, want ErrMediaNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog/models"
+)
+
+// ErrInvalidMediaVariant 请求的缩略图宽度或格式不在配置中
+var ErrInvalidMediaVariant = errors.New("不支持的图片宽度或格式")
+
+// maxVariantWidth 可配置的缩略图宽度上限
+const maxVariantWidth = 8192
+
+// variantFormats 缩略图可用的编码格式及对应的 MIME 类型与扩展名
+var variantFormats = map[string]struct{ contentType, ext string }{
+	"jpeg": {"image/jpeg", ".jpg"},
+	"png":  {"image/png", ".png"},
+}
+
+// ParseMediaWidths 解析以逗号分隔的缩略图宽度列表，如 320,768,1280，返回去重后的升序列表
+func ParseMediaWidths(spec string) ([]int, error) {
+	var widths []int
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		w, err := strconv.Atoi(item)
+		if err != nil || w <= 0 || w > maxVariantWidth {
+			return nil, fmt.Errorf("无效的缩略图宽度 %q，应为 1-%d 之间的整数", item, maxVariantWidth)
+		}
+		widths = append(widths, w)
+	}
+	slices.Sort(widths)
+	return slices.Compact(widths), nil
+}
+
+// MediaVariant 请求的图片版本，零值表示原图
+type MediaVariant struct {
+	Width  int    // 缩略图宽度，须为配置的宽度之一，0 表示原图宽度
+	Format string // jpeg 或 png，为空时 JPEG 图片使用 jpeg，其他使用 png
+}
+
+// MediaFile 打开的原图或缩略图，调用方负责关闭
+type MediaFile struct {
+	io.ReadSeekCloser
+	Media       *models.Media
+	ContentType string
+	Name        string // 文件名（原图为哈希，缩略图附加宽度与扩展名），可用作 ETag
+}
+
+// OpenMedia 根据内容哈希打开原图或缩略图
+// 缩略图首次请求时生成并写入磁盘缓存；不会放大图片，宽度不小于原图时按原图宽度输出；
+// GIF 始终返回原图以保留动画
+func (s *MediaService) OpenMedia(hash string, variant MediaVariant) (*MediaFile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	media, err := s.meta.GetMediaByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if variant.Width != 0 && !slices.Contains(s.widths, variant.Width) {
+		return nil, ErrInvalidMediaVariant
+	}
+	if variant.Format != "" {
+		if _, ok := variantFormats[variant.Format]; !ok {
+			return nil, ErrInvalidMediaVariant
+		}
+	}
+
+	width, format := variant.Width, variant.Format
+	if width == 0 || width > media.Width {
+		width = media.Width
+	}
+	if format == "" {
+		format = "png"
+		if media.ContentType == "image/jpeg" {
+			format = "jpeg"
+		}
+	}
+	if media.ContentType == "image/gif" || (width == media.Width && variantFormats[format].contentType == media.ContentType) {
+		// 读取文件内容可能持续较久，不使用上面的超时
+		f, err := s.files.Open(context.Background(), media.Key)
+		if err != nil {
+			return nil, err
+		}
+		return &MediaFile{ReadSeekCloser: f, Media: media, ContentType: media.ContentType, Name: media.Hash}, nil
+	}
+
+	key := variantKey(media.Hash, width, format)
+	f, err := s.cache.Open(ctx, key)
+	if errors.Is(err, ErrMediaNotFound) {
+		// 同一缩略图的并发请求只生成一次
+		_, err, _ = s.generating.Do(key, func() (any, error) {
+			return nil, s.generateVariant(media, key, width, format)
+		})
+		if err == nil {
+			f, err = s.cache.Open(ctx, key)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	name := key[strings.LastIndexByte(key, '/')+1:]
+	return &MediaFile{ReadSeekCloser: f, Media: media, ContentType: variantFormats[format].contentType, Name: name}, nil
+}
+
+// generateVariant 读取原图，缩放并编码后写入缓存
+func (s *MediaService) generateVariant(media *models.Media, key string, width int, format string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	f, err := s.files.Open(ctx, media.Key)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := resizeImage(&buf, img, width, format); err != nil {
+		return err
+	}
+	return s.cache.Put(ctx, key, &buf, int64(buf.Len()), variantFormats[format].contentType)
+}
+
+// removeVariants 删除媒体文件的全部缓存缩略图
+func (s *MediaService) removeVariants(ctx context.Context, media *models.Media) {
+	widths := append(slices.Clone(s.widths), media.Width)
+	for _, width := range widths {
+		for format := range variantFormats {
+			s.cache.Delete(ctx, variantKey(media.Hash, width, format))
+		}
+	}
+}
+
+// Srcset 为指向媒体文件原图的地址生成 srcset：小于原图宽度的缩略图加上原图本身，
+// 地址不是媒体文件、已指定版本、为 GIF 或没有更小的缩略图时返回空字符串
+func (s *MediaService) Srcset(src string) string {
+	loc := mediaRefPattern.FindStringSubmatchIndex(src)
+	if loc == nil || loc[1] != len(src) {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	media, err := s.meta.GetMediaByHash(ctx, src[loc[2]:loc[3]])
+	if err != nil {
+		return ""
+	}
+	return mediaSrcset(src, media, s.widths)
+}
+
+// mediaSrcset 按配置的宽度生成 srcset，没有小于原图的宽度时返回空字符串
+func mediaSrcset(src string, media *models.Media, widths []int) string {
+	if media.ContentType == "image/gif" {
+		return ""
+	}
+	var candidates []string
+	for _, w := range widths {
+		if w < media.Width {
+			candidates = append(candidates, fmt.Sprintf("%s?w=%d %dw", src, w, w))
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	candidates = append(candidates, fmt.Sprintf("%s %dw", src, media.Width))
+	return strings.Join(candidates, ", ")
+}
+
+// variantKey 缩略图在缓存中的路径，与原图使用相同的目录结构
+func variantKey(hash string, width int, format string) string {
+	return mediaKey(hash, fmt.Sprintf("-%d%s", width, variantFormats[format].ext))
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"blog/models"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// excerptRunes 自动摘要的最大长度（字符数）
+	excerptRunes = 140
+	// 阅读速度：中日韩文字按字计，其他语言按单词计
+	cjkCharsPerMinute   = 300
+	latinWordsPerMinute = 200
+)
+
+// moreSeparator 正文中手动指定摘要结束位置的标记，只识别 HTML 注释节点，代码块中的同名文本不算
+var moreSeparator = regexp.MustCompile(`<!--\s*more\s*-->`)
+
+// blogMetadata 根据 Markdown 正文计算摘要、目录、字数与阅读时长
+func blogMetadata(content string) models.BlogMetadata {
+	plain, toc, more := markdownText(content)
+
+	var meta models.BlogMetadata
+	meta.TOC = toc
+	if more >= 0 {
+		meta.Excerpt = collapseSpaces(plain[:more])
+	} else {
+		meta.Excerpt = truncateRunes(collapseSpaces(plain), excerptRunes)
+	}
+
+	cjk, latin, chars := countWords(plain)
+	meta.WordCount = cjk + latin
+	meta.CharCount = chars
+	if strings.TrimSpace(content) != "" {
+		minutes := float64(cjk)/cjkCharsPerMinute + float64(latin)/latinWordsPerMinute
+		meta.ReadingMinutes = max(1, int(math.Ceil(minutes)))
+	}
+	return meta
+}
+
+// markdownText 提取 Markdown 的纯文本（不含代码块与原始 HTML）及标题目录
+// 标题锚点与 RenderMarkdown 生成的 id 一致；more 为第一个摘要标记在纯文本中的位置，没有标记时为 -1
+func markdownText(source string) (plain string, toc []models.TOCEntry, more int) {
+	src := []byte(source)
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	doc := markdown.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	var b strings.Builder
+	more = -1
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML:
+			if entering && more < 0 && moreSeparator.Match(rawHTML(n, src)) {
+				more = b.Len()
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading:
+			if entering {
+				id, _ := n.AttributeString("id")
+				idBytes, _ := id.([]byte)
+				toc = append(toc, models.TOCEntry{
+					Level: n.Level,
+					Text:  collapseSpaces(inlineText(n, src)),
+					ID:    string(idBytes),
+				})
+			}
+		case *ast.Text:
+			if entering {
+				b.Write(n.Value(src))
+				if n.SoftLineBreak() || n.HardLineBreak() {
+					b.WriteByte(' ')
+				}
+			}
+		case *ast.String:
+			if entering {
+				b.Write(n.Value)
+			}
+		}
+		// 块级元素之间以换行分隔，避免相邻段落的文字粘连
+		if !entering && n.Type() == ast.TypeBlock {
+			b.WriteByte('\n')
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String(), toc, more
+}
+
+// rawHTML 返回原始 HTML 节点（块级或行内）在源文本中的内容
+func rawHTML(n ast.Node, src []byte) []byte {
+	var segments []text.Segment
+	switch n := n.(type) {
+	case *ast.HTMLBlock:
+		segments = n.Lines().Sliced(0, n.Lines().Len())
+		if n.HasClosure() {
+			segments = append(segments, n.ClosureLine)
+		}
+	case *ast.RawHTML:
+		segments = n.Segments.Sliced(0, n.Segments.Len())
+	}
+	var b []byte
+	for _, seg := range segments {
+		b = append(b, seg.Value(src)...)
+	}
+	return b
+}
+
+// inlineText 拼接节点下所有文本
+func inlineText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			b.Write(c.Value(src))
+			if c.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// countWords 统计中日韩文字数、其他语言的单词数，以及非空白字符数
+func countWords(s string) (cjk, latin, chars int) {
+	inWord := false
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			chars++
+		}
+		switch {
+		case isCJK(r):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				latin++
+			}
+			inWord = true
+		case r == '\'' || r == '’' || r == '-':
+			// 单词内部的撇号与连字符不拆分单词，如 don't、well-known
+		default:
+			inWord = false
+		}
+	}
+	return cjk, latin, chars
+}
+
+// collapseSpaces 将连续空白压缩为一个空格并去除首尾空白
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncateRunes 截断到 n 个字符，被截断时追加省略号
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
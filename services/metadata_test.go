@@ -0,0 +1,75 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"blog/models"
+)
+
+func TestBlogMetadataExcerpt(t *testing.T) {
+	long := strings.Repeat("字", excerptRunes+10)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"空正文", "", ""},
+		{"没有标记时自动截断", long, strings.Repeat("字", excerptRunes) + "…"},
+		{"单独一行的标记", "第一段\n\n<!--more-->\n\n第二段", "第一段"},
+		{"标记内带空格", "第一段\n\n<!-- more -->\n\n第二段", "第一段"},
+		{"行内标记", "摘要部分<!--more-->其余部分", "摘要部分"},
+		{"标记前的多段落", "# 标题\n\n第一段\n\n第二段\n\n<!--more-->\n\n第三段", "标题 第一段 第二段"},
+		{"只使用第一个标记", "一\n\n<!--more-->\n\n二\n\n<!--more-->\n\n三", "一"},
+		{"代码块中的标记被忽略", "开头\n\n```html\n<!--more-->\n```\n\n结尾", "开头 结尾"},
+		{"缩进代码块中的标记被忽略", "开头\n\n    <!--more-->\n\n结尾", "开头 结尾"},
+		{"行内代码中的标记被忽略", "开头 `<!--more-->` 结尾", "开头 <!--more--> 结尾"},
+		{"其他注释不是标记", "开头\n\n<!-- note -->\n\n结尾", "开头 结尾"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blogMetadata(tt.content).Excerpt; got != tt.want {
+				t.Errorf("blogMetadata(%q).Excerpt = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlogMetadataTOC(t *testing.T) {
+	content := "# 简介\n\n正文\n\n## Go 语言\n\n### 简介\n\n```\n# 不是标题\n```"
+	want := []models.TOCEntry{
+		{Level: 1, Text: "简介", ID: "jian-jie"},
+		{Level: 2, Text: "Go 语言", ID: "go-yu-yan"},
+		{Level: 3, Text: "简介", ID: "jian-jie-2"},
+	}
+	if got := blogMetadata(content).TOC; !slices.Equal(got, want) {
+		t.Errorf("TOC = %+v, want %+v", got, want)
+	}
+}
+
+func TestBlogMetadataCounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		words   int
+		chars   int
+		minutes int
+	}{
+		{"空正文", "", 0, 0, 0},
+		{"中文按字计", "你好，世界", 4, 5, 1},
+		{"英文按单词计", "Don't use well-known words", 4, 23, 1},
+		{"中英混排", "使用 Go 语言", 5, 6, 1},
+		{"代码块不计入", "正文\n\n```\ncode code code\n```", 2, 2, 1},
+		{"按阅读速度向上取整", strings.Repeat("字", cjkCharsPerMinute+1), cjkCharsPerMinute + 1, cjkCharsPerMinute + 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := blogMetadata(tt.content)
+			if meta.WordCount != tt.words || meta.CharCount != tt.chars || meta.ReadingMinutes != tt.minutes {
+				t.Errorf("blogMetadata(%q) = %d 词 %d 字符 %d 分钟, want %d %d %d",
+					tt.content, meta.WordCount, meta.CharCount, meta.ReadingMinutes, tt.words, tt.chars, tt.minutes)
+			}
+		})
+	}
+}
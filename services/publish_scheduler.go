@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/models"
+)
+
+// ErrInvalidSchedule 定时下线时间不晚于发布时间
+var ErrInvalidSchedule = errors.New("下线时间必须晚于发布时间")
+
+// 发布事件类型
+const (
+	PublishEventPublish   = "publish"   // 文章到达定时发布时间，对读者可见
+	PublishEventUnpublish = "unpublish" // 文章到达定时下线时间，对读者不可见
+)
+
+// PublishEvent 文章定时上线或下线事件
+type PublishEvent struct {
+	Type string       // PublishEventPublish 或 PublishEventUnpublish
+	Blog *models.Blog // 事件发生时的文章
+	At   time.Time    // 计划的上线/下线时间
+}
+
+// PublishScheduler 在文章的定时发布与下线时间到达时发出事件，供订阅源、Webhook、缓存等使用
+// 文章是否可见由查询时的时间决定，与事件是否送达无关；服务停机期间到达的时间点不会补发事件
+type PublishScheduler struct {
+	blogService *BlogService
+	interval    time.Duration // 最长检查间隔，同时也是预读即将到达时间点的窗口
+
+	mu          sync.RWMutex
+	subscribers []func(PublishEvent)
+	last        time.Time // 已处理到的时间点
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPublishScheduler 创建新的PublishScheduler实例，interval 为最长检查间隔
+func NewPublishScheduler(blogService *BlogService, interval time.Duration) *PublishScheduler {
+	return &PublishScheduler{
+		blogService: blogService,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Subscribe 注册事件处理函数，处理函数在调度协程中依次同步调用，不应长时间阻塞
+func (p *PublishScheduler) Subscribe(fn func(PublishEvent)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.subscribers = append(p.subscribers, fn)
+}
+
+// Start 启动后台调度，只处理启动之后到达的时间点
+func (p *PublishScheduler) Start() {
+	p.last = time.Now()
+	go func() {
+		defer close(p.done)
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+			case <-p.blogService.scheduleChanged:
+			case <-p.stop:
+				return
+			}
+			timer.Reset(p.check())
+		}
+	}()
+}
+
+// Stop 停止后台调度
+func (p *PublishScheduler) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+// check 发出自上次检查以来到达的事件，返回距下一个时间点（最长 interval）的等待时长
+func (p *PublishScheduler) check() time.Duration {
+	now := time.Now()
+	next := now.Add(p.interval)
+
+	blogs, err := p.blogService.listScheduled(p.last, next)
+	if err != nil {
+		log.Println("获取定时发布文章失败:", err)
+		return p.interval
+	}
+
+	var events []PublishEvent
+	due := func(t *time.Time, eventType string, blog *models.Blog) {
+		if t == nil || !t.After(p.last) {
+			return
+		}
+		if t.After(now) {
+			if t.Before(next) {
+				next = *t
+			}
+			return
+		}
+		// 未展示的文章在时间点到达时对读者没有变化
+		if blog.Show {
+			events = append(events, PublishEvent{Type: eventType, Blog: blog, At: *t})
+		}
+	}
+	for _, blog := range blogs {
+		due(blog.PublishAt, PublishEventPublish, blog)
+		due(blog.UnpublishAt, PublishEventUnpublish, blog)
+	}
+	p.last = now
+
+	if len(events) > 0 {
+		p.blogService.counts.invalidate()
+		// 下线的文章不再作为相关文章的候选
+		for _, e := range events {
+			p.blogService.related.put(e.Blog)
+		}
+		sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+
+		p.mu.RLock()
+		subscribers := p.subscribers
+		p.mu.RUnlock()
+		for _, e := range events {
+			for _, fn := range subscribers {
+				fn(e)
+			}
+		}
+	}
+	return max(time.Until(next), 0)
+}
+
+// listScheduled 获取定时发布或下线时间落在 (from, to] 内的文章
+func (s *BlogService) listScheduled(from, to time.Time) ([]*models.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return s.store.ListScheduledBlogs(ctx, from, to)
+}
+
+// notifyScheduleChanged 通知调度器重新计算下一个时间点，调度器忙时合并通知
+func (s *BlogService) notifyScheduleChanged() {
+	select {
+	case s.scheduleChanged <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPublishWindow(t *testing.T) {
+	blogService := newTestBlogService()
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		publishAt   *time.Time
+		unpublishAt *time.Time
+		visible     bool
+	}{
+		{"未设置定时", nil, nil, true},
+		{"已到发布时间", at(-time.Hour), nil, true},
+		{"未到发布时间", at(time.Hour), nil, false},
+		{"在发布窗口内", at(-time.Hour), at(time.Hour), true},
+		{"已过下线时间", at(-2 * time.Hour), at(-time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blog, err := blogService.CreateBlog(CreateBlogInput{
+				Title: tt.name, Content: "正文", Author: "alice", Show: true,
+				PublishAt: tt.publishAt, UnpublishAt: tt.unpublishAt,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = blogService.GetVisibleBlogByID(blog.ID.Hex())
+			if tt.visible != (err == nil) {
+				t.Errorf("GetVisibleBlogByID err = %v, want visible %v", err, tt.visible)
+			}
+			page, err := blogService.ListVisibleBlogs(BlogQuery{IDs: []primitive.ObjectID{blog.ID}}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if listed := len(page.Blogs) == 1 && *page.Total == 1; listed != tt.visible {
+				t.Errorf("列表中 = %v, want %v", listed, tt.visible)
+			}
+			// 后台总能看到
+			if _, err := blogService.GetBlogByID(blog.ID.Hex()); err != nil {
+				t.Errorf("GetBlogByID err = %v", err)
+			}
+		})
+	}
+
+	t.Run("下线时间须晚于发布时间", func(t *testing.T) {
+		_, err := blogService.CreateBlog(CreateBlogInput{
+			Title: "无效", Content: "正文", Author: "alice", Show: true,
+			PublishAt: at(time.Hour), UnpublishAt: at(time.Hour),
+		})
+		if !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("err = %v, want ErrInvalidSchedule", err)
+		}
+	})
+
+	t.Run("清除定时后立即可见", func(t *testing.T) {
+		blog, err := blogService.CreateBlog(CreateBlogInput{Title: "定时", Content: "正文", Author: "alice", Show: true, PublishAt: at(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 只修改下线时间时同样校验
+		if _, err := blogService.UpdateBlog(blog.ID.Hex(), UpdateBlogInput{UnpublishAt: at(time.Minute)}); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("err = %v, want ErrInvalidSchedule", err)
+		}
+		updated, err := blogService.UpdateBlog(blog.ID.Hex(), UpdateBlogInput{PublishAt: &time.Time{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.PublishAt != nil || !updated.PublishedAt.Equal(updated.CreatedAt) {
+			t.Errorf("清除后 publish_at = %v, published_at = %v", updated.PublishAt, updated.PublishedAt)
+		}
+		if _, err := blogService.GetVisibleBlogByID(blog.ID.Hex()); err != nil {
+			t.Errorf("GetVisibleBlogByID err = %v", err)
+		}
+	})
+}
+
+func TestPublishSchedulerCheck(t *testing.T) {
+	blogService := newTestBlogService()
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	create := func(title string, show bool, publishAt, unpublishAt *time.Time) {
+		t.Helper()
+		_, err := blogService.CreateBlog(CreateBlogInput{
+			Title: title, Content: "正文", Author: "alice", Show: show,
+			PublishAt: publishAt, UnpublishAt: unpublishAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	create("窗口已过", true, at(-30*time.Minute), at(-10*time.Minute))
+	create("刚刚发布", true, at(-20*time.Minute), nil)
+	create("上次检查之前", true, at(-2*time.Hour), nil)
+	create("草稿", false, at(-15*time.Minute), nil)
+	create("稍后发布", true, at(time.Hour), nil)
+
+	scheduler := NewPublishScheduler(blogService, 2*time.Hour)
+	var got []string
+	scheduler.Subscribe(func(e PublishEvent) { got = append(got, e.Type+":"+e.Blog.Title) })
+	scheduler.last = now.Add(-time.Hour)
+
+	wait := scheduler.check()
+	// 按时间点先后发出，未展示的文章与上次检查之前的时间点不发出事件
+	want := []string{"publish:窗口已过", "publish:刚刚发布", "unpublish:窗口已过"}
+	if !slices.Equal(got, want) {
+		t.Errorf("事件 = %q, want %q", got, want)
+	}
+	// 下一个时间点早于检查间隔时，等到该时间点
+	if wait > time.Hour || wait < 59*time.Minute {
+		t.Errorf("等待时长 = %v, want 约 1h", wait)
+	}
+
+	got = nil
+	scheduler.check()
+	if len(got) != 0 {
+		t.Errorf("再次检查不应重复发出事件: %q", got)
+	}
+}
+
+func TestPublishSchedulerWakesOnScheduleChange(t *testing.T) {
+	blogService := newTestBlogService()
+	scheduler := NewPublishScheduler(blogService, time.Hour)
+	events := make(chan PublishEvent, 1)
+	scheduler.Subscribe(func(e PublishEvent) { events <- e })
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	// 调度器在一小时的检查间隔内等待，新的定时时间应使其提前醒来
+	publishAt := time.Now().Add(50 * time.Millisecond)
+	blog, err := blogService.CreateBlog(CreateBlogInput{Title: "定时", Content: "正文", Author: "alice", Show: true, PublishAt: &publishAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != PublishEventPublish || e.Blog.ID != blog.ID || !e.At.Equal(publishAt) {
+			t.Errorf("事件 = %s %s %v", e.Type, e.Blog.Title, e.At)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到发布事件")
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrUnknownReaction 不在配置中的表情回应
+var ErrUnknownReaction = errors.New("不支持的表情回应")
+
+// reactionName 回应名称只允许小写字母、数字、- 与 _，可直接用作路径与计数字段名
+var reactionName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// ParseReactionKinds 解析以逗号分隔的 name:emoji 列表，如 like:👍,heart:❤️,tada:🎉
+func ParseReactionKinds(spec string) ([]models.ReactionKind, error) {
+	var kinds []models.ReactionKind
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, emoji, _ := strings.Cut(item, ":")
+		name, emoji = strings.TrimSpace(name), strings.TrimSpace(emoji)
+		if !reactionName.MatchString(name) || emoji == "" || seen[name] {
+			return nil, fmt.Errorf("无效的表情回应配置 %q，格式为 name:emoji，name 只能包含小写字母、数字、- 与 _ 且不能重复", item)
+		}
+		seen[name] = true
+		kinds = append(kinds, models.ReactionKind{Name: name, Emoji: emoji})
+	}
+	return kinds, nil
+}
+
+// ReactionSummary 文章的表情回应数量及当前读者做出的回应
+type ReactionSummary struct {
+	Counts map[string]int64 // 每种已配置回应的数量，包括 0
+	Mine   []string         // 当前读者做出的回应种类
+}
+
+// ReactionService 处理文章的表情回应
+type ReactionService struct {
+	store       ReactionStore
+	blogService *BlogService
+	kinds       []models.ReactionKind
+}
+
+// NewReactionService 创建新的ReactionService实例，kinds 为可用的回应种类
+func NewReactionService(store ReactionStore, blogService *BlogService, kinds []models.ReactionKind) *ReactionService {
+	return &ReactionService{
+		store:       store,
+		blogService: blogService,
+		kinds:       kinds,
+	}
+}
+
+// Kinds 返回可用的回应种类
+func (s *ReactionService) Kinds() []models.ReactionKind {
+	return s.kinds
+}
+
+// VisibleReactions 获取前端可见文章的回应数量，actor 为当前读者的标识
+func (s *ReactionService) VisibleReactions(postID, actor string) (*ReactionSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blog, err := s.blogService.GetVisibleBlogByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(ctx, blog, actor)
+}
+
+// React 对前端可见的文章做出回应，重复回应不会重复计数
+func (s *ReactionService) React(postID, kind, actor string) (*ReactionSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blog, err := s.reactionTarget(postID, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// 是否计数以唯一索引上的插入结果为准，并发的重复点击只有一次成功
+	added, err := s.store.AddReaction(ctx, &models.Reaction{
+		ID:        primitive.NewObjectID(),
+		PostID:    blog.ID,
+		Kind:      kind,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if added {
+		if err := s.blogService.store.IncrementReactions(ctx, blog.ID, kind, 1); err != nil {
+			return nil, err
+		}
+	}
+	return s.summary(ctx, blog, actor)
+}
+
+// Unreact 撤销对前端可见文章的回应，没有做出过该回应时不做修改
+func (s *ReactionService) Unreact(postID, kind, actor string) (*ReactionSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blog, err := s.reactionTarget(postID, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := s.store.RemoveReaction(ctx, blog.ID, kind, actor)
+	if err != nil {
+		return nil, err
+	}
+	if removed {
+		if err := s.blogService.store.IncrementReactions(ctx, blog.ID, kind, -1); err != nil {
+			return nil, err
+		}
+	}
+	return s.summary(ctx, blog, actor)
+}
+
+// reactionTarget 校验回应种类并获取前端可见的文章
+func (s *ReactionService) reactionTarget(postID, kind string) (*models.Blog, error) {
+	known := false
+	for _, k := range s.kinds {
+		if k.Name == kind {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, ErrUnknownReaction
+	}
+	return s.blogService.GetVisibleBlogByID(postID)
+}
+
+// RepairCounts 按回应记录重新统计全部文章（包括回收站中的文章）的回应数量，写回与记录不一致的文章，返回修复的文章数
+// 文章上的数量只是供列表展示的副本，由每次插入或删除回应记录后的原子自增维护，
+// 只有在插入记录后自增失败等情况下才会与记录不一致
+func (s *ReactionService) RepairCounts() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	blogs, err := s.blogService.store.ListBlogs(ctx, BlogQuery{})
+	if err != nil {
+		return 0, err
+	}
+	trashed, err := s.blogService.store.ListBlogs(ctx, BlogQuery{Trashed: true})
+	if err != nil {
+		return 0, err
+	}
+	repaired := 0
+	for _, blog := range append(blogs, trashed...) {
+		counts, err := s.store.CountReactions(ctx, blog.ID)
+		if err != nil {
+			return repaired, err
+		}
+		stored := maps.Clone(blog.Reactions)
+		maps.DeleteFunc(stored, func(_ string, n int64) bool { return n == 0 })
+		if maps.Equal(stored, counts) {
+			continue
+		}
+		if err := s.blogService.store.SetReactions(ctx, blog.ID, counts); err != nil {
+			return repaired, err
+		}
+		repaired++
+	}
+	return repaired, nil
+}
+
+// summary 以回应记录统计数量，不读取文章上的副本
+func (s *ReactionService) summary(ctx context.Context, blog *models.Blog, actor string) (*ReactionSummary, error) {
+	counts, err := s.store.CountReactions(ctx, blog.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildSummary(ctx, blog.ID, counts, actor)
+}
+
+func (s *ReactionService) buildSummary(ctx context.Context, postID primitive.ObjectID, counts map[string]int64, actor string) (*ReactionSummary, error) {
+	mine, err := s.store.ListActorKinds(ctx, postID, actor)
+	if err != nil {
+		return nil, err
+	}
+	summary := &ReactionSummary{Counts: make(map[string]int64, len(s.kinds)), Mine: []string{}}
+	for _, k := range s.kinds {
+		summary.Counts[k.Name] = counts[k.Name]
+	}
+	for _, kind := range mine {
+		if _, ok := summary.Counts[kind]; ok {
+			summary.Mine = append(summary.Mine, kind)
+		}
+	}
+	return summary, nil
+}
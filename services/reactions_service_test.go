@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+
+	"blog/models"
+)
+
+// newTestReactionService 基于内存存储创建 ReactionService 与一篇可见文章
+func newTestReactionService(t *testing.T) (*ReactionService, *models.Blog) {
+	t.Helper()
+	blogService := newTestBlogService()
+	blog, err := blogService.CreateBlog(CreateBlogInput{Title: "回应", Content: "正文", Author: "alice", Show: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := []models.ReactionKind{{Name: "like", Emoji: "👍"}, {Name: "heart", Emoji: "❤️"}}
+	return NewReactionService(blogService.reactions, blogService, kinds), blog
+}
+
+func TestReactionCounts(t *testing.T) {
+	reactionService, blog := newTestReactionService(t)
+	id := blog.ID.Hex()
+
+	steps := []struct {
+		name   string
+		do     func() (*ReactionSummary, error)
+		counts map[string]int64
+		mine   []string
+	}{
+		{"首次回应", func() (*ReactionSummary, error) { return reactionService.React(id, "like", "a") },
+			map[string]int64{"like": 1, "heart": 0}, []string{"like"}},
+		{"重复回应不计数", func() (*ReactionSummary, error) { return reactionService.React(id, "like", "a") },
+			map[string]int64{"like": 1, "heart": 0}, []string{"like"}},
+		{"其他读者回应", func() (*ReactionSummary, error) { return reactionService.React(id, "like", "b") },
+			map[string]int64{"like": 2, "heart": 0}, []string{"like"}},
+		{"另一种回应", func() (*ReactionSummary, error) { return reactionService.React(id, "heart", "b") },
+			map[string]int64{"like": 2, "heart": 1}, []string{"heart", "like"}},
+		{"撤销回应", func() (*ReactionSummary, error) { return reactionService.Unreact(id, "like", "a") },
+			map[string]int64{"like": 1, "heart": 1}, []string{}},
+		{"撤销不存在的回应", func() (*ReactionSummary, error) { return reactionService.Unreact(id, "like", "a") },
+			map[string]int64{"like": 1, "heart": 1}, []string{}},
+	}
+	for _, step := range steps {
+		summary, err := step.do()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if !maps.Equal(summary.Counts, step.counts) || fmt.Sprint(summary.Mine) != fmt.Sprint(step.mine) {
+			t.Errorf("%s: counts %v mine %v, want %v %v", step.name, summary.Counts, summary.Mine, step.counts, step.mine)
+		}
+	}
+
+	// 文章上的副本与回应记录一致，撤销后保留为 0 的种类
+	got, err := reactionService.blogService.store.GetBlogByID(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"like": 1, "heart": 1}; !maps.Equal(got.Reactions, want) {
+		t.Errorf("文章上的数量 = %v, want %v", got.Reactions, want)
+	}
+
+	if _, err := reactionService.React(id, "unknown", "a"); err != ErrUnknownReaction {
+		t.Errorf("未配置的回应 err = %v, want %v", err, ErrUnknownReaction)
+	}
+}
+
+func TestReactionCountsRepairDrift(t *testing.T) {
+	reactionService, blog := newTestReactionService(t)
+	id := blog.ID.Hex()
+	store := reactionService.blogService.store
+	ctx := context.Background()
+
+	if _, err := reactionService.React(id, "like", "a"); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟此前写入失败导致文章上的副本与回应记录不一致
+	if err := store.SetReactions(ctx, blog.ID, map[string]int64{"like": 7, "heart": -1}); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := reactionService.VisibleReactions(id, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"like": 1, "heart": 0}; !maps.Equal(summary.Counts, want) {
+		t.Errorf("读取的数量 = %v, want %v", summary.Counts, want)
+	}
+
+	// 回应只在副本上原子自增，不会覆盖其他并发写入，不一致须显式修复
+	if _, err := reactionService.React(id, "like", "b"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetBlogByID(ctx, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"like": 8, "heart": -1}; !maps.Equal(got.Reactions, want) {
+		t.Errorf("修复前文章上的数量 = %v, want %v", got.Reactions, want)
+	}
+
+	for _, want := range []int{1, 0} {
+		repaired, err := reactionService.RepairCounts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repaired != want {
+			t.Errorf("修复的文章数 = %d, want %d", repaired, want)
+		}
+	}
+	got, err = store.GetBlogByID(ctx, blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"like": 2}; !maps.Equal(got.Reactions, want) {
+		t.Errorf("修复后文章上的数量 = %v, want %v", got.Reactions, want)
+	}
+}
+
+func TestReactionCountsConcurrent(t *testing.T) {
+	reactionService, blog := newTestReactionService(t)
+	id := blog.ID.Hex()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(actor string) {
+			defer wg.Done()
+			// 同一读者并发重复点击只计一次，一半读者随后撤销
+			for j := 0; j < 3; j++ {
+				if _, err := reactionService.React(id, "like", actor); err != nil {
+					t.Error(err)
+				}
+			}
+			if i%2 == 0 {
+				if _, err := reactionService.Unreact(id, "like", actor); err != nil {
+					t.Error(err)
+				}
+			}
+		}(fmt.Sprintf("actor-%d", i))
+	}
+	wg.Wait()
+
+	summary, err := reactionService.VisibleReactions(id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Counts["like"] != 10 {
+		t.Errorf("like = %d, want 10", summary.Counts["like"])
+	}
+	// 文章上的副本由原子自增维护，并发时同样不漂移
+	got, err := reactionService.blogService.store.GetBlogByID(context.Background(), blog.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Reactions["like"] != 10 {
+		t.Errorf("文章上的 like = %d, want 10", got.Reactions["like"])
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReactionStore 表情回应的存储接口，(post_id, kind, actor) 唯一，用于去重
+type ReactionStore interface {
+	// AddReaction 保存回应，同一读者已有同种回应时不做修改并返回 false
+	AddReaction(ctx context.Context, reaction *models.Reaction) (bool, error)
+	// RemoveReaction 删除回应，不存在时返回 false
+	RemoveReaction(ctx context.Context, postID primitive.ObjectID, kind, actor string) (bool, error)
+	// CountReactions 统计文章每种回应的数量，没有回应的种类不返回
+	CountReactions(ctx context.Context, postID primitive.ObjectID) (map[string]int64, error)
+	// ListActorKinds 获取读者对文章做出的全部回应种类，按名称排序
+	ListActorKinds(ctx context.Context, postID primitive.ObjectID, actor string) ([]string, error)
+	// DeletePostReactions 永久删除文章的全部回应，文章被永久删除时调用
+	DeletePostReactions(ctx context.Context, postID primitive.ObjectID) error
+	// ListReactions 按时间正序获取全部回应
+	ListReactions(ctx context.Context) ([]*models.Reaction, error)
+}
+
+// MongoReactionStore 基于 MongoDB 的表情回应存储
+type MongoReactionStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoReactionStore 创建新的MongoReactionStore实例
+func NewMongoReactionStore(client *mongo.Client, dbName, collectionName string) *MongoReactionStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoReactionStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建 (post_id, actor, kind) 唯一索引，并发的重复点击由索引去重
+func (s *MongoReactionStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "post_id", Value: 1}, {Key: "actor", Value: 1}, {Key: "kind", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// AddReaction 保存回应
+func (s *MongoReactionStore) AddReaction(ctx context.Context, reaction *models.Reaction) (bool, error) {
+	_, err := s.collection.InsertOne(ctx, reaction)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveReaction 删除回应
+func (s *MongoReactionStore) RemoveReaction(ctx context.Context, postID primitive.ObjectID, kind, actor string) (bool, error) {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"post_id": postID, "actor": actor, "kind": kind})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// CountReactions 统计文章每种回应的数量
+func (s *MongoReactionStore) CountReactions(ctx context.Context, postID primitive.ObjectID) (map[string]int64, error) {
+	cursor, err := s.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"post_id": postID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$kind", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Kind  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(groups))
+	for _, g := range groups {
+		counts[g.Kind] = g.Count
+	}
+	return counts, nil
+}
+
+// ListActorKinds 获取读者对文章做出的全部回应种类
+func (s *MongoReactionStore) ListActorKinds(ctx context.Context, postID primitive.ObjectID, actor string) ([]string, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{"post_id": postID, "actor": actor},
+		options.Find().SetProjection(bson.M{"kind": 1}).SetSort(bson.D{{Key: "kind", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reactions []models.Reaction
+	if err = cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	kinds := make([]string, len(reactions))
+	for i, reaction := range reactions {
+		kinds[i] = reaction.Kind
+	}
+	return kinds, nil
+}
+
+// DeletePostReactions 永久删除文章的全部回应
+func (s *MongoReactionStore) DeletePostReactions(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
+
+// ListReactions 按时间正序获取全部回应
+func (s *MongoReactionStore) ListReactions(ctx context.Context) ([]*models.Reaction, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reactions := []*models.Reaction{}
+	if err = cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// MemoryReactionStore 基于内存的表情回应存储，用于本地运行与单元测试
+type MemoryReactionStore struct {
+	mu        sync.Mutex
+	reactions map[reactionKey]*models.Reaction
+}
+
+type reactionKey struct {
+	postID primitive.ObjectID
+	actor  string
+	kind   string
+}
+
+// NewMemoryReactionStore 创建新的MemoryReactionStore实例
+func NewMemoryReactionStore() *MemoryReactionStore {
+	return &MemoryReactionStore{
+		reactions: make(map[reactionKey]*models.Reaction),
+	}
+}
+
+// AddReaction 保存回应
+func (s *MemoryReactionStore) AddReaction(_ context.Context, reaction *models.Reaction) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := reactionKey{reaction.PostID, reaction.Actor, reaction.Kind}
+	if _, ok := s.reactions[key]; ok {
+		return false, nil
+	}
+	r := *reaction
+	s.reactions[key] = &r
+	return true, nil
+}
+
+// RemoveReaction 删除回应
+func (s *MemoryReactionStore) RemoveReaction(_ context.Context, postID primitive.ObjectID, kind, actor string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := reactionKey{postID, actor, kind}
+	if _, ok := s.reactions[key]; !ok {
+		return false, nil
+	}
+	delete(s.reactions, key)
+	return true, nil
+}
+
+// CountReactions 统计文章每种回应的数量
+func (s *MemoryReactionStore) CountReactions(_ context.Context, postID primitive.ObjectID) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int64)
+	for key := range s.reactions {
+		if key.postID == postID {
+			counts[key.kind]++
+		}
+	}
+	return counts, nil
+}
+
+// ListActorKinds 获取读者对文章做出的全部回应种类
+func (s *MemoryReactionStore) ListActorKinds(_ context.Context, postID primitive.ObjectID, actor string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kinds := []string{}
+	for key := range s.reactions {
+		if key.postID == postID && key.actor == actor {
+			kinds = append(kinds, key.kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds, nil
+}
+
+// DeletePostReactions 永久删除文章的全部回应
+func (s *MemoryReactionStore) DeletePostReactions(_ context.Context, postID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.reactions {
+		if key.postID == postID {
+			delete(s.reactions, key)
+		}
+	}
+	return nil
+}
+
+// ListReactions 按时间正序获取全部回应
+func (s *MemoryReactionStore) ListReactions(_ context.Context) ([]*models.Reaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reactions := make([]*models.Reaction, 0, len(s.reactions))
+	for _, reaction := range s.reactions {
+		r := *reaction
+		reactions = append(reactions, &r)
+	}
+	sort.Slice(reactions, func(i, j int) bool {
+		if !reactions[i].CreatedAt.Equal(reactions[j].CreatedAt) {
+			return reactions[i].CreatedAt.Before(reactions[j].CreatedAt)
+		}
+		return reactions[i].ID.Hex() < reactions[j].ID.Hex()
+	})
+	return reactions, nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 相关文章打分：正文相似度、标签重合度与同一分类按权重相加，总分在 0-1 之间
+const (
+	relatedTextWeight     = 0.5
+	relatedTagWeight      = 0.35
+	relatedCategoryWeight = 0.15
+
+	// relatedTitleWeight 标题中的词比正文中的词更能代表文章主题
+	relatedTitleWeight = 3.0
+	// relatedMaxTerms 每篇文章只保留 TF-IDF 最高的若干个词参与比较
+	relatedMaxTerms = 100
+	// relatedKeep 每篇文章保留的候选数，草稿与已下线的文章不作为候选，请求时再过滤掉尚未发布的文章
+	relatedKeep = 30
+	// MaxRelatedLimit 单次请求最多返回的相关文章数
+	MaxRelatedLimit = 20
+)
+
+// RelatedBlog 一篇相关文章及其相似度
+type RelatedBlog struct {
+	Blog  *models.Blog
+	Score float64
+}
+
+// RelatedVisibleBlogs 获取与前端可见文章最相似的 limit 篇前端可见文章
+func (s *BlogService) RelatedVisibleBlogs(id string, limit int) ([]*RelatedBlog, error) {
+	blog, err := s.GetVisibleBlogByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	candidates := s.related.lookup(blog)
+	ids := make([]primitive.ObjectID, len(candidates))
+	scores := make(map[primitive.ObjectID]float64, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.id
+		scores[c.id] = c.score
+	}
+	blogs, err := s.blogsInOrder(ctx, ids, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*RelatedBlog, 0, min(limit, len(blogs)))
+	for _, b := range blogs {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, &RelatedBlog{Blog: b, Score: scores[b.ID]})
+	}
+	return result, nil
+}
+
+// BuildRelatedIndex 为回收站以外的全部文章建立相关文章索引，启动时调用，返回索引的文章数
+func (s *BlogService) BuildRelatedIndex() (int, error) {
+	blogs, err := s.GetAllBlogs()
+	if err != nil {
+		return 0, err
+	}
+	for _, blog := range blogs {
+		s.related.put(blog)
+	}
+	return len(blogs), nil
+}
+
+// relatedIndex 进程内的相关文章索引
+// 文章创建或修改时只重新计算该文章与其他文章的相似度，并增量更新受影响文章的候选列表，
+// 请求时直接读取候选列表，不再逐篇计算；已满的候选列表失去条目后标记为过期，下次读取时重新计算
+type relatedIndex struct {
+	mu       sync.Mutex
+	docs     map[primitive.ObjectID]*relatedDoc
+	postings map[string]map[primitive.ObjectID]float64 // 词 → 包含该词的文章及词频
+	related  map[primitive.ObjectID][]relatedScore     // 按相似度倒序排列的候选
+	stale    map[primitive.ObjectID]bool               // 候选列表可能遗漏了排在保留数之外的文章
+}
+
+// relatedDoc 参与比较的文章特征
+type relatedDoc struct {
+	terms    map[string]float64 // 词频（对数平滑，标题加权）
+	norm     float64            // 建立索引时的 TF-IDF 向量长度
+	tagKeys  []string
+	category *primitive.ObjectID
+
+	show        bool       // 是否展示，草稿不作为其他文章的候选
+	unpublishAt *time.Time // 定时下线时间，到达后不作为其他文章的候选
+}
+
+// candidate 判断文章此刻能否出现在其他文章的候选列表中
+// 尚未到发布时间的文章仍作为候选，发布后无需重新计算，请求时会被过滤
+func (d *relatedDoc) candidate(now time.Time) bool {
+	return d.show && (d.unpublishAt == nil || now.Before(*d.unpublishAt))
+}
+
+type relatedScore struct {
+	id    primitive.ObjectID
+	score float64
+}
+
+func newRelatedIndex() *relatedIndex {
+	return &relatedIndex{
+		docs:     make(map[primitive.ObjectID]*relatedDoc),
+		postings: make(map[string]map[primitive.ObjectID]float64),
+		related:  make(map[primitive.ObjectID][]relatedScore),
+		stale:    make(map[primitive.ObjectID]bool),
+	}
+}
+
+// lookup 返回文章的候选列表，文章尚未建立索引时先加入索引，候选列表过期时重新计算
+func (x *relatedIndex) lookup(blog *models.Blog) []relatedScore {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if _, ok := x.docs[blog.ID]; !ok {
+		x.putLocked(blog)
+	} else if x.stale[blog.ID] {
+		x.rescore(blog.ID)
+	}
+	return slices.Clone(x.related[blog.ID])
+}
+
+// put 加入或更新文章，重新计算它与其他文章的相似度
+func (x *relatedIndex) put(blog *models.Blog) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.putLocked(blog)
+}
+
+// remove 删除文章，并从其他文章的候选列表中移除
+func (x *relatedIndex) remove(id primitive.ObjectID) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.removeLocked(id)
+	for other := range x.related {
+		x.setScore(other, id, 0)
+	}
+}
+
+// recategorize 文章被批量移出分类后更新其特征并重新计算相似度
+func (x *relatedIndex) recategorize(from primitive.ObjectID, to *primitive.ObjectID) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for id, doc := range x.docs {
+		if doc.category != nil && *doc.category == from {
+			doc.category = to
+			x.rescore(id)
+		}
+	}
+}
+
+func (x *relatedIndex) putLocked(blog *models.Blog) {
+	x.removeLocked(blog.ID)
+
+	counts := make(map[string]float64)
+	for t, n := range countTokens(tokenize(blog.Title)) {
+		counts[t] += relatedTitleWeight * math.Log1p(float64(n))
+	}
+	for t, n := range countTokens(tokenize(blog.Content)) {
+		counts[t] += math.Log1p(float64(n))
+	}
+
+	// 只保留最能代表文章的词，文档频率包含文章自身
+	total := float64(len(x.docs) + 1)
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	ranked := make([]weighted, 0, len(counts))
+	for t, tf := range counts {
+		ranked = append(ranked, weighted{t, tf * math.Log1p(total/float64(len(x.postings[t])+1))})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].weight != ranked[j].weight {
+			return ranked[i].weight > ranked[j].weight
+		}
+		return ranked[i].term < ranked[j].term
+	})
+	ranked = ranked[:min(len(ranked), relatedMaxTerms)]
+
+	doc := &relatedDoc{
+		terms:       make(map[string]float64, len(ranked)),
+		tagKeys:     tagKeys(blog.Tags),
+		category:    blog.CategoryID,
+		show:        blog.Show,
+		unpublishAt: blog.UnpublishAt,
+	}
+	var norm float64
+	for _, w := range ranked {
+		doc.terms[w.term] = counts[w.term]
+		norm += w.weight * w.weight
+		if x.postings[w.term] == nil {
+			x.postings[w.term] = make(map[primitive.ObjectID]float64)
+		}
+		x.postings[w.term][blog.ID] = counts[w.term]
+	}
+	doc.norm = math.Sqrt(norm)
+	x.docs[blog.ID] = doc
+	x.rescore(blog.ID)
+}
+
+func (x *relatedIndex) removeLocked(id primitive.ObjectID) {
+	doc, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for t := range doc.terms {
+		delete(x.postings[t], id)
+		if len(x.postings[t]) == 0 {
+			delete(x.postings, t)
+		}
+	}
+	delete(x.docs, id)
+	delete(x.related, id)
+	delete(x.stale, id)
+}
+
+// rescore 计算文章与其他全部文章的相似度，更新双方的候选列表
+func (x *relatedIndex) rescore(id primitive.ObjectID) {
+	doc := x.docs[id]
+	now := time.Now()
+	isCandidate := doc.candidate(now)
+
+	// 通过倒排表累加共同词的 TF-IDF 点积
+	total := float64(len(x.docs))
+	dots := make(map[primitive.ObjectID]float64)
+	for t, tf := range doc.terms {
+		idf := math.Log1p(total / float64(len(x.postings[t])))
+		for other, otherTF := range x.postings[t] {
+			if other != id {
+				dots[other] += tf * otherTF * idf * idf
+			}
+		}
+	}
+
+	scores := []relatedScore{}
+	for other, otherDoc := range x.docs {
+		if other == id {
+			continue
+		}
+		var score float64
+		if dot := dots[other]; dot > 0 && doc.norm > 0 && otherDoc.norm > 0 {
+			score += relatedTextWeight * min(1, dot/(doc.norm*otherDoc.norm))
+		}
+		score += relatedTagWeight * jaccard(doc.tagKeys, otherDoc.tagKeys)
+		if doc.category != nil && otherDoc.category != nil && *doc.category == *otherDoc.category {
+			score += relatedCategoryWeight
+		}
+		if isCandidate {
+			x.setScore(other, id, score)
+		} else {
+			x.setScore(other, id, 0)
+		}
+		if score > 0 && otherDoc.candidate(now) {
+			scores = append(scores, relatedScore{other, score})
+		}
+	}
+
+	sortRelated(scores)
+	x.related[id] = scores[:min(len(scores), relatedKeep)]
+	delete(x.stale, id)
+}
+
+// setScore 更新 target 候选列表中 id 的相似度，score 为 0 时移除
+// 已满的列表中 id 被移除或相似度降到原末位以下时，排在保留数之外的文章可能应当补入，标记为过期
+func (x *relatedIndex) setScore(target, id primitive.ObjectID, score float64) {
+	list := x.related[target]
+	if len(list) == relatedKeep && score < list[len(list)-1].score &&
+		slices.ContainsFunc(list, func(r relatedScore) bool { return r.id == id }) {
+		x.stale[target] = true
+	}
+	list = slices.DeleteFunc(list, func(r relatedScore) bool { return r.id == id })
+	if score > 0 && (len(list) < relatedKeep || score > list[len(list)-1].score) {
+		list = append(list, relatedScore{id, score})
+		sortRelated(list)
+		list = list[:min(len(list), relatedKeep)]
+	}
+	if len(list) == 0 {
+		delete(x.related, target)
+		return
+	}
+	x.related[target] = list
+}
+
+// sortRelated 按相似度倒序排列，相同时按ID倒序（较新的文章在前）
+func sortRelated(list []relatedScore) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].id.Hex() > list[j].id.Hex()
+	})
+}
+
+// jaccard 计算两个标签集合的 Jaccard 相似度
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for _, key := range a {
+		if slices.Contains(b, key) {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
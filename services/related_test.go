@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// relatedIDs 返回候选列表中的文章ID
+func relatedIDs(list []relatedScore) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(list))
+	for i, r := range list {
+		ids[i] = r.id
+	}
+	return ids
+}
+
+func TestRelatedIndexExcludesHiddenPosts(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	post := func(title string, modify func(*models.Blog)) *models.Blog {
+		blog := &models.Blog{ID: primitive.NewObjectID(), Title: title, Content: "Go 语言并发编程", Tags: []string{"go"}, Show: true}
+		if modify != nil {
+			modify(blog)
+		}
+		return blog
+	}
+	target := post("并发", nil)
+
+	tests := []struct {
+		name string
+		blog *models.Blog
+		want bool
+	}{
+		{"已发布", post("已发布", nil), true},
+		{"草稿", post("草稿", func(b *models.Blog) { b.Show = false }), false},
+		{"已下线", post("已下线", func(b *models.Blog) { b.UnpublishAt = &past }), false},
+		{"尚未下线", post("尚未下线", func(b *models.Blog) { b.UnpublishAt = &future }), true},
+		{"定时发布", post("定时发布", func(b *models.Blog) { b.PublishAt = &future }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 无论先后加入索引，不可见的文章都不出现在候选列表中
+			for _, targetFirst := range []bool{true, false} {
+				x := newRelatedIndex()
+				if targetFirst {
+					x.put(target)
+					x.put(tt.blog)
+				} else {
+					x.put(tt.blog)
+					x.put(target)
+				}
+				if got := slices.Contains(relatedIDs(x.lookup(target)), tt.blog.ID); got != tt.want {
+					t.Errorf("targetFirst=%v: 候选中包含 %q = %v, want %v", targetFirst, tt.blog.Title, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRelatedIndexRefillsAfterLosingCandidates(t *testing.T) {
+	x := newRelatedIndex()
+	target := &models.Blog{ID: primitive.NewObjectID(), Title: "目标", Content: "通用内容", Tags: []string{"a"}, Show: true}
+	x.put(target)
+
+	// 标签重合越多越相似，保证候选按加入顺序排列
+	var blogs []*models.Blog
+	for i := 0; i < relatedKeep+5; i++ {
+		tags := []string{"a"}
+		for j := 0; j < relatedKeep+5-i; j++ {
+			tags = append(tags, fmt.Sprintf("t%d", j))
+		}
+		blog := &models.Blog{ID: primitive.NewObjectID(), Title: fmt.Sprintf("文章 %d", i), Content: "通用内容", Tags: tags, Show: true}
+		blogs = append(blogs, blog)
+		x.put(blog)
+	}
+	if got := x.lookup(target); len(got) != relatedKeep {
+		t.Fatalf("候选数 = %d, want %d", len(got), relatedKeep)
+	}
+
+	tests := []struct {
+		name   string
+		change func()
+		lost   *models.Blog
+	}{
+		{"删除文章", func() { x.remove(blogs[0].ID) }, blogs[0]},
+		{"改为草稿", func() {
+			draft := *blogs[1]
+			draft.Show = false
+			x.put(&draft)
+		}, blogs[1]},
+		{"相似度下降", func() {
+			changed := *blogs[2]
+			changed.Tags = nil
+			changed.Content = "无关"
+			x.put(&changed)
+		}, blogs[2]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			got := relatedIDs(x.lookup(target))
+			if len(got) != relatedKeep {
+				t.Errorf("候选数 = %d, want %d", len(got), relatedKeep)
+			}
+			if slices.Contains(got, tt.lost.ID) {
+				t.Errorf("候选中不应包含 %q", tt.lost.Title)
+			}
+		})
+	}
+}
+
+func TestRelatedVisibleBlogsSkipsDrafts(t *testing.T) {
+	blogService := newTestBlogService()
+	create := func(title string, show bool) *models.Blog {
+		blog, err := blogService.CreateBlog(CreateBlogInput{Title: title, Content: "Go 语言并发编程", Author: "alice", Tags: []string{"go"}, Show: show})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blog
+	}
+	target := create("并发入门", true)
+	published := create("并发进阶", true)
+	draft := create("并发草稿", false)
+
+	titles := func() []string {
+		related, err := blogService.RelatedVisibleBlogs(target.ID.Hex(), MaxRelatedLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s []string
+		for _, r := range related {
+			s = append(s, r.Blog.Title)
+		}
+		return s
+	}
+	if got := titles(); !slices.Equal(got, []string{published.Title}) {
+		t.Errorf("相关文章 = %q, want %q", got, []string{published.Title})
+	}
+
+	// 草稿发布后成为候选
+	show := true
+	if _, err := blogService.UpdateBlog(draft.ID.Hex(), UpdateBlogInput{Show: &show}); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(); len(got) != 2 || !slices.Contains(got, draft.Title) {
+		t.Errorf("发布草稿后相关文章 = %q", got)
+	}
+}
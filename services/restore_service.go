@@ -0,0 +1,561 @@
+package services
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidArchive 不是导出的归档文件
+var ErrInvalidArchive = errors.New("不是有效的导出归档，只支持 blog export 生成的 zip 或 tar.gz 文件")
+
+// RestoreCount 一个集合的恢复结果
+type RestoreCount struct {
+	Name     string   // 归档中的文件名，如 users.json
+	Restored int      // 写入的记录数
+	Skipped  int      // 已存在或所属文章不在归档中而跳过的记录数
+	Failed   []string // 失败的记录及原因
+}
+
+// RestoreResult 从归档恢复的结果
+type RestoreResult struct {
+	Posts       []*ImportResult // posts/ 下每个文件的导入结果
+	Collections []*RestoreCount // 各集合的结果，按恢复顺序排列
+}
+
+// RestoreService 从 ExportService 导出的归档恢复全部数据
+type RestoreService struct {
+	importService *ImportService
+	series        SeriesStore
+	users         UserStore
+	media         MediaMetaStore
+	files         MediaStore
+}
+
+// NewRestoreService 创建新的RestoreService实例
+func NewRestoreService(importService *ImportService, series SeriesStore, users UserStore, media MediaMetaStore, files MediaStore) *RestoreService {
+	return &RestoreService{
+		importService: importService,
+		series:        series,
+		users:         users,
+		media:         media,
+		files:         files,
+	}
+}
+
+// restore 一次恢复过程中的状态
+type restore struct {
+	*RestoreService
+	dir      string
+	dryRun   bool
+	archived map[primitive.ObjectID]bool // 试运行时归档中文章的ID
+	posts    map[primitive.ObjectID]bool // 已查询过的文章是否存在且不在回收站中
+}
+
+// RestoreArchive 恢复归档中的全部数据，已存在的记录（按ID匹配）保持不变
+// 依次恢复用户（没有密码哈希，设置之前无法登录）、分类、媒体文件、文章、版本、系列、评论与表情回应；
+// 文章按 ImportMarkdown 的规则导入，版本只恢复本次新建的文章，评论与回应只恢复所属文章存在的记录，
+// 最后按恢复的记录重新计算文章的评论数与回应数
+func (s *RestoreService) RestoreArchive(file string, options ImportOptions) (*RestoreResult, error) {
+	dir, err := os.MkdirTemp("", "blog-restore-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := extractArchive(file, dir); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "categories.json")); err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	r := &restore{
+		RestoreService: s,
+		dir:            dir,
+		dryRun:         options.DryRun,
+		posts:          make(map[primitive.ObjectID]bool),
+	}
+	result := &RestoreResult{}
+
+	users, err := readCollection[*models.User](dir, "users.json")
+	if err != nil {
+		return nil, err
+	}
+	result.Collections = append(result.Collections, r.restoreUsers(users))
+
+	categories, err := readCollection[*models.Category](dir, "categories.json")
+	if err != nil {
+		return nil, err
+	}
+	result.Collections = append(result.Collections, r.restoreCategories(categories))
+
+	media, err := readCollection[*exportMedia](dir, "media.json")
+	if err != nil {
+		return nil, err
+	}
+	result.Collections = append(result.Collections, r.restoreMedia(media))
+
+	// 导入文章前记录版本所属的文章是否已存在，已存在的文章保留自己的版本历史
+	revisions, err := readCollection[*models.Revision](dir, "revisions.json")
+	if err != nil {
+		return nil, err
+	}
+	existed := make(map[primitive.ObjectID]bool)
+	for _, rev := range revisions {
+		if _, ok := existed[rev.BlogID]; !ok {
+			existed[rev.BlogID] = r.postExists(rev.BlogID)
+		}
+	}
+	clear(r.posts)
+
+	postsDir := filepath.Join(dir, "posts")
+	if _, err := os.Stat(postsDir); err == nil {
+		if r.dryRun {
+			if r.archived, err = archivedPostIDs(postsDir); err != nil {
+				return nil, err
+			}
+		}
+		options.NoSource = true
+		if options.Location == nil {
+			options.Location = time.UTC
+		}
+		if result.Posts, err = s.importService.ImportMarkdown(postsDir, options); err != nil {
+			return nil, err
+		}
+	}
+	result.Collections = append(result.Collections, r.restoreRevisions(revisions, existed))
+
+	series, err := readCollection[*models.Series](dir, "series.json")
+	if err != nil {
+		return nil, err
+	}
+	result.Collections = append(result.Collections, r.restoreSeries(series))
+
+	comments, err := readCollection[*models.Comment](dir, "comments.json")
+	if err != nil {
+		return nil, err
+	}
+	commented, count := r.restoreComments(comments)
+	result.Collections = append(result.Collections, count)
+
+	reactions, err := readCollection[*exportReaction](dir, "reactions.json")
+	if err != nil {
+		return nil, err
+	}
+	reacted, count := r.restoreReactions(reactions)
+	result.Collections = append(result.Collections, count)
+
+	if !r.dryRun {
+		if err := r.recount(commented, reacted); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// restoreUsers 恢复不存在的用户，用户名或邮箱已被其他用户使用时失败
+func (r *restore) restoreUsers(users []*models.User) *RestoreCount {
+	count := &RestoreCount{Name: "users.json"}
+	restoreEach(count, users, func(u *models.User) string { return u.Username }, func(ctx context.Context, u *models.User) (bool, error) {
+		if _, err := r.users.GetUserByID(ctx, u.ID); err == nil {
+			return false, nil
+		} else if !errors.Is(err, ErrUserNotFound) {
+			return false, err
+		}
+		if _, err := r.users.GetUserByUsername(ctx, u.Username); err == nil {
+			return false, errors.New("用户名已存在")
+		} else if !errors.Is(err, ErrUserNotFound) {
+			return false, err
+		}
+		if u.Email != "" {
+			if _, err := r.users.GetUserByEmail(ctx, u.Email); err == nil {
+				return false, errors.New("邮箱已存在")
+			} else if !errors.Is(err, ErrUserNotFound) {
+				return false, err
+			}
+		}
+		if r.dryRun {
+			return true, nil
+		}
+		return true, r.users.CreateUser(ctx, u)
+	})
+	return count
+}
+
+// restoreCategories 恢复不存在的分类，保留原有的ID与上级分类
+func (r *restore) restoreCategories(categories []*models.Category) *RestoreCount {
+	store := r.importService.blogService.categories
+	count := &RestoreCount{Name: "categories.json"}
+	restoreEach(count, categories, func(c *models.Category) string { return c.Slug }, func(ctx context.Context, c *models.Category) (bool, error) {
+		if _, err := store.GetCategoryByID(ctx, c.ID); err == nil {
+			return false, nil
+		} else if !errors.Is(err, ErrCategoryNotFound) {
+			return false, err
+		}
+		if r.dryRun {
+			return true, nil
+		}
+		return true, store.CreateCategory(ctx, c)
+	})
+	return count
+}
+
+// restoreMedia 将 media/ 下的文件写入 MediaStore 并恢复元数据，文件内容须与哈希一致
+func (r *restore) restoreMedia(media []*exportMedia) *RestoreCount {
+	count := &RestoreCount{Name: "media.json"}
+	restoreEach(count, media, func(m *exportMedia) string { return m.Key }, func(ctx context.Context, m *exportMedia) (bool, error) {
+		if _, err := hex.DecodeString(m.Hash); err != nil || len(m.Hash) != 2*sha256.Size || m.Key != mediaKey(m.Hash, path.Ext(m.Key)) {
+			return false, errors.New("文件路径与哈希不符")
+		}
+		if _, err := r.media.GetMediaByHash(ctx, m.Hash); err == nil {
+			return false, nil
+		} else if !errors.Is(err, ErrMediaNotFound) {
+			return false, err
+		}
+
+		data, err := os.ReadFile(filepath.Join(r.dir, "media", filepath.FromSlash(m.Key)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, errors.New("归档中缺少该文件")
+		} else if err != nil {
+			return false, err
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != m.Hash {
+			return false, errors.New("文件内容与哈希不符")
+		}
+		if r.dryRun {
+			return true, nil
+		}
+		if err := r.files.Put(ctx, m.Key, bytes.NewReader(data), int64(len(data)), m.ContentType); err != nil {
+			return false, err
+		}
+		media := *m.Media
+		media.Key = m.Key
+		media.Size = int64(len(data))
+		if err := r.media.CreateMedia(ctx, &media); errors.Is(err, errMediaExists) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	return count
+}
+
+// restoreRevisions 以归档中的版本替换本次新建的文章的版本历史，保留原有的版本号
+func (r *restore) restoreRevisions(revisions []*models.Revision, existed map[primitive.ObjectID]bool) *RestoreCount {
+	var blogIDs []primitive.ObjectID
+	byBlog := make(map[primitive.ObjectID][]*models.Revision)
+	for _, rev := range revisions {
+		if byBlog[rev.BlogID] == nil {
+			blogIDs = append(blogIDs, rev.BlogID)
+		}
+		byBlog[rev.BlogID] = append(byBlog[rev.BlogID], rev)
+	}
+
+	count := &RestoreCount{Name: "revisions.json"}
+	for _, id := range blogIDs {
+		revs := byBlog[id]
+		if existed[id] || !r.postExists(id) {
+			count.Skipped += len(revs)
+			continue
+		}
+		if !r.dryRun {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			err := r.importService.blogService.revisions.ReplaceRevisions(ctx, id, revs)
+			cancel()
+			if err != nil {
+				count.Failed = append(count.Failed, id.Hex()+": "+err.Error())
+				continue
+			}
+		}
+		count.Restored += len(revs)
+	}
+	return count
+}
+
+// restoreSeries 恢复不存在的系列，去掉不在归档中的文章
+func (r *restore) restoreSeries(series []*models.Series) *RestoreCount {
+	count := &RestoreCount{Name: "series.json"}
+	restoreEach(count, series, func(s *models.Series) string { return s.Slug }, func(ctx context.Context, s *models.Series) (bool, error) {
+		if _, err := r.series.GetSeriesByID(ctx, s.ID); err == nil {
+			return false, nil
+		} else if !errors.Is(err, ErrSeriesNotFound) {
+			return false, err
+		}
+		postIDs := []primitive.ObjectID{}
+		for _, id := range s.PostIDs {
+			if r.postExists(id) {
+				postIDs = append(postIDs, id)
+			}
+		}
+		s.PostIDs = postIDs
+		if r.dryRun {
+			return true, nil
+		}
+		return true, r.series.CreateSeries(ctx, s)
+	})
+	return count
+}
+
+// restoreComments 恢复所属文章存在的评论，返回恢复了评论的文章
+func (r *restore) restoreComments(comments []*models.Comment) (map[primitive.ObjectID]bool, *RestoreCount) {
+	store := r.importService.blogService.comments
+	commented := make(map[primitive.ObjectID]bool)
+	count := &RestoreCount{Name: "comments.json"}
+	restoreEach(count, comments, func(c *models.Comment) string { return c.ID.Hex() }, func(ctx context.Context, c *models.Comment) (bool, error) {
+		if !r.postExists(c.PostID) {
+			return false, nil
+		}
+		if _, err := store.GetCommentByID(ctx, c.ID); err == nil {
+			return false, nil
+		} else if !errors.Is(err, ErrCommentNotFound) {
+			return false, err
+		}
+		if r.dryRun {
+			return true, nil
+		}
+		if err := store.CreateComment(ctx, c); err != nil {
+			return false, err
+		}
+		commented[c.PostID] = true
+		return true, nil
+	})
+	return commented, count
+}
+
+// restoreReactions 恢复所属文章存在的回应，同一读者已有同种回应时跳过，返回恢复了回应的文章
+func (r *restore) restoreReactions(reactions []*exportReaction) (map[primitive.ObjectID]bool, *RestoreCount) {
+	store := r.importService.blogService.reactions
+	reacted := make(map[primitive.ObjectID]bool)
+	count := &RestoreCount{Name: "reactions.json"}
+	restoreEach(count, reactions, func(re *exportReaction) string { return re.ID.Hex() }, func(ctx context.Context, re *exportReaction) (bool, error) {
+		if re.Actor == "" {
+			return false, errors.New("缺少读者标识")
+		}
+		if !r.postExists(re.PostID) {
+			return false, nil
+		}
+		if r.dryRun {
+			kinds, err := store.ListActorKinds(ctx, re.PostID, re.Actor)
+			return err == nil && !slices.Contains(kinds, re.Kind), err
+		}
+		reaction := *re.Reaction
+		reaction.Actor = re.Actor
+		added, err := store.AddReaction(ctx, &reaction)
+		if added {
+			reacted[re.PostID] = true
+		}
+		return added, err
+	})
+	return reacted, count
+}
+
+// recount 按评论与回应记录重新计算文章上的评论数与回应数
+func (r *restore) recount(commented, reacted map[primitive.ObjectID]bool) error {
+	blogService := r.importService.blogService
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for id := range commented {
+		blog, err := blogService.store.GetBlogByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		approved, err := blogService.comments.CountComments(ctx, CommentQuery{PostID: &id, Statuses: []models.CommentStatus{models.CommentApproved}})
+		if err != nil {
+			return err
+		}
+		if delta := approved - blog.CommentCount; delta != 0 {
+			if err := blogService.store.IncrementComments(ctx, id, delta); err != nil {
+				return err
+			}
+		}
+	}
+	for id := range reacted {
+		counts, err := blogService.reactions.CountReactions(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := blogService.store.SetReactions(ctx, id, counts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postExists 判断文章是否存在且不在回收站中；试运行时归档中的文章视为存在
+func (r *restore) postExists(id primitive.ObjectID) bool {
+	if r.archived[id] {
+		return true
+	}
+	exists, ok := r.posts[id]
+	if !ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		blog, err := r.importService.blogService.store.GetBlogByID(ctx, id)
+		exists = err == nil && blog.DeletedAt == nil
+		r.posts[id] = exists
+	}
+	return exists
+}
+
+// restoreEach 依次恢复 items 中的记录并计数，fn 返回 false 表示跳过，返回错误时以 name 标识失败的记录
+func restoreEach[T any](count *RestoreCount, items []T, name func(T) string, fn func(ctx context.Context, item T) (bool, error)) {
+	for _, item := range items {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		restored, err := fn(ctx, item)
+		cancel()
+		switch {
+		case err != nil:
+			count.Failed = append(count.Failed, fmt.Sprintf("%s: %v", name(item), err))
+		case restored:
+			count.Restored++
+		default:
+			count.Skipped++
+		}
+	}
+}
+
+// readCollection 读取归档中的 JSON 集合，文件不存在时返回 nil
+func readCollection[T any](dir, name string) ([]T, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []T
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", name, err)
+	}
+	return list, nil
+}
+
+// archivedPostIDs 读取 posts/ 下各文章 front matter 中的 id
+func archivedPostIDs(dir string) (map[primitive.ObjectID]bool, error) {
+	ids := make(map[primitive.ObjectID]bool)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isMarkdownFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if post, err := parseMarkdownPost(data, time.UTC); err == nil && !post.ID.IsZero() {
+			ids[post.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// extractArchive 按文件头识别 zip 或 tar.gz 归档并解压到 dir，拒绝指向 dir 之外的路径
+func extractArchive(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	magic, err := bufio.NewReader(f).Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return ErrInvalidArchive
+		}
+		for _, entry := range zr.File {
+			if entry.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := entry.Open()
+			if err != nil {
+				return err
+			}
+			err = extractFile(dir, entry.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case bytes.HasPrefix(magic, []byte("\x1f\x8b")):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return ErrInvalidArchive
+		}
+		defer gz.Close()
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := extractFile(dir, header.Name, tr); err != nil {
+				return err
+			}
+		}
+	}
+	return ErrInvalidArchive
+}
+
+// extractFile 将归档中名为 name（以 / 分隔）的文件写入 dir
+func extractFile(dir, name string, r io.Reader) error {
+	rel := filepath.FromSlash(name)
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("归档中的路径 %q 无效", name)
+	}
+	target := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 版本历史中记录的字段名
+const (
+	RevisionFieldTitle   = "title"
+	RevisionFieldContent = "content"
+	RevisionFieldAuthor  = "author"
+	RevisionFieldTags    = "tags"
+	RevisionFieldShow    = "show"
+	RevisionFieldSlug    = "slug"
+)
+
+// revisionFields 创建文章时的首个版本涉及全部字段
+var revisionFields = []string{
+	RevisionFieldTitle, RevisionFieldContent, RevisionFieldAuthor,
+	RevisionFieldTags, RevisionFieldShow, RevisionFieldSlug,
+}
+
+// RevisionDiff 两个版本之间的差异
+type RevisionDiff struct {
+	From *models.Revision // 不含正文
+	To   *models.Revision // 不含正文
+
+	ChangedFields []string   // 取值不同的字段
+	Lines         []DiffLine // 正文的行级差异
+	Added         int        // 新增的行数
+	Removed       int        // 删除的行数
+}
+
+// changedFields 比较当前文档与待更新字段，返回实际发生变化的字段（浏览次数不计入版本历史）
+func changedFields(current *models.Blog, update BlogUpdate, slug *string) []string {
+	var changed []string
+	if update.Title != nil && *update.Title != current.Title {
+		changed = append(changed, RevisionFieldTitle)
+	}
+	if update.Content != nil && *update.Content != current.Content {
+		changed = append(changed, RevisionFieldContent)
+	}
+	if update.Author != nil && *update.Author != current.Author {
+		changed = append(changed, RevisionFieldAuthor)
+	}
+	if update.Tags != nil && !slices.Equal(update.Tags, current.Tags) {
+		changed = append(changed, RevisionFieldTags)
+	}
+	if update.Show != nil && *update.Show != current.Show {
+		changed = append(changed, RevisionFieldShow)
+	}
+	if slug != nil && *slug != current.Slug {
+		changed = append(changed, RevisionFieldSlug)
+	}
+	return changed
+}
+
+// recordRevision 保存文章的当前快照并按保留数量清理旧版本
+// 版本历史是辅助数据，写入失败只记录日志，不影响文章本身的修改
+func (s *BlogService) recordRevision(ctx context.Context, blog *models.Blog, editor string, changed []string, note string) {
+	rev := &models.Revision{
+		BlogID:        blog.ID,
+		Title:         blog.Title,
+		Content:       blog.Content,
+		Tags:          blog.Tags,
+		Show:          blog.Show,
+		Slug:          blog.Slug,
+		Editor:        editor,
+		ChangedFields: changed,
+		Note:          note,
+		CreatedAt:     blog.UpdatedAt,
+	}
+	if err := s.revisions.CreateRevision(ctx, rev); err != nil {
+		log.Printf("记录文章 %s 的版本失败: %v", blog.ID.Hex(), err)
+		return
+	}
+	if s.revisionLimit > 0 {
+		if err := s.revisions.PruneRevisions(ctx, blog.ID, s.revisionLimit); err != nil {
+			log.Printf("清理文章 %s 的旧版本失败: %v", blog.ID.Hex(), err)
+		}
+	}
+}
+
+// ListRevisions 按版本号倒序分页获取文章的版本（不含正文），同时返回总数
+func (s *BlogService) ListRevisions(id string, page, limit int64) ([]*models.Revision, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := s.existingBlogID(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.revisions.ListRevisions(ctx, objID, (page-1)*limit, limit)
+}
+
+// GetRevision 获取文章的指定版本
+func (s *BlogService) GetRevision(id string, number int64) (*models.Revision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := s.existingBlogID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.revisions.GetRevision(ctx, objID, number)
+}
+
+// DiffRevisions 比较文章的两个版本，返回字段变化与正文的行级差异
+func (s *BlogService) DiffRevisions(id string, from, to int64) (*RevisionDiff, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := s.existingBlogID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	oldRev, err := s.revisions.GetRevision(ctx, objID, from)
+	if err != nil {
+		return nil, err
+	}
+	newRev, err := s.revisions.GetRevision(ctx, objID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RevisionDiff{Lines: DiffText(oldRev.Content, newRev.Content)}
+	for _, l := range diff.Lines {
+		switch l.Op {
+		case DiffInsert:
+			diff.Added++
+		case DiffDelete:
+			diff.Removed++
+		}
+	}
+
+	if oldRev.Title != newRev.Title {
+		diff.ChangedFields = append(diff.ChangedFields, RevisionFieldTitle)
+	}
+	if oldRev.Content != newRev.Content {
+		diff.ChangedFields = append(diff.ChangedFields, RevisionFieldContent)
+	}
+	if !slices.Equal(oldRev.Tags, newRev.Tags) {
+		diff.ChangedFields = append(diff.ChangedFields, RevisionFieldTags)
+	}
+	if oldRev.Show != newRev.Show {
+		diff.ChangedFields = append(diff.ChangedFields, RevisionFieldShow)
+	}
+	if oldRev.Slug != newRev.Slug {
+		diff.ChangedFields = append(diff.ChangedFields, RevisionFieldSlug)
+	}
+
+	oldRev.Content, newRev.Content = "", ""
+	diff.From, diff.To = oldRev, newRev
+	return diff, nil
+}
+
+// RollbackBlog 将文章的标题、正文、标签与展示状态恢复到指定版本，并记录为新版本
+// slug 不随回滚变化，避免已发布的链接失效
+func (s *BlogService) RollbackBlog(id string, number int64, editor string) (*models.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := s.existingBlogID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.revisions.GetRevision(ctx, objID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := rev.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return s.updateBlog(ctx, objID, UpdateBlogInput{
+		Title:   &rev.Title,
+		Content: &rev.Content,
+		Tags:    tags,
+		Show:    &rev.Show,
+		Editor:  editor,
+	}, fmt.Sprintf("回滚到版本 %d", number))
+}
+
+// existingBlogID 解析文章ID并确认文章存在且不在回收站中
+func (s *BlogService) existingBlogID(ctx context.Context, id string) (primitive.ObjectID, error) {
+	objID, err := parseBlogID(id)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	blog, err := s.store.GetBlogByID(ctx, objID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if blog.DeletedAt != nil {
+		return primitive.NilObjectID, ErrBlogNotFound
+	}
+	return objID, nil
+}
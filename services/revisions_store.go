@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrRevisionNotFound 版本不存在
+var ErrRevisionNotFound = errors.New("版本不存在")
+
+// RevisionStore 文章版本的存储接口
+type RevisionStore interface {
+	// CreateRevision 保存新版本，并为其分配文章内递增的 Number
+	CreateRevision(ctx context.Context, rev *models.Revision) error
+	// ListRevisions 按版本号倒序分页获取文章的版本（不含正文），同时返回总数
+	ListRevisions(ctx context.Context, blogID primitive.ObjectID, offset, limit int64) ([]*models.Revision, int64, error)
+	// GetRevision 获取文章的指定版本，不存在时返回 ErrRevisionNotFound
+	GetRevision(ctx context.Context, blogID primitive.ObjectID, number int64) (*models.Revision, error)
+	// PruneRevisions 只保留文章最新的 keep 个版本
+	PruneRevisions(ctx context.Context, blogID primitive.ObjectID, keep int64) error
+	// DeleteRevisions 删除文章的全部版本
+	DeleteRevisions(ctx context.Context, blogID primitive.ObjectID) error
+	// ListAllRevisions 按文章与版本号正序获取全部文章的全部版本（含正文）
+	ListAllRevisions(ctx context.Context) ([]*models.Revision, error)
+	// ReplaceRevisions 以 revs 替换文章的全部版本，保留其中的ID与版本号，从导出归档恢复时使用
+	ReplaceRevisions(ctx context.Context, blogID primitive.ObjectID, revs []*models.Revision) error
+}
+
+// MongoRevisionStore 基于 MongoDB 的版本存储
+type MongoRevisionStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRevisionStore 创建新的MongoRevisionStore实例
+func NewMongoRevisionStore(client *mongo.Client, dbName, collectionName string) *MongoRevisionStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoRevisionStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建 (blog_id, number) 唯一索引，保证版本号不重复
+func (s *MongoRevisionStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "number", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// CreateRevision 保存新版本，并发写入导致版本号冲突时重试
+func (s *MongoRevisionStore) CreateRevision(ctx context.Context, rev *models.Revision) error {
+	for attempt := 0; ; attempt++ {
+		var latest models.Revision
+		err := s.collection.FindOne(ctx, bson.M{"blog_id": rev.BlogID},
+			options.FindOne().SetSort(bson.M{"number": -1}).SetProjection(bson.M{"number": 1}),
+		).Decode(&latest)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		rev.ID = primitive.NewObjectID()
+		rev.Number = latest.Number + 1
+		_, err = s.collection.InsertOne(ctx, rev)
+		if mongo.IsDuplicateKeyError(err) && attempt < 5 {
+			continue
+		}
+		return err
+	}
+}
+
+// ListRevisions 按版本号倒序分页获取文章的版本
+func (s *MongoRevisionStore) ListRevisions(ctx context.Context, blogID primitive.ObjectID, offset, limit int64) ([]*models.Revision, int64, error) {
+	filter := bson.M{"blog_id": blogID}
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"number": -1}).
+		SetProjection(bson.M{"content": 0}).
+		SetSkip(offset)
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	revs := []*models.Revision{}
+	if err = cursor.All(ctx, &revs); err != nil {
+		return nil, 0, err
+	}
+	return revs, total, nil
+}
+
+// GetRevision 获取文章的指定版本
+func (s *MongoRevisionStore) GetRevision(ctx context.Context, blogID primitive.ObjectID, number int64) (*models.Revision, error) {
+	var rev models.Revision
+	err := s.collection.FindOne(ctx, bson.M{"blog_id": blogID, "number": number}).Decode(&rev)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// PruneRevisions 只保留文章最新的 keep 个版本
+func (s *MongoRevisionStore) PruneRevisions(ctx context.Context, blogID primitive.ObjectID, keep int64) error {
+	// 找到第 keep 新的版本号，删除比它更早的版本
+	var boundary models.Revision
+	err := s.collection.FindOne(ctx, bson.M{"blog_id": blogID},
+		options.FindOne().SetSort(bson.M{"number": -1}).SetSkip(keep-1).SetProjection(bson.M{"number": 1}),
+	).Decode(&boundary)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.collection.DeleteMany(ctx, bson.M{"blog_id": blogID, "number": bson.M{"$lt": boundary.Number}})
+	return err
+}
+
+// DeleteRevisions 删除文章的全部版本
+func (s *MongoRevisionStore) DeleteRevisions(ctx context.Context, blogID primitive.ObjectID) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"blog_id": blogID})
+	return err
+}
+
+// ListAllRevisions 按文章与版本号正序获取全部版本
+func (s *MongoRevisionStore) ListAllRevisions(ctx context.Context) ([]*models.Revision, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "blog_id", Value: 1}, {Key: "number", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revs := []*models.Revision{}
+	if err = cursor.All(ctx, &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// ReplaceRevisions 删除文章已有的版本后批量写入 revs
+func (s *MongoRevisionStore) ReplaceRevisions(ctx context.Context, blogID primitive.ObjectID, revs []*models.Revision) error {
+	if _, err := s.collection.DeleteMany(ctx, bson.M{"blog_id": blogID}); err != nil {
+		return err
+	}
+	if len(revs) == 0 {
+		return nil
+	}
+	docs := make([]any, len(revs))
+	for i, rev := range revs {
+		c := cloneRevision(rev)
+		c.BlogID = blogID
+		docs[i] = c
+	}
+	_, err := s.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// MemoryRevisionStore 基于内存的版本存储，用于本地运行与单元测试
+type MemoryRevisionStore struct {
+	mu   sync.RWMutex
+	revs map[primitive.ObjectID][]*models.Revision // 按版本号正序排列
+}
+
+// NewMemoryRevisionStore 创建新的MemoryRevisionStore实例
+func NewMemoryRevisionStore() *MemoryRevisionStore {
+	return &MemoryRevisionStore{
+		revs: make(map[primitive.ObjectID][]*models.Revision),
+	}
+}
+
+// CreateRevision 保存新版本
+func (s *MemoryRevisionStore) CreateRevision(_ context.Context, rev *models.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.revs[rev.BlogID]
+	rev.ID = primitive.NewObjectID()
+	rev.Number = 1
+	if len(list) > 0 {
+		rev.Number = list[len(list)-1].Number + 1
+	}
+	s.revs[rev.BlogID] = append(list, cloneRevision(rev))
+	return nil
+}
+
+// ListRevisions 按版本号倒序分页获取文章的版本
+func (s *MemoryRevisionStore) ListRevisions(_ context.Context, blogID primitive.ObjectID, offset, limit int64) ([]*models.Revision, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.revs[blogID]
+	total := int64(len(list))
+	revs := []*models.Revision{}
+	for i := total - 1 - offset; i >= 0 && (limit <= 0 || int64(len(revs)) < limit); i-- {
+		rev := cloneRevision(list[i])
+		rev.Content = ""
+		revs = append(revs, rev)
+	}
+	return revs, total, nil
+}
+
+// GetRevision 获取文章的指定版本
+func (s *MemoryRevisionStore) GetRevision(_ context.Context, blogID primitive.ObjectID, number int64) (*models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.revs[blogID]
+	i := sort.Search(len(list), func(i int) bool { return list[i].Number >= number })
+	if i == len(list) || list[i].Number != number {
+		return nil, ErrRevisionNotFound
+	}
+	return cloneRevision(list[i]), nil
+}
+
+// PruneRevisions 只保留文章最新的 keep 个版本
+func (s *MemoryRevisionStore) PruneRevisions(_ context.Context, blogID primitive.ObjectID, keep int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.revs[blogID]
+	if int64(len(list)) > keep {
+		s.revs[blogID] = append([]*models.Revision{}, list[int64(len(list))-keep:]...)
+	}
+	return nil
+}
+
+// DeleteRevisions 删除文章的全部版本
+func (s *MemoryRevisionStore) DeleteRevisions(_ context.Context, blogID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.revs, blogID)
+	return nil
+}
+
+// ListAllRevisions 按文章与版本号正序获取全部版本
+func (s *MemoryRevisionStore) ListAllRevisions(_ context.Context) ([]*models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]primitive.ObjectID, 0, len(s.revs))
+	for id := range s.revs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Hex() < ids[j].Hex() })
+
+	revs := []*models.Revision{}
+	for _, id := range ids {
+		for _, rev := range s.revs[id] {
+			revs = append(revs, cloneRevision(rev))
+		}
+	}
+	return revs, nil
+}
+
+// cloneRevision 复制版本，避免调用方修改存储内部的数据
+func cloneRevision(rev *models.Revision) *models.Revision {
+	c := *rev
+	if rev.Tags != nil {
+		c.Tags = append([]string{}, rev.Tags...)
+	}
+	c.ChangedFields = append([]string{}, rev.ChangedFields...)
+	return &c
+}
+
+// ReplaceRevisions 以 revs 替换文章的全部版本，按版本号正序保存
+func (s *MemoryRevisionStore) ReplaceRevisions(_ context.Context, blogID primitive.ObjectID, revs []*models.Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*models.Revision, len(revs))
+	for i, rev := range revs {
+		list[i] = cloneRevision(rev)
+		list[i].BlogID = blogID
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Number < list[j].Number })
+	if len(list) == 0 {
+		delete(s.revs, blogID)
+	} else {
+		s.revs[blogID] = list
+	}
+	return nil
+}
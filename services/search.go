@@ -0,0 +1,304 @@
+package services
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"blog/models"
+)
+
+// 搜索字段权重：标题命中比标签、正文更重要
+const (
+	searchTitleWeight   = 3.0
+	searchTagWeight     = 2.0
+	searchContentWeight = 1.0
+
+	// snippetRunes 摘要片段的长度（字符数）
+	snippetRunes = 120
+
+	// maxSearchCandidates 参与排序的候选文章上限，超出时只对发布时间最新的部分排序
+	maxSearchCandidates = 1000
+)
+
+// SearchHit 一条搜索结果
+type SearchHit struct {
+	Blog    *models.Blog
+	Score   float64
+	Title   string // 高亮后的标题（已 HTML 转义，命中部分以 <mark> 包裹）
+	Snippet string // 高亮后的正文片段（已 HTML 转义，命中部分以 <mark> 包裹）
+}
+
+// SearchPage 一页搜索结果
+type SearchPage struct {
+	Hits  []*SearchHit
+	Total int64
+}
+
+// isCJK 判断字符是否属于需要按字切分的中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// tokenize 将查询切分为检索词：拉丁字母与数字按单词切分并转为小写，
+// 连续的中日韩文字按相邻二字（bigram）切分，单个汉字单独成词
+func tokenize(text string) []string {
+	return splitTokens(text, false)
+}
+
+// indexTokens 将文章内容切分为检索词，在 tokenize 的基础上为连续的中日韩文字额外加入每个单字，
+// 使单字查询也能命中多字词中的字
+func indexTokens(text string) []string {
+	return splitTokens(text, true)
+}
+
+// splitTokens 切分文本，unigrams 为 true 时连续的中日韩文字同时按单字切分
+func splitTokens(text string, unigrams bool) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			tokens = append(tokens, string(cjk))
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+			if unigrams {
+				for _, r := range cjk {
+					tokens = append(tokens, string(r))
+				}
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// uniqueTokens 对检索词去重，保持首次出现的顺序
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	unique := tokens[:0]
+	for _, t := range tokens {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// blogSearchTerms 计算文章的检索词（标题、标签与正文），存储层据此建立索引
+func blogSearchTerms(blog *models.Blog) []string {
+	return uniqueTokens(indexTokens(blog.Title + "\n" + strings.Join(blog.Tags, "\n") + "\n" + blog.Content))
+}
+
+// countTokens 统计每个检索词出现的次数
+func countTokens(tokens []string) map[string]int {
+	counts := make(map[string]int)
+	for _, t := range tokens {
+		counts[t]++
+	}
+	return counts
+}
+
+// rankedCandidate 打分后的候选文章
+type rankedCandidate struct {
+	*models.SearchCandidate
+	score float64
+}
+
+// rankBlogs 对候选文章按相关度打分并倒序排列
+// 采用 TF-IDF：词频做对数平滑并按字段加权，文档频率基于候选集与可见文章总数估算，
+// 再乘以命中检索词的比例，使同时命中多个词的文章排在前面；
+// 候选文章不含正文，只出现在正文中的词按出现一次计分
+func rankBlogs(candidates []*models.SearchCandidate, terms []string, totalDocs int64) []*rankedCandidate {
+	type fieldCounts struct {
+		title, tags, content map[string]int
+	}
+	counts := make([]fieldCounts, len(candidates))
+	df := make(map[string]int, len(terms))
+	for i, c := range candidates {
+		fc := fieldCounts{
+			title:   countTokens(indexTokens(c.Title)),
+			tags:    countTokens(indexTokens(strings.Join(c.Tags, "\n"))),
+			content: make(map[string]int),
+		}
+		for _, t := range c.SearchTerms {
+			if fc.title[t]+fc.tags[t] == 0 {
+				fc.content[t] = 1
+			}
+		}
+		counts[i] = fc
+		for _, t := range terms {
+			if fc.title[t]+fc.tags[t]+fc.content[t] > 0 {
+				df[t]++
+			}
+		}
+	}
+
+	n := float64(max(totalDocs, int64(len(candidates))))
+	ranked := make([]*rankedCandidate, 0, len(candidates))
+	for i, c := range candidates {
+		fc := counts[i]
+		score, matched := 0.0, 0
+		for _, t := range terms {
+			if df[t] == 0 {
+				continue
+			}
+			tf := searchTitleWeight*math.Log1p(float64(fc.title[t])) +
+				searchTagWeight*math.Log1p(float64(fc.tags[t])) +
+				searchContentWeight*math.Log1p(float64(fc.content[t]))
+			if tf == 0 {
+				continue
+			}
+			matched++
+			score += tf * math.Log1p(n/float64(df[t]))
+		}
+		if matched == 0 {
+			continue
+		}
+		score *= float64(matched) / float64(len(terms))
+		ranked = append(ranked, &rankedCandidate{SearchCandidate: c, score: score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].PublishedAt.After(ranked[j].PublishedAt)
+	})
+	return ranked
+}
+
+// matchRanges 找出文本中所有检索词的命中区间（按字符下标，半开区间），并合并重叠部分
+func matchRanges(text []rune, terms []string) [][2]int {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var ranges [][2]int
+	for _, t := range terms {
+		tr := []rune(t)
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if !runesEqual(lower[i:i+len(tr)], tr) {
+				continue
+			}
+			// 拉丁单词要求完整匹配，避免 "go" 命中 "good"
+			if !isCJK(tr[0]) && (i > 0 && isWordRune(lower[i-1]) || i+len(tr) < len(lower) && isWordRune(lower[i+len(tr)])) {
+				continue
+			}
+			ranges = append(ranges, [2]int{i, i + len(tr)})
+		}
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], r[1])
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func isWordRune(r rune) bool {
+	return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// highlight 将 text[start:end] 转义为 HTML，并用 <mark> 包裹命中区间
+func highlight(text []rune, start, end int, ranges [][2]int) string {
+	var b strings.Builder
+	pos := start
+	for _, r := range ranges {
+		s, e := max(r[0], start), min(r[1], end)
+		if s >= e {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(text[pos:s])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(text[s:e])))
+		b.WriteString("</mark>")
+		pos = e
+	}
+	b.WriteString(html.EscapeString(string(text[pos:end])))
+	return b.String()
+}
+
+// highlightTitle 高亮标题中的命中词
+func highlightTitle(title string, terms []string) string {
+	text := []rune(title)
+	return highlight(text, 0, len(text), matchRanges(text, terms))
+}
+
+// buildSnippet 从正文中选取命中最密集的片段并高亮，正文未命中时返回开头部分
+func buildSnippet(content string, terms []string) string {
+	text := []rune(strings.Join(strings.Fields(content), " "))
+	ranges := matchRanges(text, terms)
+
+	// 以每个命中区间为起点尝试窗口，选择包含命中最多的窗口
+	start, best := 0, 0
+	for i, r := range ranges {
+		n := 0
+		for _, other := range ranges[i:] {
+			if other[1] > r[0]+snippetRunes {
+				break
+			}
+			n++
+		}
+		if n > best {
+			best, start = n, r[0]
+		}
+	}
+	// 在命中位置前保留少量上下文
+	start = max(0, start-snippetRunes/6)
+	end := min(len(text), start+snippetRunes)
+
+	snippet := highlight(text, start, end, ranges)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
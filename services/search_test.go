@@ -0,0 +1,218 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/models"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"Go1.22 发布", []string{"go1", "22", "发布"}},
+		{"博客系统", []string{"博客", "客系", "系统"}},
+		{"用 Go 写博客", []string{"用", "go", "写博", "博客"}},
+		{"ひらがなカタカナ", []string{"ひら", "らが", "がな", "なカ", "カタ", "タカ", "カナ"}},
+		{"a-b_c", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIndexTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Go", []string{"go"}},
+		{"博", []string{"博"}},
+		{"博客", []string{"博客", "博", "客"}},
+		{"写博客 Go", []string{"写博", "博客", "写", "博", "客", "go"}},
+	}
+	for _, tt := range tests {
+		if got := indexTokens(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("indexTokens(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBlogSearchTermsMatchQueries(t *testing.T) {
+	blog := &models.Blog{Title: "我的博客", Tags: []string{"Golang"}, Content: "使用 MongoDB 存储文章"}
+	terms := blogSearchTerms(blog)
+
+	// 每个查询切分出的检索词都应出现在文章的检索词中
+	for _, q := range []string{"博客", "博", "客", "golang", "MongoDB", "存储文章", "储"} {
+		for _, term := range tokenize(q) {
+			if !slices.Contains(terms, term) {
+				t.Errorf("查询 %q 的检索词 %q 不在文章检索词 %q 中", q, term, terms)
+			}
+		}
+	}
+	// 多字查询只使用 bigram，不会退化为单字匹配
+	if got := tokenize("博文"); slices.Contains(terms, got[0]) {
+		t.Errorf("查询 %q 不应命中", "博文")
+	}
+}
+
+func TestRankBlogs(t *testing.T) {
+	now := time.Now()
+	names := make(map[*models.SearchCandidate]string)
+	blog := func(title, content string, tags ...string) *models.SearchCandidate {
+		now = now.Add(-time.Hour)
+		terms := blogSearchTerms(&models.Blog{Title: title, Content: content, Tags: tags})
+		c := &models.SearchCandidate{Title: title, Tags: tags, SearchTerms: terms, PublishedAt: now}
+		names[c] = title + "|" + content
+		return c
+	}
+	inTitle := blog("Go 并发", "正文")
+	inTags := blog("笔记", "正文", "Go")
+	inContent := blog("笔记", "介绍 Go 的用法")
+	both := blog("Go 与 Rust", "正文")
+	none := blog("Rust", "正文")
+
+	tests := []struct {
+		name  string
+		terms []string
+		want  []*models.SearchCandidate
+	}{
+		// 标题命中相同时按发布时间倒序
+		{"标题优先于标签与正文", []string{"go"}, []*models.SearchCandidate{inTitle, both, inTags, inContent}},
+		// 只命中一个词时，较少见的 rust 得分更高
+		{"命中更多检索词的排在前面", []string{"go", "rust"}, []*models.SearchCandidate{both, none, inTitle, inTags, inContent}},
+		{"单字命中多字词", []string{"并"}, []*models.SearchCandidate{inTitle}},
+		{"没有命中", []string{"python"}, nil},
+	}
+	candidates := []*models.SearchCandidate{inContent, none, inTags, both, inTitle}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := rankBlogs(candidates, tt.terms, 10)
+			var got []*models.SearchCandidate
+			for _, hit := range hits {
+				got = append(got, hit.SearchCandidate)
+			}
+			if !slices.Equal(got, tt.want) {
+				titles := func(candidates []*models.SearchCandidate) (s []string) {
+					for _, c := range candidates {
+						s = append(s, names[c])
+					}
+					return s
+				}
+				t.Errorf("rankBlogs = %q, want %q", titles(got), titles(tt.want))
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		title string
+		terms []string
+		want  string
+	}{
+		{"Go is good", []string{"go"}, "<mark>Go</mark> is good"},
+		{"<b>博客</b>系统", []string{"博客", "系统"}, "&lt;b&gt;<mark>博客</mark>&lt;/b&gt;<mark>系统</mark>"},
+		{"我的博客", []string{"博"}, "我的<mark>博</mark>客"},
+		{"重叠的词组", []string{"重叠", "叠的"}, "<mark>重叠的</mark>词组"},
+	}
+	for _, tt := range tests {
+		if got := highlightTitle(tt.title, tt.terms); got != tt.want {
+			t.Errorf("highlightTitle(%q, %q) = %q, want %q", tt.title, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestSearchVisibleBlogsSingleCJKCharacter(t *testing.T) {
+	blogService := newTestBlogService()
+	for _, input := range []CreateBlogInput{
+		{Title: "搭建博客", Content: "记录过程", Author: "alice", Show: true},
+		{Title: "读书笔记", Content: "无关内容", Author: "alice", Show: true},
+		{Title: "博客草稿", Content: "未发布", Author: "alice", Show: false},
+	} {
+		if _, err := blogService.CreateBlog(input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"博", []string{"搭建博客"}},
+		{"博客", []string{"搭建博客"}},
+		{"笔", []string{"读书笔记"}},
+		{"记", []string{"读书笔记", "搭建博客"}},
+		{"博文", nil},
+	}
+	for _, tt := range tests {
+		page, err := blogService.SearchVisibleBlogs(tt.q, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, hit := range page.Hits {
+			got = append(got, hit.Blog.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SearchVisibleBlogs(%q) = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestSearchVisibleBlogsCandidateLimit(t *testing.T) {
+	blogService := newTestBlogService()
+	// 最早的文章标题命中，得分最高，但超出候选上限后不参与排序
+	oldest := newTestBlog(0, func(b *models.Blog) { b.Title, b.Content = "Go Go", "Go 并发" })
+	blogs := []*models.Blog{oldest}
+	for n := 1; n <= maxSearchCandidates; n++ {
+		blogs = append(blogs, newTestBlog(n, func(b *models.Blog) { b.Content = fmt.Sprintf("介绍 Go 的用法 %d", n) }))
+	}
+	for _, blog := range blogs {
+		blog.SearchTerms = blogSearchTerms(blog)
+	}
+	mustCreateBlogs(t, blogService.store, blogs...)
+
+	page, err := blogService.SearchVisibleBlogs("go", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != maxSearchCandidates {
+		t.Errorf("Total = %d, want %d", page.Total, maxSearchCandidates)
+	}
+	var got []string
+	for _, hit := range page.Hits {
+		got = append(got, hit.Blog.Slug)
+		// 当前页读取完整文章以生成摘要
+		if hit.Blog.Content == "" || !strings.Contains(hit.Snippet, "<mark>Go</mark>") {
+			t.Errorf("%s 的正文/摘要 = %q/%q", hit.Blog.Slug, hit.Blog.Content, hit.Snippet)
+		}
+	}
+	want := []string{fmt.Sprintf("post-%d", maxSearchCandidates-2), fmt.Sprintf("post-%d", maxSearchCandidates-3)}
+	if !slices.Equal(got, want) {
+		t.Errorf("第 2 页 = %q, want %q", got, want)
+	}
+
+	page, err = blogService.SearchVisibleBlogs("并发", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || page.Hits[0].Blog.ID != oldest.ID {
+		t.Errorf("候选未超出上限时应命中最早的文章: %d", page.Total)
+	}
+}
+
+// newTestBlogService 基于内存存储创建 BlogService
+func newTestBlogService() *BlogService {
+	return NewBlogService(NewMemoryBlogStore(), NewMemoryRevisionStore(), NewMemoryCategoryStore(),
+		NewMemoryCommentStore(), NewMemoryReactionStore(), NewMemorySeriesStore(), 50)
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidSeriesPosts 系列中的文章ID无效、重复或文章不存在
+var ErrInvalidSeriesPosts = errors.New("系列中的文章无效：文章须存在且不能重复")
+
+// SeriesService 处理系列文章的业务逻辑，系列只保存文章ID与顺序，增删、排序不会修改文章本身
+type SeriesService struct {
+	store       SeriesStore
+	blogService *BlogService
+}
+
+// NewSeriesService 创建新的SeriesService实例
+func NewSeriesService(store SeriesStore, blogService *BlogService) *SeriesService {
+	return &SeriesService{
+		store:       store,
+		blogService: blogService,
+	}
+}
+
+// SeriesSummary 系列及其中可见文章的数量
+type SeriesSummary struct {
+	Series    *models.Series
+	PostCount int
+}
+
+// SeriesNav 文章在某个系列中的位置及前后篇
+type SeriesNav struct {
+	Series   *models.Series
+	Position int          // 在系列中的序号，从 1 开始
+	Total    int          // 系列中的文章数
+	Prev     *models.Blog // 上一篇，没有时为 nil
+	Next     *models.Blog // 下一篇，没有时为 nil
+}
+
+// ListSeries 获取全部系列，供后台管理使用
+func (s *SeriesService) ListSeries() ([]*models.Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return s.store.ListSeries(ctx)
+}
+
+// ListVisibleSeries 获取全部系列及其中前端可见的文章数，不含可见文章的系列不返回
+func (s *SeriesService) ListVisibleSeries() ([]*SeriesSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := s.store.ListSeries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries := []*SeriesSummary{}
+	for _, series := range list {
+		posts, err := s.blogService.blogsInOrder(ctx, series.PostIDs, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(posts) > 0 {
+			summaries = append(summaries, &SeriesSummary{Series: series, PostCount: len(posts)})
+		}
+	}
+	return summaries, nil
+}
+
+// GetSeries 根据ID获取系列及其中的全部文章（包含草稿），供后台管理使用
+func (s *SeriesService) GetSeries(id string) (*models.Series, []*models.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseSeriesID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	series, err := s.store.GetSeriesByID(ctx, objID)
+	if err != nil {
+		return nil, nil, err
+	}
+	posts, err := s.blogService.blogsInOrder(ctx, series.PostIDs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return series, posts, nil
+}
+
+// GetVisibleSeriesBySlug 根据 slug 获取系列及其中前端可见的文章（按系列顺序）
+func (s *SeriesService) GetVisibleSeriesBySlug(slug string) (*models.Series, []*models.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	series, err := s.store.GetSeriesBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	posts, err := s.blogService.blogsInOrder(ctx, series.PostIDs, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(posts) == 0 {
+		return nil, nil, ErrSeriesNotFound
+	}
+	return series, posts, nil
+}
+
+// Navigation 获取文章所属的系列及前后篇，visibleOnly 为 true 时只考虑前端可见的文章
+func (s *SeriesService) Navigation(blog *models.Blog, visibleOnly bool) ([]*SeriesNav, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := s.store.ListSeriesByPost(ctx, blog.ID)
+	if err != nil {
+		return nil, err
+	}
+	navs := []*SeriesNav{}
+	for _, series := range list {
+		posts, err := s.blogService.blogsInOrder(ctx, series.PostIDs, visibleOnly)
+		if err != nil {
+			return nil, err
+		}
+		for i, post := range posts {
+			if post.ID != blog.ID {
+				continue
+			}
+			nav := &SeriesNav{Series: series, Position: i + 1, Total: len(posts)}
+			if i > 0 {
+				nav.Prev = posts[i-1]
+			}
+			if i+1 < len(posts) {
+				nav.Next = posts[i+1]
+			}
+			navs = append(navs, nav)
+			break
+		}
+	}
+	return navs, nil
+}
+
+// SeriesInput 创建或更新系列的参数，更新时 nil 字段表示不修改
+type SeriesInput struct {
+	Title       *string
+	Slug        *string // 创建时为空则根据标题生成
+	Description *string
+	PostIDs     []string // 按阅读顺序排列的文章ID，更新时完整替换（用于排序与批量移除）
+}
+
+// CreateSeries 创建新系列
+func (s *SeriesService) CreateSeries(input SeriesInput) (*models.Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := primitive.NewObjectID()
+	series := &models.Series{ID: id}
+	if input.Title != nil {
+		series.Title = *input.Title
+	}
+	if input.Description != nil {
+		series.Description = *input.Description
+	}
+
+	var err error
+	if input.Slug != nil && *input.Slug != "" {
+		if err = s.checkSlug(ctx, *input.Slug, id); err != nil {
+			return nil, err
+		}
+		series.Slug = *input.Slug
+	} else if series.Slug, err = s.uniqueSlug(ctx, Slugify(series.Title), id); err != nil {
+		return nil, err
+	}
+
+	if series.PostIDs, err = s.parsePostIDs(ctx, input.PostIDs); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	series.CreatedAt = now
+	series.UpdatedAt = now
+	if err := s.store.CreateSeries(ctx, series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// UpdateSeries 更新系列的标题、slug、简介或文章顺序
+func (s *SeriesService) UpdateSeries(id string, input SeriesInput) (*models.Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseSeriesID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	update := SeriesUpdate{
+		Title:       input.Title,
+		Description: input.Description,
+		UpdatedAt:   time.Now(),
+	}
+	if input.Slug != nil {
+		if err := s.checkSlug(ctx, *input.Slug, objID); err != nil {
+			return nil, err
+		}
+		update.Slug = input.Slug
+	}
+	if input.PostIDs != nil {
+		if update.PostIDs, err = s.parsePostIDs(ctx, input.PostIDs); err != nil {
+			return nil, err
+		}
+	}
+	return s.store.UpdateSeries(ctx, objID, update)
+}
+
+// AddPost 将文章加入系列，position 为从 0 开始的插入位置，< 0 表示追加到末尾
+func (s *SeriesService) AddPost(id, postID string, position int) (*models.Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseSeriesID(id)
+	if err != nil {
+		return nil, err
+	}
+	postIDs, err := s.parsePostIDs(ctx, []string{postID})
+	if err != nil {
+		return nil, err
+	}
+	return s.store.AddSeriesPost(ctx, objID, postIDs[0], position, time.Now())
+}
+
+// RemovePost 将文章移出系列，文章本身不受影响
+func (s *SeriesService) RemovePost(id, postID string) (*models.Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseSeriesID(id)
+	if err != nil {
+		return nil, err
+	}
+	postObjID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	return s.store.RemoveSeriesPost(ctx, objID, postObjID, time.Now())
+}
+
+// DeleteSeries 删除系列，其中的文章不受影响
+func (s *SeriesService) DeleteSeries(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := parseSeriesID(id)
+	if err != nil {
+		return err
+	}
+	return s.store.DeleteSeries(ctx, objID)
+}
+
+// parsePostIDs 解析文章ID列表，并确认文章存在、不在回收站中且没有重复
+func (s *SeriesService) parsePostIDs(ctx context.Context, ids []string) ([]primitive.ObjectID, error) {
+	postIDs := make([]primitive.ObjectID, 0, len(ids))
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil || seen[objID] {
+			return nil, ErrInvalidSeriesPosts
+		}
+		seen[objID] = true
+		postIDs = append(postIDs, objID)
+	}
+
+	posts, err := s.blogService.blogsInOrder(ctx, postIDs, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) != len(postIDs) {
+		return nil, ErrInvalidSeriesPosts
+	}
+	return postIDs, nil
+}
+
+// uniqueSlug 基于 base 生成未被其他系列占用的 slug，冲突时追加 -2、-3…
+func (s *SeriesService) uniqueSlug(ctx context.Context, base string, self primitive.ObjectID) (string, error) {
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		existing, err := s.store.GetSeriesBySlug(ctx, candidate)
+		if errors.Is(err, ErrSeriesNotFound) || (err == nil && existing.ID == self) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// checkSlug 校验手动指定的 slug 格式，并确认未被其他系列占用
+func (s *SeriesService) checkSlug(ctx context.Context, slug string, self primitive.ObjectID) error {
+	if err := ValidateSlug(slug); err != nil {
+		return err
+	}
+	existing, err := s.store.GetSeriesBySlug(ctx, slug)
+	if errors.Is(err, ErrSeriesNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != self {
+		return ErrSlugTaken
+	}
+	return nil
+}
+
+// blogsInOrder 按给定ID的顺序获取文章，不存在或在回收站中的文章被跳过
+// visibleOnly 为 true 时只返回前端可见的文章
+func (s *BlogService) blogsInOrder(ctx context.Context, ids []primitive.ObjectID, visibleOnly bool) ([]*models.Blog, error) {
+	if len(ids) == 0 {
+		return []*models.Blog{}, nil
+	}
+	query := BlogQuery{IDs: ids}
+	if visibleOnly {
+		visible := true
+		query.Show = &visible
+		query.VisibleAt = time.Now()
+	}
+	blogs, err := s.store.ListBlogs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*models.Blog, len(blogs))
+	for _, blog := range blogs {
+		byID[blog.ID] = blog
+	}
+	ordered := make([]*models.Blog, 0, len(blogs))
+	for _, id := range ids {
+		if blog, ok := byID[id]; ok {
+			ordered = append(ordered, blog)
+		}
+	}
+	return ordered, nil
+}
+
+// parseSeriesID 解析系列ID，格式错误的ID视为系列不存在
+func parseSeriesID(id string) (primitive.ObjectID, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrSeriesNotFound
+	}
+	return objID, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"blog/models"
+)
+
+func TestPurgeRemovesPostsFromSeries(t *testing.T) {
+	tests := []struct {
+		name  string
+		purge func(s *BlogService, id string) error
+	}{
+		{"PurgeBlog", func(s *BlogService, id string) error { return s.PurgeBlog(id) }},
+		{"PurgeExpiredTrash", func(s *BlogService, _ string) error {
+			_, err := s.PurgeExpiredTrash(0)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seriesStore := NewMemorySeriesStore()
+			blogService := NewBlogService(NewMemoryBlogStore(), NewMemoryRevisionStore(), NewMemoryCategoryStore(),
+				NewMemoryCommentStore(), NewMemoryReactionStore(), seriesStore, 50)
+			seriesService := NewSeriesService(seriesStore, blogService)
+
+			var ids []string
+			for _, title := range []string{"第一篇", "第二篇", "第三篇"} {
+				blog, err := blogService.CreateBlog(CreateBlogInput{Title: title, Content: "正文", Author: "alice", Show: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, blog.ID.Hex())
+			}
+			title, other := "教程", "另一个系列"
+			series, err := seriesService.CreateSeries(SeriesInput{Title: &title, PostIDs: ids})
+			if err != nil {
+				t.Fatal(err)
+			}
+			otherSeries, err := seriesService.CreateSeries(SeriesInput{Title: &other, PostIDs: []string{ids[2], ids[1]}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := blogService.DeleteBlog(ids[1], nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.purge(blogService, ids[1]); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, c := range []struct {
+				series *models.Series
+				want   []string
+			}{
+				{series, []string{ids[0], ids[2]}},
+				{otherSeries, []string{ids[2]}},
+			} {
+				got, _, err := seriesService.GetSeries(c.series.ID.Hex())
+				if err != nil {
+					t.Fatal(err)
+				}
+				var postIDs []string
+				for _, id := range got.PostIDs {
+					postIDs = append(postIDs, id.Hex())
+				}
+				if !slices.Equal(postIDs, c.want) {
+					t.Errorf("系列 %q 的文章 = %q, want %q", got.Title, postIDs, c.want)
+				}
+			}
+
+			// 清理后系列可以正常编辑，不会因残留的文章ID校验失败
+			if _, err := seriesService.UpdateSeries(series.ID.Hex(), SeriesInput{PostIDs: []string{ids[2], ids[0]}}); err != nil {
+				t.Errorf("UpdateSeries err = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSeriesNotFound 系列不存在
+var ErrSeriesNotFound = errors.New("系列不存在")
+
+// ErrPostInSeries 文章已在系列中
+var ErrPostInSeries = errors.New("文章已在该系列中")
+
+// SeriesUpdate 系列的部分更新字段，nil 表示不修改
+type SeriesUpdate struct {
+	Title       *string
+	Slug        *string
+	Description *string
+	PostIDs     []primitive.ObjectID // 完整替换文章顺序，nil 表示不修改
+	UpdatedAt   time.Time
+}
+
+// SeriesStore 系列的存储接口
+type SeriesStore interface {
+	// CreateSeries 保存新系列，series.ID 由调用方生成，slug 冲突时返回 ErrSlugTaken
+	CreateSeries(ctx context.Context, series *models.Series) error
+	// GetSeriesByID 根据ID获取系列，不存在时返回 ErrSeriesNotFound
+	GetSeriesByID(ctx context.Context, id primitive.ObjectID) (*models.Series, error)
+	// GetSeriesBySlug 根据 slug 获取系列，不存在时返回 ErrSeriesNotFound
+	GetSeriesBySlug(ctx context.Context, slug string) (*models.Series, error)
+	// ListSeries 按创建时间倒序获取全部系列
+	ListSeries(ctx context.Context) ([]*models.Series, error)
+	// ListSeriesByPost 获取包含指定文章的全部系列
+	ListSeriesByPost(ctx context.Context, postID primitive.ObjectID) ([]*models.Series, error)
+	// UpdateSeries 更新系列并返回更新后的文档，不存在时返回 ErrSeriesNotFound，slug 冲突时返回 ErrSlugTaken
+	UpdateSeries(ctx context.Context, id primitive.ObjectID, update SeriesUpdate) (*models.Series, error)
+	// AddSeriesPost 将文章插入系列的 position 位置（从 0 开始，< 0 或越界时追加到末尾）
+	// 文章已在系列中时返回 ErrPostInSeries
+	AddSeriesPost(ctx context.Context, id, postID primitive.ObjectID, position int, at time.Time) (*models.Series, error)
+	// RemoveSeriesPost 从系列中移除文章，文章不在系列中时不做修改
+	RemoveSeriesPost(ctx context.Context, id, postID primitive.ObjectID, at time.Time) (*models.Series, error)
+	// PurgeSeriesPosts 从全部系列中移除 postIDs 中的文章，文章被永久删除时调用，返回被修改的系列数
+	PurgeSeriesPosts(ctx context.Context, postIDs []primitive.ObjectID, at time.Time) (int64, error)
+	// DeleteSeries 删除系列（不影响其中的文章），不存在时返回 ErrSeriesNotFound
+	DeleteSeries(ctx context.Context, id primitive.ObjectID) error
+}
+
+// MongoSeriesStore 基于 MongoDB 的系列存储
+type MongoSeriesStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoSeriesStore 创建新的MongoSeriesStore实例
+func NewMongoSeriesStore(client *mongo.Client, dbName, collectionName string) *MongoSeriesStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoSeriesStore{
+		collection: collection,
+	}
+}
+
+// EnsureSchema 创建 slug 唯一索引与文章反查索引
+func (s *MongoSeriesStore) EnsureSchema(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "post_ids", Value: 1}}},
+	})
+	return err
+}
+
+// CreateSeries 保存新系列
+func (s *MongoSeriesStore) CreateSeries(ctx context.Context, series *models.Series) error {
+	_, err := s.collection.InsertOne(ctx, series)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSlugTaken
+	}
+	return err
+}
+
+// GetSeriesByID 根据ID获取系列
+func (s *MongoSeriesStore) GetSeriesByID(ctx context.Context, id primitive.ObjectID) (*models.Series, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetSeriesBySlug 根据 slug 获取系列
+func (s *MongoSeriesStore) GetSeriesBySlug(ctx context.Context, slug string) (*models.Series, error) {
+	return s.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (s *MongoSeriesStore) findOne(ctx context.Context, filter bson.M) (*models.Series, error) {
+	var series models.Series
+	err := s.collection.FindOne(ctx, filter).Decode(&series)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, err
+	}
+	return &series, nil
+}
+
+// ListSeries 按创建时间倒序获取全部系列
+func (s *MongoSeriesStore) ListSeries(ctx context.Context) ([]*models.Series, error) {
+	return s.find(ctx, bson.M{})
+}
+
+// ListSeriesByPost 获取包含指定文章的全部系列
+func (s *MongoSeriesStore) ListSeriesByPost(ctx context.Context, postID primitive.ObjectID) ([]*models.Series, error) {
+	return s.find(ctx, bson.M{"post_ids": postID})
+}
+
+func (s *MongoSeriesStore) find(ctx context.Context, filter bson.M) ([]*models.Series, error) {
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []*models.Series{}
+	if err = cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateSeries 更新系列
+func (s *MongoSeriesStore) UpdateSeries(ctx context.Context, id primitive.ObjectID, update SeriesUpdate) (*models.Series, error) {
+	setFields := bson.M{"updated_at": update.UpdatedAt}
+	if update.Title != nil {
+		setFields["title"] = *update.Title
+	}
+	if update.Slug != nil {
+		setFields["slug"] = *update.Slug
+	}
+	if update.Description != nil {
+		setFields["description"] = *update.Description
+	}
+	if update.PostIDs != nil {
+		setFields["post_ids"] = update.PostIDs
+	}
+	return s.findOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": setFields})
+}
+
+// AddSeriesPost 将文章插入系列
+func (s *MongoSeriesStore) AddSeriesPost(ctx context.Context, id, postID primitive.ObjectID, position int, at time.Time) (*models.Series, error) {
+	each := bson.M{"$each": bson.A{postID}}
+	if position >= 0 {
+		each["$position"] = position
+	}
+	series, err := s.findOneAndUpdate(ctx,
+		bson.M{"_id": id, "post_ids": bson.M{"$ne": postID}},
+		bson.M{"$push": bson.M{"post_ids": each}, "$set": bson.M{"updated_at": at}},
+	)
+	if errors.Is(err, ErrSeriesNotFound) {
+		// 区分系列不存在与文章已在系列中
+		if _, err := s.GetSeriesByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrPostInSeries
+	}
+	return series, err
+}
+
+// RemoveSeriesPost 从系列中移除文章
+func (s *MongoSeriesStore) RemoveSeriesPost(ctx context.Context, id, postID primitive.ObjectID, at time.Time) (*models.Series, error) {
+	return s.findOneAndUpdate(ctx, bson.M{"_id": id},
+		bson.M{"$pull": bson.M{"post_ids": postID}, "$set": bson.M{"updated_at": at}},
+	)
+}
+
+// PurgeSeriesPosts 从全部系列中移除文章
+func (s *MongoSeriesStore) PurgeSeriesPosts(ctx context.Context, postIDs []primitive.ObjectID, at time.Time) (int64, error) {
+	if len(postIDs) == 0 {
+		return 0, nil
+	}
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"post_ids": bson.M{"$in": postIDs}},
+		bson.M{"$pull": bson.M{"post_ids": bson.M{"$in": postIDs}}, "$set": bson.M{"updated_at": at}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *MongoSeriesStore) findOneAndUpdate(ctx context.Context, filter, change bson.M) (*models.Series, error) {
+	var series models.Series
+	err := s.collection.FindOneAndUpdate(ctx, filter, change,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&series)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, err
+	}
+	return &series, nil
+}
+
+// DeleteSeries 删除系列
+func (s *MongoSeriesStore) DeleteSeries(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrSeriesNotFound
+	}
+	return nil
+}
+
+// MemorySeriesStore 基于内存的系列存储，用于本地运行与单元测试
+type MemorySeriesStore struct {
+	mu     sync.RWMutex
+	series map[primitive.ObjectID]*models.Series
+}
+
+// NewMemorySeriesStore 创建新的MemorySeriesStore实例
+func NewMemorySeriesStore() *MemorySeriesStore {
+	return &MemorySeriesStore{
+		series: make(map[primitive.ObjectID]*models.Series),
+	}
+}
+
+// CreateSeries 保存新系列
+func (s *MemorySeriesStore) CreateSeries(_ context.Context, series *models.Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.slugTaken(series.Slug, series.ID) {
+		return ErrSlugTaken
+	}
+	s.series[series.ID] = cloneSeries(series)
+	return nil
+}
+
+// GetSeriesByID 根据ID获取系列
+func (s *MemorySeriesStore) GetSeriesByID(_ context.Context, id primitive.ObjectID) (*models.Series, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, ok := s.series[id]
+	if !ok {
+		return nil, ErrSeriesNotFound
+	}
+	return cloneSeries(series), nil
+}
+
+// GetSeriesBySlug 根据 slug 获取系列
+func (s *MemorySeriesStore) GetSeriesBySlug(_ context.Context, slug string) (*models.Series, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, series := range s.series {
+		if series.Slug == slug {
+			return cloneSeries(series), nil
+		}
+	}
+	return nil, ErrSeriesNotFound
+}
+
+// ListSeries 按创建时间倒序获取全部系列
+func (s *MemorySeriesStore) ListSeries(_ context.Context) ([]*models.Series, error) {
+	return s.filter(func(*models.Series) bool { return true }), nil
+}
+
+// ListSeriesByPost 获取包含指定文章的全部系列
+func (s *MemorySeriesStore) ListSeriesByPost(_ context.Context, postID primitive.ObjectID) ([]*models.Series, error) {
+	return s.filter(func(series *models.Series) bool { return slices.Contains(series.PostIDs, postID) }), nil
+}
+
+func (s *MemorySeriesStore) filter(match func(*models.Series) bool) []*models.Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []*models.Series{}
+	for _, series := range s.series {
+		if match(series) {
+			list = append(list, cloneSeries(series))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID.Hex() > list[j].ID.Hex()
+	})
+	return list
+}
+
+// UpdateSeries 更新系列
+func (s *MemorySeriesStore) UpdateSeries(_ context.Context, id primitive.ObjectID, update SeriesUpdate) (*models.Series, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series, ok := s.series[id]
+	if !ok {
+		return nil, ErrSeriesNotFound
+	}
+	if update.Slug != nil && s.slugTaken(*update.Slug, id) {
+		return nil, ErrSlugTaken
+	}
+
+	series.UpdatedAt = update.UpdatedAt
+	if update.Title != nil {
+		series.Title = *update.Title
+	}
+	if update.Slug != nil {
+		series.Slug = *update.Slug
+	}
+	if update.Description != nil {
+		series.Description = *update.Description
+	}
+	if update.PostIDs != nil {
+		series.PostIDs = slices.Clone(update.PostIDs)
+	}
+	return cloneSeries(series), nil
+}
+
+// AddSeriesPost 将文章插入系列
+func (s *MemorySeriesStore) AddSeriesPost(_ context.Context, id, postID primitive.ObjectID, position int, at time.Time) (*models.Series, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series, ok := s.series[id]
+	if !ok {
+		return nil, ErrSeriesNotFound
+	}
+	if slices.Contains(series.PostIDs, postID) {
+		return nil, ErrPostInSeries
+	}
+	if position < 0 || position > len(series.PostIDs) {
+		position = len(series.PostIDs)
+	}
+	series.PostIDs = slices.Insert(series.PostIDs, position, postID)
+	series.UpdatedAt = at
+	return cloneSeries(series), nil
+}
+
+// RemoveSeriesPost 从系列中移除文章
+func (s *MemorySeriesStore) RemoveSeriesPost(_ context.Context, id, postID primitive.ObjectID, at time.Time) (*models.Series, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series, ok := s.series[id]
+	if !ok {
+		return nil, ErrSeriesNotFound
+	}
+	series.PostIDs = slices.DeleteFunc(series.PostIDs, func(p primitive.ObjectID) bool { return p == postID })
+	series.UpdatedAt = at
+	return cloneSeries(series), nil
+}
+
+// PurgeSeriesPosts 从全部系列中移除文章
+func (s *MemorySeriesStore) PurgeSeriesPosts(_ context.Context, postIDs []primitive.ObjectID, at time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, series := range s.series {
+		before := len(series.PostIDs)
+		series.PostIDs = slices.DeleteFunc(series.PostIDs, func(p primitive.ObjectID) bool { return slices.Contains(postIDs, p) })
+		if len(series.PostIDs) != before {
+			series.UpdatedAt = at
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteSeries 删除系列
+func (s *MemorySeriesStore) DeleteSeries(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.series[id]; !ok {
+		return ErrSeriesNotFound
+	}
+	delete(s.series, id)
+	return nil
+}
+
+// slugTaken 判断 slug 是否已被其他系列使用，调用方需持有锁
+func (s *MemorySeriesStore) slugTaken(slug string, self primitive.ObjectID) bool {
+	for id, series := range s.series {
+		if id != self && series.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneSeries 复制系列，避免调用方修改存储内部的数据
+func cloneSeries(series *models.Series) *models.Series {
+	c := *series
+	c.PostIDs = append([]primitive.ObjectID{}, series.PostIDs...)
+	return &c
+}
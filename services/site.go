@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SiteConfig 站点信息，用于生成订阅源、站点地图等面向外部的绝对链接
+// 本服务只提供 API，页面路径由前端决定，各路径模板为空时表示前端没有对应的页面
+type SiteConfig struct {
+	Title            string
+	Description      string
+	URL              string // 站点根地址，如 https://example.com，不含末尾的 /
+	PostPath         string // 文章页面路径模板，{slug} 与 {id} 会被替换，如 /blog/{slug}
+	TagPath          string // 标签页面路径模板，{tag} 会被替换，如 /tags/{tag}
+	ArchiveYearPath  string // 年度归档页面路径模板，{year} 会被替换，如 /archive/{year}
+	ArchiveMonthPath string // 月度归档页面路径模板，{year} 与 {month}（两位数字）会被替换，如 /archive/{year}/{month}
+}
+
+// AbsURL 将站点内路径转换为绝对链接
+func (c SiteConfig) AbsURL(path string) string {
+	return strings.TrimRight(c.URL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// PostURL 返回文章页面的绝对链接
+func (c SiteConfig) PostURL(id primitive.ObjectID, slug string) string {
+	path := strings.NewReplacer(
+		"{slug}", url.PathEscape(slug),
+		"{id}", id.Hex(),
+	).Replace(c.PostPath)
+	return c.AbsURL(path)
+}
+
+// TagURL 返回标签页面的绝对链接
+func (c SiteConfig) TagURL(tag string) string {
+	return c.AbsURL(strings.ReplaceAll(c.TagPath, "{tag}", url.PathEscape(tag)))
+}
+
+// ArchiveYearURL 返回年度归档页面的绝对链接
+func (c SiteConfig) ArchiveYearURL(year int) string {
+	return c.AbsURL(strings.ReplaceAll(c.ArchiveYearPath, "{year}", strconv.Itoa(year)))
+}
+
+// ArchiveMonthURL 返回月度归档页面的绝对链接
+func (c SiteConfig) ArchiveMonthURL(year, month int) string {
+	path := strings.NewReplacer(
+		"{year}", strconv.Itoa(year),
+		"{month}", fmt.Sprintf("%02d", month),
+	).Replace(c.ArchiveMonthPath)
+	return c.AbsURL(path)
+}
+
+// Host 返回站点的主机名，URL 无法解析时返回空字符串
+func (c SiteConfig) Host() string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
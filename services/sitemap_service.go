@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SitemapMaxURLs 单个站点地图文件最多包含的链接数（sitemaps.org 协议的上限）
+const SitemapMaxURLs = 50000
+
+// SitemapURL 站点地图中的一个页面
+type SitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// RobotsConfig robots.txt 的内容配置
+type RobotsConfig struct {
+	Disallow []string // 禁止抓取的路径前缀，为空表示允许抓取全部页面
+	Content  string   // 非空时原样输出，忽略 Disallow
+}
+
+// SitemapService 根据前端可见的文章生成站点地图与 robots.txt
+type SitemapService struct {
+	blogService *BlogService
+	site        SiteConfig
+	location    *time.Location
+	robots      RobotsConfig
+}
+
+// NewSitemapService 创建新的SitemapService实例，location 为划分归档年月所用的时区，应与归档接口一致
+func NewSitemapService(blogService *BlogService, site SiteConfig, location *time.Location, robots RobotsConfig) *SitemapService {
+	return &SitemapService{
+		blogService: blogService,
+		site:        site,
+		location:    location,
+		robots:      robots,
+	}
+}
+
+// Site 返回站点信息
+func (s *SitemapService) Site() SiteConfig {
+	return s.site
+}
+
+// VisibleSitemap 生成首页、全部前端可见文章、标签页与归档页的链接，
+// 按 SitemapMaxURLs 切分为多个文件，超过一个文件时由调用方输出站点地图索引
+func (s *SitemapService) VisibleSitemap() ([][]SitemapURL, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	visible := true
+	posts, err := s.blogService.store.ListSitemapPosts(ctx, BlogQuery{Show: &visible, VisibleAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	var home time.Time
+	postURLs := make([]SitemapURL, 0, len(posts))
+	tags := make(map[string]time.Time)
+	years := make(map[int]time.Time)
+	months := make(map[[2]int]time.Time)
+	for _, post := range posts {
+		// 定时发布的文章上线时 UpdatedAt 不变，以两者中较晚者作为最后修改时间
+		modified := latest(post.UpdatedAt, post.PublishedAt)
+		home = latest(home, modified)
+		postURLs = append(postURLs, SitemapURL{Loc: s.site.PostURL(post.ID, post.Slug), LastMod: modified})
+		for _, key := range tagKeys(post.Tags) {
+			tags[key] = latest(tags[key], modified)
+		}
+		t := post.PublishedAt.In(s.location)
+		years[t.Year()] = latest(years[t.Year()], modified)
+		month := [2]int{t.Year(), int(t.Month())}
+		months[month] = latest(months[month], modified)
+	}
+
+	urls := []SitemapURL{{Loc: s.site.AbsURL("/"), LastMod: home}}
+	urls = append(urls, postURLs...)
+	if s.site.TagPath != "" {
+		for _, key := range sortedKeys(tags, func(a, b string) bool { return a < b }) {
+			urls = append(urls, SitemapURL{Loc: s.site.TagURL(key), LastMod: tags[key]})
+		}
+	}
+	if s.site.ArchiveYearPath != "" {
+		for _, year := range sortedKeys(years, func(a, b int) bool { return a > b }) {
+			urls = append(urls, SitemapURL{Loc: s.site.ArchiveYearURL(year), LastMod: years[year]})
+		}
+	}
+	if s.site.ArchiveMonthPath != "" {
+		for _, month := range sortedKeys(months, func(a, b [2]int) bool { return a[0] > b[0] || a[0] == b[0] && a[1] > b[1] }) {
+			urls = append(urls, SitemapURL{Loc: s.site.ArchiveMonthURL(month[0], month[1]), LastMod: months[month]})
+		}
+	}
+
+	chunks := make([][]SitemapURL, 0, (len(urls)+SitemapMaxURLs-1)/SitemapMaxURLs)
+	for start := 0; start < len(urls); start += SitemapMaxURLs {
+		chunks = append(chunks, urls[start:min(start+SitemapMaxURLs, len(urls))])
+	}
+	return chunks, nil
+}
+
+// RobotsTxt 生成 robots.txt，并指向站点地图
+func (s *SitemapService) RobotsTxt() string {
+	if s.robots.Content != "" {
+		return s.robots.Content
+	}
+
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(s.robots.Disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range s.robots.Disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + s.site.AbsURL("/sitemap.xml") + "\n")
+	return b.String()
+}
+
+// sortedKeys 按 less 排序返回 map 的键
+func sortedKeys[K comparable, V any](m map[K]V, less func(a, b K) bool) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blog/models"
+)
+
+func newTestSitemapService(blogService *BlogService) *SitemapService {
+	site := SiteConfig{
+		URL:              "https://example.com",
+		PostPath:         "/blog/{slug}",
+		TagPath:          "/tags/{tag}",
+		ArchiveYearPath:  "/archive/{year}",
+		ArchiveMonthPath: "/archive/{year}/{month}",
+	}
+	return NewSitemapService(blogService, site, time.UTC, RobotsConfig{})
+}
+
+func TestVisibleSitemap(t *testing.T) {
+	blogService := newTestBlogService()
+	mustCreateBlogs(t, blogService.store,
+		newTestBlog(1, func(b *models.Blog) { b.Tags = []string{"Go", "Web"} }),
+		newTestBlog(24*40, func(b *models.Blog) { b.Tags = []string{"go"} }),
+		newTestBlog(24*400, nil),
+		newTestBlog(2, func(b *models.Blog) { b.Tags = []string{"Draft"}; b.Show = false }),
+	)
+
+	chunks, err := newTestSitemapService(blogService).VisibleSitemap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("文件数 = %d, want 1", len(chunks))
+	}
+	var got []string
+	for _, u := range chunks[0] {
+		got = append(got, strings.TrimPrefix(u.Loc, "https://example.com")+" "+u.LastMod.Format("2006-01-02"))
+	}
+	// 首页、文章按发布时间倒序，标签按名称排序，归档按年月倒序；lastmod 取其中文章的最晚时间，草稿不计入
+	want := []string{
+		"/ 2025-02-04",
+		"/blog/post-9600 2025-02-04",
+		"/blog/post-960 2024-02-10",
+		"/blog/post-1 2024-01-01",
+		"/tags/go 2024-02-10",
+		"/tags/web 2024-01-01",
+		"/archive/2025 2025-02-04",
+		"/archive/2024 2024-02-10",
+		"/archive/2025/02 2025-02-04",
+		"/archive/2024/02 2024-02-10",
+		"/archive/2024/01 2024-01-01",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("VisibleSitemap =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestVisibleSitemapSplitsFiles(t *testing.T) {
+	blogService := newTestBlogService()
+	store := blogService.store.(*MemoryBlogStore)
+	// 直接写入内存存储，跳过逐篇的 slug 检查；每分钟一篇，全部已发布
+	publishedAt := func(n int) time.Time { return testEpoch.Add(time.Duration(n) * time.Minute) }
+	for n := range SitemapMaxURLs + 1 {
+		blog := newTestBlog(n, func(b *models.Blog) { b.PublishedAt, b.UpdatedAt = publishedAt(n), publishedAt(n) })
+		store.blogs[blog.ID] = blog
+	}
+
+	chunks, err := newTestSitemapService(blogService).VisibleSitemap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("文件数 = %d, want 2", len(chunks))
+	}
+	total := 0
+	for _, chunk := range chunks {
+		total += len(chunk)
+	}
+	if len(chunks[0]) != SitemapMaxURLs {
+		t.Errorf("第一个文件的链接数 = %d, want %d", len(chunks[0]), SitemapMaxURLs)
+	}
+	// 首页 + 文章 + 各年与各月的归档页
+	years, months := map[int]bool{}, map[string]bool{}
+	for n := range SitemapMaxURLs + 1 {
+		at := publishedAt(n)
+		years[at.Year()] = true
+		months[at.Format("2006-01")] = true
+	}
+	if want := 1 + SitemapMaxURLs + 1 + len(years) + len(months); total != want {
+		t.Errorf("链接总数 = %d, want %d", total, want)
+	}
+	if last := chunks[1][len(chunks[1])-1]; last.Loc != fmt.Sprintf("https://example.com/archive/%d/01", testEpoch.Year()) {
+		t.Errorf("最后一个链接 = %s", last.Loc)
+	}
+}
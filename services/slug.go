@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// maxSlugLength 自动生成的 slug 最大长度（字节）
+const maxSlugLength = 80
+
+// ErrSlugTaken slug 已被其他文章使用（包括其历史 slug）
+var ErrSlugTaken = errors.New("slug 已被使用")
+
+// ErrInvalidSlug slug 格式不合法
+var ErrInvalidSlug = errors.New("slug 只能包含小写字母、数字和连字符，且不能以连字符开头或结尾")
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// pinyinArgs 无声调、多音字取常用读音
+var pinyinArgs = pinyin.NewArgs()
+
+// Slugify 根据标题生成 slug：汉字转写为拼音，字母转为小写，其余字符作为分隔符
+// 例如 "Go 并发编程 入门" 生成 "go-bing-fa-bian-cheng-ru-men"
+func Slugify(title string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range title {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 {
+				words = append(words, py[0])
+			}
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word = append(word, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	slug := strings.Join(words, "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+		// 避免截断在单词中间
+		if i := strings.LastIndex(slug, "-"); i > maxSlugLength/2 {
+			slug = slug[:i]
+		}
+	}
+	if slug == "" {
+		return "post"
+	}
+	return slug
+}
+
+// ValidateSlug 校验手动设置的 slug 格式
+func ValidateSlug(slug string) error {
+	if len(slug) > maxSlugLength*2 || !slugPattern.MatchString(slug) {
+		return ErrInvalidSlug
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello World", "hello-world"},
+		{"Go 并发编程 入门", "go-bing-fa-bian-cheng-ru-men"},
+		{"  C++ / Rust: 对比!  ", "c-rust-dui-bi"},
+		{"Go1.22发布", "go1-22-fa-bu"},
+		{"Café déjà vu", "caf-d-j-vu"},
+		{"!!!", "post"},
+		{"", "post"},
+		{"🎉", "post"},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.title); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSlugifyTruncatesAtWordBoundary(t *testing.T) {
+	title := strings.Repeat("word ", 30)
+	slug := Slugify(title)
+	if len(slug) > maxSlugLength {
+		t.Errorf("len(Slugify) = %d, want <= %d", len(slug), maxSlugLength)
+	}
+	if strings.HasSuffix(slug, "-") || !strings.HasSuffix(slug, "-word") {
+		t.Errorf("Slugify 截断在单词中间: %q", slug)
+	}
+	if err := ValidateSlug(slug); err != nil {
+		t.Errorf("ValidateSlug(Slugify(...)) = %v", err)
+	}
+}
+
+func TestValidateSlug(t *testing.T) {
+	tests := []struct {
+		slug  string
+		valid bool
+	}{
+		{"hello", true},
+		{"hello-world-2", true},
+		{"2024", true},
+		{"", false},
+		{"Hello", false},
+		{"-hello", false},
+		{"hello-", false},
+		{"hello--world", false},
+		{"hello_world", false},
+		{"你好", false},
+		{strings.Repeat("a", maxSlugLength*2), true},
+		{strings.Repeat("a", maxSlugLength*2+1), false},
+	}
+	for _, tt := range tests {
+		err := ValidateSlug(tt.slug)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateSlug(%q) = %v, want valid %v", tt.slug, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidSlug) {
+			t.Errorf("ValidateSlug(%q) err = %v, want ErrInvalidSlug", tt.slug, err)
+		}
+	}
+}
+
+func TestBlogServiceSlugs(t *testing.T) {
+	s := newTestBlogService()
+	create := func(title, slug string) (string, error) {
+		blog, err := s.CreateBlog(CreateBlogInput{Title: title, Slug: slug, Author: "alice", Show: true})
+		if err != nil {
+			return "", err
+		}
+		return blog.Slug, nil
+	}
+
+	first, _ := create("你好 World", "")
+	second, _ := create("你好 World", "")
+	if first != "ni-hao-world" || second != "ni-hao-world-2" {
+		t.Errorf("重复标题的 slug = %q, %q, want ni-hao-world, ni-hao-world-2", first, second)
+	}
+	if _, err := create("other", "ni-hao-world"); !errors.Is(err, ErrSlugTaken) {
+		t.Errorf("手动指定已占用的 slug err = %v, want ErrSlugTaken", err)
+	}
+	if _, err := create("other", "Bad Slug"); !errors.Is(err, ErrInvalidSlug) {
+		t.Errorf("手动指定不合法的 slug err = %v, want ErrInvalidSlug", err)
+	}
+
+	// 修改 slug 后历史 slug 仍指向原文章，且不能被其他文章占用
+	blog, _, err := s.GetVisibleBlogBySlug(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := "renamed"
+	if _, err := s.UpdateBlog(blog.ID.Hex(), UpdateBlogInput{Slug: &renamed, Editor: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	got, moved, err := s.GetVisibleBlogBySlug(first)
+	if err != nil || got.ID != blog.ID || !moved || got.Slug != renamed {
+		t.Errorf("GetVisibleBlogBySlug(历史 slug) = %v, moved %v, err %v", got, moved, err)
+	}
+	if _, err := create("other", first); !errors.Is(err, ErrSlugTaken) {
+		t.Errorf("占用历史 slug err = %v, want ErrSlugTaken", err)
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"strings"
+)
+
+// NormalizeTags 规范化标签：去除首尾空白、丢弃空标签，并按大小写不敏感去重（保留首次出现的写法）
+// 传入 nil 时返回 nil，以保留“不修改标签”的语义
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := tagKey(tag)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// tagKey 返回标签用于匹配的键，"Go" 与 "go " 得到相同的键
+func tagKey(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// tagKeys 计算一组标签的匹配键，与标签一一对应
+func tagKeys(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagKey(tag)
+	}
+	return keys
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"nil 表示不修改", nil, nil},
+		{"空切片表示清空", []string{}, []string{}},
+		{"去除空白与空标签", []string{" Go ", "", "  ", "Web"}, []string{"Go", "Web"}},
+		{"大小写不敏感去重，保留首次出现的写法", []string{"Go", "go", "GO ", "Rust"}, []string{"Go", "Rust"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeTags(tt.tags)
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeTags(%q) = %#v, want %#v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibleTags(t *testing.T) {
+	blogService := newTestBlogService()
+	create := func(title string, show bool, tags ...string) string {
+		t.Helper()
+		blog, err := blogService.CreateBlog(CreateBlogInput{Title: title, Content: "正文", Author: "alice", Show: show, Tags: tags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blog.ID.Hex()
+	}
+	create("Go 入门", true, "Go", " go ", "Web")
+	rust := create("Rust 入门", true, "rust", "GO")
+	create("草稿", false, "Go", "Draft")
+
+	tagCounts := func() []string {
+		t.Helper()
+		tags, err := blogService.ListVisibleTags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, tc := range tags {
+			got = append(got, fmt.Sprintf("%s=%d", tagKey(tc.Tag), tc.Count))
+		}
+		return got
+	}
+	// 草稿的标签不计入，同一篇文章中大小写不同的重复标签只计一次
+	if got, want := tagCounts(), []string{"go=2", "rust=1", "web=1"}; !slices.Equal(got, want) {
+		t.Errorf("ListVisibleTags = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		matchAll bool
+		want     []string
+	}{
+		{"大小写不敏感", []string{"GO"}, false, []string{"Rust 入门", "Go 入门"}},
+		{"包含任一标签", []string{"web", "rust"}, false, []string{"Rust 入门", "Go 入门"}},
+		{"包含全部标签", []string{"go", "Web"}, true, []string{"Go 入门"}},
+		{"不包含草稿", []string{"draft"}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := blogService.ListVisibleBlogs(BlogQuery{Tags: tt.tags, TagMatchAll: tt.matchAll}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, blog := range page.Blogs {
+				got = append(got, blog.Title)
+			}
+			if !slices.Equal(got, tt.want) || *page.Total != int64(len(tt.want)) {
+				t.Errorf("标签 %q = %q（共 %d 篇）, want %q", tt.tags, got, *page.Total, tt.want)
+			}
+		})
+	}
+
+	// 修改标签后计数随之更新
+	if _, err := blogService.UpdateBlog(rust, UpdateBlogInput{Tags: []string{"Rust", "rust", " "}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tagCounts(), []string{"go=1", "rust=1", "web=1"}; !slices.Equal(got, want) {
+		t.Errorf("修改后 ListVisibleTags = %v, want %v", got, want)
+	}
+}
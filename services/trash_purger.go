@@ -0,0 +1,62 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// TrashPurger 定期永久删除在回收站中超过保留期的文章
+type TrashPurger struct {
+	blogService *BlogService
+	retention   time.Duration
+	interval    time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTrashPurger 创建新的TrashPurger实例，retention 为回收站保留期，interval 为清理间隔
+func NewTrashPurger(blogService *BlogService, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		blogService: blogService,
+		retention:   retention,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start 启动后台清理，启动时立即执行一次
+func (p *TrashPurger) Start() {
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.purge()
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台清理
+func (p *TrashPurger) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+func (p *TrashPurger) purge() {
+	n, err := p.blogService.PurgeExpiredTrash(p.retention)
+	if err != nil {
+		log.Println("清理回收站失败:", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("已永久删除回收站中的 %d 篇文章", n)
+	}
+}
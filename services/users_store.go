@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"blog/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrUserNotFound 用户不存在
+var ErrUserNotFound = errors.New("用户不存在")
+
+// UserStore 用户的存储接口，AuthService 通过它访问数据
+type UserStore interface {
+	// CreateUser 保存新用户并回填 user.ID
+	CreateUser(ctx context.Context, user *models.User) error
+	// GetUserByID 根据ID获取用户，不存在时返回 ErrUserNotFound
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	// GetUserByUsername 根据用户名获取用户，不存在时返回 ErrUserNotFound
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// GetUserByEmail 根据邮箱获取用户，不存在时返回 ErrUserNotFound
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// ListUsers 按注册时间正序获取全部用户
+	ListUsers(ctx context.Context) ([]*models.User, error)
+}
+
+// MongoUserStore 基于 MongoDB 的用户存储
+type MongoUserStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoUserStore 创建新的MongoUserStore实例
+func NewMongoUserStore(client *mongo.Client, dbName, collectionName string) *MongoUserStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &MongoUserStore{
+		collection: collection,
+	}
+}
+
+// CreateUser 保存新用户
+func (s *MongoUserStore) CreateUser(ctx context.Context, user *models.User) error {
+	result, err := s.collection.InsertOne(ctx, user)
+	if err != nil {
+		return err
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetUserByID 根据ID获取用户
+func (s *MongoUserStore) GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetUserByUsername 根据用户名获取用户
+func (s *MongoUserStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.findOne(ctx, bson.M{"username": username})
+}
+
+// GetUserByEmail 根据邮箱获取用户
+func (s *MongoUserStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.findOne(ctx, bson.M{"email": email})
+}
+
+// ListUsers 按注册时间正序获取全部用户
+func (s *MongoUserStore) ListUsers(ctx context.Context) ([]*models.User, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []*models.User{}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *MongoUserStore) findOne(ctx context.Context, filter bson.M) (*models.User, error) {
+	var user models.User
+	err := s.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// MemoryUserStore 基于内存的用户存储，用于本地运行与单元测试
+type MemoryUserStore struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]*models.User
+}
+
+// NewMemoryUserStore 创建新的MemoryUserStore实例
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		users: make(map[primitive.ObjectID]*models.User),
+	}
+}
+
+// CreateUser 保存新用户
+func (s *MemoryUserStore) CreateUser(_ context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	c := *user
+	s.users[user.ID] = &c
+	return nil
+}
+
+// GetUserByID 根据ID获取用户
+func (s *MemoryUserStore) GetUserByID(_ context.Context, id primitive.ObjectID) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	c := *user
+	return &c, nil
+}
+
+// GetUserByUsername 根据用户名获取用户
+func (s *MemoryUserStore) GetUserByUsername(_ context.Context, username string) (*models.User, error) {
+	return s.find(func(u *models.User) bool { return u.Username == username })
+}
+
+// GetUserByEmail 根据邮箱获取用户
+func (s *MemoryUserStore) GetUserByEmail(_ context.Context, email string) (*models.User, error) {
+	return s.find(func(u *models.User) bool { return u.Email == email })
+}
+
+// ListUsers 按注册时间正序获取全部用户
+func (s *MemoryUserStore) ListUsers(_ context.Context) ([]*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		c := *user
+		users = append(users, &c)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID.Hex() < users[j].ID.Hex()
+	})
+	return users, nil
+}
+
+func (s *MemoryUserStore) find(match func(*models.User) bool) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if match(user) {
+			c := *user
+			return &c, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// botUserAgentKeywords 常见爬虫与脚本客户端的 User-Agent 关键字（小写）
+var botUserAgentKeywords = []string{
+	"bot", "spider", "crawl", "slurp", "bingpreview", "facebookexternalhit",
+	"headless", "preview", "curl", "wget", "python-requests", "go-http-client",
+	"httpclient", "okhttp", "java/", "libwww", "scrapy", "monitor", "uptime",
+}
+
+// IsBotUserAgent 判断 User-Agent 是否来自爬虫或脚本，空 User-Agent 也视为机器访问
+func IsBotUserAgent(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, keyword := range botUserAgentKeywords {
+		if strings.Contains(ua, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// ViewCounter 在进程内缓冲文章浏览次数，定期以原子自增批量写入存储，避免每次阅读都写库
+// 同一客户端在去重窗口内重复浏览同一篇文章只计一次，爬虫访问不计数
+type ViewCounter struct {
+	store    BlogStore
+	window   time.Duration
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[primitive.ObjectID]int64
+	seen    map[string]time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewViewCounter 创建新的ViewCounter实例，window 为去重窗口，interval 为写入间隔
+func NewViewCounter(store BlogStore, window, interval time.Duration) *ViewCounter {
+	return &ViewCounter{
+		store:    store,
+		window:   window,
+		interval: interval,
+		pending:  make(map[primitive.ObjectID]int64),
+		seen:     make(map[string]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start 启动后台定时写入
+func (c *ViewCounter) Start() {
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.flushWithTimeout()
+			case <-c.stop:
+				c.flushWithTimeout()
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台写入，并在返回前写入剩余的浏览次数
+func (c *ViewCounter) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+// Record 记录一次浏览，返回是否计数（去重窗口内的重复浏览与爬虫访问不计数）
+// clientKey 用于识别客户端，通常由 IP 与 User-Agent 组成
+func (c *ViewCounter) Record(id primitive.ObjectID, clientKey, userAgent string) bool {
+	if IsBotUserAgent(userAgent) {
+		return false
+	}
+
+	now := time.Now()
+	key := id.Hex() + "|" + clientKey
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.seen[key]; ok && now.Sub(last) < c.window {
+		return false
+	}
+	c.seen[key] = now
+	c.pending[id]++
+	return true
+}
+
+// Pending 返回尚未写入存储的浏览次数，用于在响应中展示最新的浏览数
+func (c *ViewCounter) Pending(id primitive.ObjectID) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.pending[id]
+}
+
+// Flush 将缓冲的浏览次数写入存储，未写入的部分保留在缓冲区等待下次重试
+func (c *ViewCounter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[primitive.ObjectID]int64)
+
+	// 顺带清理已过期的去重记录
+	now := time.Now()
+	for key, last := range c.seen {
+		if now.Sub(last) >= c.window {
+			delete(c.seen, key)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := c.store.IncrementViews(ctx, batch); err != nil {
+		// 部分写入成功时只重试失败的文章，已生效的增量不能再次累加
+		failed := batch
+		var partial *IncrementViewsError
+		if errors.As(err, &partial) {
+			failed = partial.Failed
+		}
+		c.mu.Lock()
+		for id, n := range failed {
+			c.pending[id] += n
+		}
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (c *ViewCounter) flushWithTimeout() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := c.Flush(ctx); err != nil {
+		log.Println("写入浏览次数失败:", err)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// failingViewStore 模拟部分或全部写入失败的存储
+type failingViewStore struct {
+	*MemoryBlogStore
+	fail    map[primitive.ObjectID]bool // 写入失败的文章
+	partial bool                        // 为 true 时其余文章正常写入，否则全部不写入
+}
+
+func (s *failingViewStore) IncrementViews(ctx context.Context, increments map[primitive.ObjectID]int64) error {
+	failed := make(map[primitive.ObjectID]int64)
+	applied := make(map[primitive.ObjectID]int64)
+	for id, n := range increments {
+		if s.fail[id] {
+			failed[id] = n
+		} else {
+			applied[id] = n
+		}
+	}
+	if len(failed) == 0 {
+		return s.MemoryBlogStore.IncrementViews(ctx, increments)
+	}
+	err := errors.New("写入失败")
+	if !s.partial {
+		return err
+	}
+	s.MemoryBlogStore.IncrementViews(ctx, applied)
+	return &IncrementViewsError{Failed: failed, Err: err}
+}
+
+func TestViewCounterRecord(t *testing.T) {
+	c := NewViewCounter(NewMemoryBlogStore(), time.Hour, time.Hour)
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+
+	tests := []struct {
+		name      string
+		id        primitive.ObjectID
+		client    string
+		userAgent string
+		want      bool
+	}{
+		{"首次浏览", a, "1.1.1.1|Mozilla", "Mozilla/5.0", true},
+		{"窗口内重复浏览", a, "1.1.1.1|Mozilla", "Mozilla/5.0", false},
+		{"其他客户端", a, "2.2.2.2|Mozilla", "Mozilla/5.0", true},
+		{"同一客户端浏览其他文章", b, "1.1.1.1|Mozilla", "Mozilla/5.0", true},
+		{"爬虫", b, "3.3.3.3|Googlebot", "Googlebot/2.1", false},
+		{"空 User-Agent", b, "4.4.4.4|", "", false},
+	}
+	for _, tt := range tests {
+		if got := c.Record(tt.id, tt.client, tt.userAgent); got != tt.want {
+			t.Errorf("%s: Record = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := c.Pending(a); got != 2 {
+		t.Errorf("Pending(a) = %d, want 2", got)
+	}
+}
+
+func TestViewCounterFlushRequeuesOnlyFailedIncrements(t *testing.T) {
+	tests := []struct {
+		name        string
+		partial     bool
+		wantViews   int64 // 第一次写入后 ok 文章的浏览次数
+		wantPending int64 // 第一次写入后 ok 文章留在缓冲区的浏览次数
+	}{
+		{"部分失败只重试失败的文章", true, 1, 0},
+		{"全部失败全部重试", false, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			memory := NewMemoryBlogStore()
+			ok, bad := newTestBlog(1, nil), newTestBlog(2, nil)
+			mustCreateBlogs(t, memory, ok, bad)
+			store := &failingViewStore{MemoryBlogStore: memory, fail: map[primitive.ObjectID]bool{bad.ID: true}, partial: tt.partial}
+
+			c := NewViewCounter(store, time.Hour, time.Hour)
+			c.Record(ok.ID, "a", "Mozilla/5.0")
+			c.Record(bad.ID, "a", "Mozilla/5.0")
+			if err := c.Flush(ctx); err == nil {
+				t.Fatal("Flush 应返回写入错误")
+			}
+			if got, _ := memory.GetBlogByID(ctx, ok.ID); got.Views != tt.wantViews {
+				t.Errorf("views = %d, want %d", got.Views, tt.wantViews)
+			}
+			if got := c.Pending(ok.ID); got != tt.wantPending {
+				t.Errorf("Pending(ok) = %d, want %d", got, tt.wantPending)
+			}
+			if got := c.Pending(bad.ID); got != 1 {
+				t.Errorf("Pending(bad) = %d, want 1", got)
+			}
+
+			// 恢复后重试，每篇文章都恰好计一次
+			store.fail = nil
+			if err := c.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []primitive.ObjectID{ok.ID, bad.ID} {
+				if got, _ := memory.GetBlogByID(ctx, id); got.Views != 1 {
+					t.Errorf("重试后 views = %d, want 1", got.Views)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"blog/services"
+)
+
+// stores 服务器与子命令共用的各类数据存储
+type stores struct {
+	blogs      services.BlogStore
+	revisions  services.RevisionStore
+	series     services.SeriesStore
+	categories services.CategoryStore
+	comments   services.CommentStore
+	reactions  services.ReactionStore
+	mediaMeta  services.MediaMetaStore
+	users      services.UserStore
+}
+
+// openStores 按 STORAGE_BACKEND 初始化存储，返回的函数用于断开数据库连接
+func openStores() (*stores, func()) {
+	// 存储后端：mongo（默认）或 memory（无需数据库，数据不持久化）
+	storageBackend := getEnv("STORAGE_BACKEND", "mongo")
+
+	switch storageBackend {
+	case "mongo":
+		// 从环境变量读取配置，若未设置使用默认值
+		mongoURI := getEnv("MONGO_URI", "mongodb://localhost:27017")
+		// MongoDB 数据库名称
+		dbName := getEnv("DB_NAME", "blogs-db-dev")
+		// MongoDB 集合名称
+		collectionName := getEnv("COLLECTION_NAME", "blogs-db")
+
+		client := connectMongo(mongoURI)
+		closeStores := func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := client.Disconnect(ctx); err != nil {
+				log.Fatal("断开 MongoDB 连接失败:", err)
+			}
+		}
+
+		st := &stores{}
+		mongoBlogStore := services.NewMongoBlogStore(client, dbName, collectionName)
+		if err := mongoBlogStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化博客集合失败:", err)
+		}
+		st.blogs = mongoBlogStore
+
+		mongoRevisionStore := services.NewMongoRevisionStore(client, dbName, "blog_revisions")
+		if err := mongoRevisionStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化版本集合失败:", err)
+		}
+		st.revisions = mongoRevisionStore
+
+		mongoSeriesStore := services.NewMongoSeriesStore(client, dbName, "series")
+		if err := mongoSeriesStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化系列集合失败:", err)
+		}
+		st.series = mongoSeriesStore
+
+		mongoCategoryStore := services.NewMongoCategoryStore(client, dbName, "categories")
+		if err := mongoCategoryStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化分类集合失败:", err)
+		}
+		st.categories = mongoCategoryStore
+
+		mongoCommentStore := services.NewMongoCommentStore(client, dbName, "comments")
+		if err := mongoCommentStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化评论集合失败:", err)
+		}
+		st.comments = mongoCommentStore
+
+		mongoReactionStore := services.NewMongoReactionStore(client, dbName, "reactions")
+		if err := mongoReactionStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化表情回应集合失败:", err)
+		}
+		st.reactions = mongoReactionStore
+
+		mongoMediaMetaStore := services.NewMongoMediaMetaStore(client, dbName, "media")
+		if err := mongoMediaMetaStore.EnsureSchema(context.Background()); err != nil {
+			log.Println("初始化媒体文件集合失败:", err)
+		}
+		st.mediaMeta = mongoMediaMetaStore
+		st.users = services.NewMongoUserStore(client, dbName, "users")
+		return st, closeStores
+	case "memory":
+		log.Println("使用内存存储，服务重启后数据将丢失")
+		return &stores{
+			blogs:      services.NewMemoryBlogStore(),
+			revisions:  services.NewMemoryRevisionStore(),
+			series:     services.NewMemorySeriesStore(),
+			categories: services.NewMemoryCategoryStore(),
+			comments:   services.NewMemoryCommentStore(),
+			reactions:  services.NewMemoryReactionStore(),
+			mediaMeta:  services.NewMemoryMediaMetaStore(),
+			users:      services.NewMemoryUserStore(),
+		}, func() {}
+	default:
+		log.Fatalf("未知的存储后端 STORAGE_BACKEND=%q，可选值：mongo、memory", storageBackend)
+		return nil, nil
+	}
+}
+
+// openMediaStore 打开媒体文件存储：本地目录 MEDIA_DIR（默认）或 S3 兼容的对象存储
+func openMediaStore() services.MediaStore {
+	switch mediaBackend := getEnv("MEDIA_BACKEND", "local"); mediaBackend {
+	case "local":
+		return services.NewLocalMediaStore(getEnv("MEDIA_DIR", "media"))
+	case "s3":
+		s3MediaStore, err := services.NewS3MediaStore(services.S3Config{
+			Endpoint:  getEnv("S3_ENDPOINT", "s3.amazonaws.com"),
+			Bucket:    getEnv("S3_BUCKET", "blog-media"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    getBoolEnv("S3_USE_SSL", true),
+		})
+		if err != nil {
+			log.Fatal("初始化 S3 存储失败:", err)
+		}
+		if err := s3MediaStore.EnsureBucket(context.Background()); err != nil {
+			log.Println("初始化 S3 存储桶失败:", err)
+		}
+		return s3MediaStore
+	default:
+		log.Fatalf("未知的媒体存储后端 MEDIA_BACKEND=%q，可选值：local、s3", mediaBackend)
+		return nil
+	}
+}
+
+// newBlogService 基于存储创建 BlogService，每篇文章默认保留最近 50 个版本，REVISION_LIMIT=0 表示不限制
+func newBlogService(st *stores) *services.BlogService {
+	return services.NewBlogService(st.blogs, st.revisions, st.categories, st.comments, st.reactions, st.series, getIntEnv("REVISION_LIMIT", 50))
+}